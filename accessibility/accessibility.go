@@ -0,0 +1,388 @@
+// Package accessibility implements color vision deficiency filters and a high contrast filter,
+// meant to be applied as the final pass between a game's scene and the Window.
+//
+// Each Filter provides a fragment shader for pixelgl.Canvas.SetFragmentShader, so the filter is
+// applied on the GPU to everything drawn onto the Canvas (or Window) with it. Draw the scene onto
+// its own Canvas and draw that Canvas onto the Window with the filter:
+//
+//   filter := accessibility.NewFilter(accessibility.DeuteranopiaCorrection)
+//   win.Canvas().SetUniform("uIntensity", &filter.Intensity)
+//   win.Canvas().SetFragmentShader(filter.FragmentShader())
+//
+//   for !win.Closed() {
+//       scene.Clear(colornames.Black)
+//       drawGame(scene)
+//       win.Clear(colornames.Black)
+//       scene.Draw(win, pixel.IM.Moved(win.Bounds().Center()))
+//       win.Update()
+//   }
+//
+// Filter.Apply does the same on the CPU. It's the reference the shaders are written against, and
+// it's useful for previews and tests.
+package accessibility
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/faiface/pixel"
+)
+
+// Mode is the kind of a Filter.
+type Mode int
+
+const (
+	// Protanopia simulates the lack of red cones (red-green color blindness).
+	Protanopia Mode = iota
+
+	// Deuteranopia simulates the lack of green cones (the most common red-green color
+	// blindness).
+	Deuteranopia
+
+	// Tritanopia simulates the lack of blue cones (blue-yellow color blindness).
+	Tritanopia
+
+	// ProtanopiaCorrection shifts the colors indistinguishable with protanopia towards
+	// distinguishable ones (daltonization).
+	ProtanopiaCorrection
+
+	// DeuteranopiaCorrection shifts the colors indistinguishable with deuteranopia towards
+	// distinguishable ones (daltonization).
+	DeuteranopiaCorrection
+
+	// TritanopiaCorrection shifts the colors indistinguishable with tritanopia towards
+	// distinguishable ones (daltonization).
+	TritanopiaCorrection
+
+	// HighContrast increases the contrast of the colors and outlines the edges in the image with
+	// black.
+	HighContrast
+)
+
+// String returns the name of the Mode, e.g. "Deuteranopia".
+func (m Mode) String() string {
+	switch m {
+	case Protanopia:
+		return "Protanopia"
+	case Deuteranopia:
+		return "Deuteranopia"
+	case Tritanopia:
+		return "Tritanopia"
+	case ProtanopiaCorrection:
+		return "ProtanopiaCorrection"
+	case DeuteranopiaCorrection:
+		return "DeuteranopiaCorrection"
+	case TritanopiaCorrection:
+		return "TritanopiaCorrection"
+	case HighContrast:
+		return "HighContrast"
+	default:
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+}
+
+// simulation matrices of complete dichromacy (severity 1) from Machado, Oliveira and Fernandes, "A
+// Physiologically-based Model for Simulation of Color Vision Deficiency" (2009), applied to linear
+// RGB
+var simulation = map[Mode][3][3]float64{
+	Protanopia: {
+		{0.152286, 1.052583, -0.204868},
+		{0.114503, 0.786281, 0.099216},
+		{-0.003882, -0.048116, 1.051998},
+	},
+	Deuteranopia: {
+		{0.367322, 0.860646, -0.227968},
+		{0.280085, 0.672501, 0.047413},
+		{-0.011820, 0.042940, 0.968881},
+	},
+	Tritanopia: {
+		{1.255528, -0.076749, -0.178779},
+		{-0.078411, 0.930809, 0.147602},
+		{0.004733, 0.691367, 0.303900},
+	},
+}
+
+// correction maps the correction modes to the simulated deficiency
+var correction = map[Mode]Mode{
+	ProtanopiaCorrection:   Protanopia,
+	DeuteranopiaCorrection: Deuteranopia,
+	TritanopiaCorrection:   Tritanopia,
+}
+
+// the lost information is redistributed into the channels that can still be seen (Fidaner,
+// Lin and Ozguven, "Analysis of Color Blindness", 2005)
+var errorShift = [3][3]float64{
+	{0, 0, 0},
+	{0.7, 1, 0},
+	{0.7, 0, 1},
+}
+
+const (
+	// contrast is the factor the contrast of the colors is multiplied by in the HighContrast mode
+	contrast = 1.5
+
+	// edges with the strength of the luminance gradient (Sobel) between edgeLow and edgeHigh fade
+	// into the black outline
+	edgeLow, edgeHigh = 0.2, 0.4
+)
+
+// Filter is an accessibility filter. Create it using NewFilter.
+type Filter struct {
+	// Mode is the kind of the Filter. Changing it requires setting the fragment shader again.
+	Mode Mode
+
+	// Intensity blends between the original colors (0) and the fully filtered colors (1). It's
+	// a float32, so that a pointer to it can be passed to pixelgl.Canvas.SetUniform as the
+	// "uIntensity" uniform.
+	Intensity float32
+}
+
+// NewFilter creates a Filter of the given Mode with the full Intensity.
+func NewFilter(mode Mode) *Filter {
+	if mode < Protanopia || mode > HighContrast {
+		panic(fmt.Errorf("accessibility.NewFilter: invalid mode %v", mode))
+	}
+	return &Filter{Mode: mode, Intensity: 1}
+}
+
+// Color returns the filtered color. The color is premultiplied, like all colors in Pixel.
+//
+// In the HighContrast mode, only the contrast is increased, the outlines need the neighboring
+// pixels, see Apply.
+func (f *Filter) Color(c pixel.RGBA) pixel.RGBA {
+	return f.filter(c, 0)
+}
+
+// Apply returns a filtered copy of the PictureData.
+func (f *Filter) Apply(pd *pixel.PictureData) *pixel.PictureData {
+	out := &pixel.PictureData{
+		Pix:    make([]color.RGBA, len(pd.Pix)),
+		Stride: pd.Stride,
+		Rect:   pd.Rect,
+	}
+	for i, c := range pd.Pix {
+		edge := 0.0
+		if f.Mode == HighContrast {
+			edge = sobel(pd, i%pd.Stride, i/pd.Stride)
+		}
+		out.Pix[i] = toColorRGBA(f.filter(pixel.ToRGBA(c), edge))
+	}
+	return out
+}
+
+// filter filters a premultiplied color, edge is the strength of the edge for the HighContrast mode.
+func (f *Filter) filter(c pixel.RGBA, edge float64) pixel.RGBA {
+	if c.A == 0 {
+		return c
+	}
+	rgb := [3]float64{c.R / c.A, c.G / c.A, c.B / c.A}
+
+	var filtered [3]float64
+	simulated, corrected := correction[f.Mode]
+	switch {
+	case f.Mode == HighContrast:
+		outline := 1 - smoothstep(edgeLow, edgeHigh, edge)
+		for i := range rgb {
+			filtered[i] = pixel.Clamp((rgb[i]-0.5)*contrast+0.5, 0, 1) * outline
+		}
+	case corrected:
+		lin := toLinear(rgb)
+		sim := clamp01(mulMat(simulation[simulated], lin))
+		var diff [3]float64
+		for i := range diff {
+			diff[i] = lin[i] - sim[i]
+		}
+		shift := mulMat(errorShift, diff)
+		for i := range lin {
+			lin[i] += shift[i]
+		}
+		filtered = toSRGB(clamp01(lin))
+	default:
+		filtered = toSRGB(clamp01(mulMat(simulation[f.Mode], toLinear(rgb))))
+	}
+
+	t := float64(f.Intensity)
+	for i := range rgb {
+		rgb[i] += (filtered[i] - rgb[i]) * t
+	}
+	return pixel.RGBA{R: rgb[0] * c.A, G: rgb[1] * c.A, B: rgb[2] * c.A, A: c.A}
+}
+
+// sobel returns the strength of the luminance gradient at a pixel of the PictureData, the pixels
+// beyond the edges are clamped.
+func sobel(pd *pixel.PictureData, x, y int) float64 {
+	h := len(pd.Pix) / pd.Stride
+	lum := func(dx, dy int) float64 {
+		px := int(pixel.Clamp(float64(x+dx), 0, float64(pd.Stride-1)))
+		py := int(pixel.Clamp(float64(y+dy), 0, float64(h-1)))
+		c := pd.Pix[py*pd.Stride+px]
+		return (0.2126*float64(c.R) + 0.7152*float64(c.G) + 0.0722*float64(c.B)) / 255
+	}
+	gx := -lum(-1, -1) - 2*lum(-1, 0) - lum(-1, 1) + lum(1, -1) + 2*lum(1, 0) + lum(1, 1)
+	gy := -lum(-1, -1) - 2*lum(0, -1) - lum(1, -1) + lum(-1, 1) + 2*lum(0, 1) + lum(1, 1)
+	return math.Hypot(gx, gy)
+}
+
+// FragmentShader returns the source of a fragment shader for pixelgl.Canvas.SetFragmentShader,
+// which applies the Filter to everything drawn onto the Canvas. The shader reads the Intensity
+// from the "uIntensity" float uniform, set it with SetUniform before setting the shader.
+//
+// Other than applying the Filter, the shader works the same as the default Canvas shader.
+func (f *Filter) FragmentShader() string {
+	var filter string
+	simulated, corrected := correction[f.Mode]
+	switch {
+	case f.Mode == HighContrast:
+		filter = fmt.Sprintf(highContrastFilter, contrast, edgeLow, edgeHigh)
+	case corrected:
+		filter = fmt.Sprintf(correctionFilter, glslMat(simulation[simulated]), glslMat(errorShift))
+	default:
+		filter = fmt.Sprintf(simulationFilter, glslMat(simulation[f.Mode]))
+	}
+	return strings.Replace(fragmentShader, "FILTER", filter, 1)
+}
+
+// glslMat returns a GLSL mat3 constructor of a row-major matrix. GLSL matrices are column-major.
+func glslMat(m [3][3]float64) string {
+	var elems []string
+	for col := 0; col < 3; col++ {
+		for row := 0; row < 3; row++ {
+			elems = append(elems, fmt.Sprintf("%v", m[row][col]))
+		}
+	}
+	return "mat3(" + strings.Join(elems, ", ") + ")"
+}
+
+var fragmentShader = `
+#version 330 core
+
+in vec4  vColor;
+in vec2  vTexCoords;
+in float vIntensity;
+
+out vec4 fragColor;
+
+uniform vec4 uColorMask;
+uniform vec4 uTexBounds;
+uniform sampler2D uTexture;
+uniform float uIntensity;
+
+vec3 toLinear(vec3 c) {
+	return mix(c / 12.92, pow((c + 0.055) / 1.055, vec3(2.4)), step(0.04045, c));
+}
+
+vec3 toSRGB(vec3 c) {
+	return mix(c * 12.92, 1.055 * pow(c, vec3(1 / 2.4)) - 0.055, step(0.0031308, c));
+}
+
+float luminance(vec2 t) {
+	return dot(texture(uTexture, t).rgb, vec3(0.2126, 0.7152, 0.0722));
+}
+
+float sobel(vec2 t) {
+	vec2 d = 1 / uTexBounds.zw;
+	float gx = -luminance(t + d * vec2(-1, -1)) - 2 * luminance(t + d * vec2(-1, 0)) - luminance(t + d * vec2(-1, 1))
+		+ luminance(t + d * vec2(1, -1)) + 2 * luminance(t + d * vec2(1, 0)) + luminance(t + d * vec2(1, 1));
+	float gy = -luminance(t + d * vec2(-1, -1)) - 2 * luminance(t + d * vec2(0, -1)) - luminance(t + d * vec2(1, -1))
+		+ luminance(t + d * vec2(-1, 1)) + 2 * luminance(t + d * vec2(0, 1)) + luminance(t + d * vec2(1, 1));
+	return length(vec2(gx, gy));
+}
+
+FILTER
+
+void main() {
+	vec4 c;
+	vec2 t = (vTexCoords - uTexBounds.xy) / uTexBounds.zw;
+	if (vIntensity == 0) {
+		c = uColorMask * vColor;
+	} else {
+		c = vec4(0, 0, 0, 0);
+		c += (1 - vIntensity) * vColor;
+		c += vIntensity * vColor * texture(uTexture, t);
+		c *= uColorMask;
+	}
+	if (c.a == 0) {
+		fragColor = c;
+		return;
+	}
+	vec3 rgb = c.rgb / c.a;
+	vec3 filtered = filterColor(rgb, t, vIntensity != 0);
+	fragColor = vec4(mix(rgb, filtered, uIntensity) * c.a, c.a);
+}
+`
+
+var simulationFilter = `
+vec3 filterColor(vec3 c, vec2 t, bool textured) {
+	return toSRGB(clamp(%s * toLinear(c), 0, 1));
+}
+`
+
+var correctionFilter = `
+vec3 filterColor(vec3 c, vec2 t, bool textured) {
+	vec3 lin = toLinear(c);
+	vec3 sim = clamp(%s * lin, 0, 1);
+	lin += %s * (lin - sim);
+	return toSRGB(clamp(lin, 0, 1));
+}
+`
+
+var highContrastFilter = `
+vec3 filterColor(vec3 c, vec2 t, bool textured) {
+	float edge = textured ? sobel(t) : 0.0;
+	vec3 contrasted = clamp((c - 0.5) * %v + 0.5, 0, 1);
+	return contrasted * (1 - smoothstep(%v, %v, edge));
+}
+`
+
+func toLinear(c [3]float64) [3]float64 {
+	for i, x := range c {
+		if x < 0.04045 {
+			c[i] = x / 12.92
+		} else {
+			c[i] = math.Pow((x+0.055)/1.055, 2.4)
+		}
+	}
+	return c
+}
+
+func toSRGB(c [3]float64) [3]float64 {
+	for i, x := range c {
+		if x < 0.0031308 {
+			c[i] = x * 12.92
+		} else {
+			c[i] = 1.055*math.Pow(x, 1/2.4) - 0.055
+		}
+	}
+	return c
+}
+
+func mulMat(m [3][3]float64, v [3]float64) [3]float64 {
+	var out [3]float64
+	for row := range m {
+		out[row] = m[row][0]*v[0] + m[row][1]*v[1] + m[row][2]*v[2]
+	}
+	return out
+}
+
+func clamp01(c [3]float64) [3]float64 {
+	for i := range c {
+		c[i] = pixel.Clamp(c[i], 0, 1)
+	}
+	return c
+}
+
+func smoothstep(edge0, edge1, x float64) float64 {
+	t := pixel.Clamp((x-edge0)/(edge1-edge0), 0, 1)
+	return t * t * (3 - 2*t)
+}
+
+func toColorRGBA(c pixel.RGBA) color.RGBA {
+	return color.RGBA{
+		R: uint8(math.Floor(c.R*255 + 0.5)),
+		G: uint8(math.Floor(c.G*255 + 0.5)),
+		B: uint8(math.Floor(c.B*255 + 0.5)),
+		A: uint8(math.Floor(c.A*255 + 0.5)),
+	}
+}
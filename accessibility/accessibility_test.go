@@ -0,0 +1,123 @@
+package accessibility_test
+
+import (
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/accessibility"
+)
+
+var allModes = []accessibility.Mode{
+	accessibility.Protanopia,
+	accessibility.Deuteranopia,
+	accessibility.Tritanopia,
+	accessibility.ProtanopiaCorrection,
+	accessibility.DeuteranopiaCorrection,
+	accessibility.TritanopiaCorrection,
+	accessibility.HighContrast,
+}
+
+func near(a, b pixel.RGBA, eps float64) bool {
+	return math.Abs(a.R-b.R) < eps && math.Abs(a.G-b.G) < eps && math.Abs(a.B-b.B) < eps && math.Abs(a.A-b.A) < eps
+}
+
+func dist(a, b pixel.RGBA) float64 {
+	return math.Sqrt((a.R-b.R)*(a.R-b.R) + (a.G-b.G)*(a.G-b.G) + (a.B-b.B)*(a.B-b.B))
+}
+
+func TestFilterReferenceColors(t *testing.T) {
+	red, green := pixel.RGB(1, 0, 0), pixel.RGB(0, 1, 0)
+
+	tests := []struct {
+		mode    accessibility.Mode
+		in, out pixel.RGBA
+	}{
+		// the Machado et al. matrices applied to linear red, then encoded as sRGB
+		{accessibility.Protanopia, red, pixel.RGB(0.4266, 0.3727, 0)},
+		{accessibility.Deuteranopia, red, pixel.RGB(0.6401, 0.5658, 0)},
+		{accessibility.Tritanopia, red, pixel.RGB(1, 0, 0.0584)},
+	}
+	for _, test := range tests {
+		got := accessibility.NewFilter(test.mode).Color(test.in)
+		if !near(got, test.out, 1e-3) {
+			t.Errorf("%v: %v filtered to %v, want %v", test.mode, test.in, got, test.out)
+		}
+	}
+
+	// simulated red-green blindness makes red and green hard to tell apart, the correction
+	// makes them easier to tell apart again
+	for _, modes := range [][2]accessibility.Mode{
+		{accessibility.Protanopia, accessibility.ProtanopiaCorrection},
+		{accessibility.Deuteranopia, accessibility.DeuteranopiaCorrection},
+	} {
+		sim := accessibility.NewFilter(modes[0])
+		corr := accessibility.NewFilter(modes[1])
+		simulated := dist(sim.Color(red), sim.Color(green))
+		corrected := dist(sim.Color(corr.Color(red)), sim.Color(corr.Color(green)))
+		if corrected <= simulated {
+			t.Errorf("%v: distance of red and green %v, not greater than without correction %v", modes[1], corrected, simulated)
+		}
+	}
+}
+
+func TestFilterNeutral(t *testing.T) {
+	gray := pixel.RGB(0.5, 0.5, 0.5).Scaled(0.5) // premultiplied, half transparent
+	for _, mode := range allModes {
+		f := accessibility.NewFilter(mode)
+		if mode != accessibility.HighContrast {
+			if got := f.Color(gray); !near(got, gray, 1e-3) {
+				t.Errorf("%v: gray filtered to %v, want unchanged %v", mode, got, gray)
+			}
+		}
+
+		f.Intensity = 0
+		c := pixel.RGBA{R: 0.1, G: 0.4, B: 0.2, A: 0.8}
+		if got := f.Color(c); !near(got, c, 1e-9) {
+			t.Errorf("%v: zero intensity changed %v to %v", mode, c, got)
+		}
+	}
+}
+
+func TestFilterHighContrastOutline(t *testing.T) {
+	// a white square in the middle of a gray picture
+	pd := pixel.MakePictureData(pixel.R(0, 0, 9, 9))
+	for i := range pd.Pix {
+		x, y := i%pd.Stride, i/pd.Stride
+		pd.Pix[i] = color.RGBA{128, 128, 128, 255}
+		if x >= 3 && x < 6 && y >= 3 && y < 6 {
+			pd.Pix[i] = color.RGBA{255, 255, 255, 255}
+		}
+	}
+
+	out := accessibility.NewFilter(accessibility.HighContrast).Apply(pd)
+	at := func(x, y int) color.RGBA { return out.Pix[y*out.Stride+x] }
+
+	if c := at(0, 0); c.R == 0 || c.A != 255 {
+		t.Errorf("flat area at (0, 0) is %v, want not outlined", c)
+	}
+	if c := at(4, 4); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("flat area at (4, 4) is %v, want white", c)
+	}
+	for _, p := range [][2]int{{3, 4}, {5, 4}, {4, 2}, {4, 6}} {
+		if c := at(p[0], p[1]); c != (color.RGBA{0, 0, 0, 255}) {
+			t.Errorf("edge at %v is %v, want black", p, c)
+		}
+	}
+}
+
+func TestFilterFragmentShader(t *testing.T) {
+	for _, mode := range allModes {
+		src := accessibility.NewFilter(mode).FragmentShader()
+		for _, want := range []string{"#version 330 core", "uniform float uIntensity", "vec3 filterColor("} {
+			if !strings.Contains(src, want) {
+				t.Errorf("%v: shader doesn't contain %q", mode, want)
+			}
+		}
+		if strings.Contains(src, "FILTER") || strings.Contains(src, "%!") {
+			t.Errorf("%v: shader not fully generated", mode)
+		}
+	}
+}
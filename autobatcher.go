@@ -0,0 +1,100 @@
+package pixel
+
+import "image/color"
+
+// AutoBatcher groups Sprites drawn during a frame into Batches by their Pictures, so that Sprites
+// coming from several sprite sheets can be drawn efficiently without managing a Batch per sheet.
+//
+// Draw all the Sprites onto the AutoBatcher, then draw them onto the real Target with Flush:
+//
+//   for _, e := range entities {
+//       ab.Draw(e.sprite, e.matrix, e.mask)
+//   }
+//   ab.Flush(win)
+//
+// By default, the Sprites are drawn in the order they were submitted, so a Batch has to be drawn
+// each time the Picture changes. If the Sprites with different Pictures don't overlap (or the order
+// doesn't matter), set Unordered to true and all the Sprites with the same Picture will be drawn
+// with a single Batch in the order of the first use of each Picture.
+//
+// The number of Batch draws in the last Flush is reported by Flushes. If it's much higher than the
+// number of the Pictures, consider putting the Pictures drawn interleaved into a single atlas.
+type AutoBatcher struct {
+	// Unordered allows reordering the Sprites with different Pictures to minimize the number of
+	// Batch draws.
+	Unordered bool
+
+	pools   map[Picture]*autoBatchPool
+	batches []*Batch
+	last    Picture
+	flushes int
+}
+
+// autoBatchPool holds the Batches of one Picture, so that they can be reused in the next frames.
+type autoBatchPool struct {
+	batches []*Batch
+	used    int
+}
+
+func (p *autoBatchPool) next(pic Picture) *Batch {
+	if p.used == len(p.batches) {
+		p.batches = append(p.batches, NewBatch(&TrianglesData{}, pic))
+	}
+	b := p.batches[p.used]
+	p.used++
+	return b
+}
+
+// NewAutoBatcher creates a new empty AutoBatcher which preserves the order of the Sprites.
+func NewAutoBatcher() *AutoBatcher {
+	return &AutoBatcher{pools: make(map[Picture]*autoBatchPool)}
+}
+
+// Draw adds the Sprite transformed by the Matrix and multiplied by the color mask to the
+// AutoBatcher. The Sprite gets drawn onto a Target in the next Flush.
+//
+// If the mask is nil, a fully opaque white mask will be used, which causes no effect.
+func (ab *AutoBatcher) Draw(s *Sprite, m Matrix, mask color.Color) {
+	pic := s.Picture()
+
+	pool := ab.pools[pic]
+	if pool == nil {
+		pool = &autoBatchPool{}
+		ab.pools[pic] = pool
+	}
+
+	var batch *Batch
+	switch {
+	case pic == ab.last && len(ab.batches) > 0:
+		batch = ab.batches[len(ab.batches)-1]
+	case ab.Unordered && pool.used > 0:
+		batch = pool.batches[0]
+	default:
+		batch = pool.next(pic)
+		ab.batches = append(ab.batches, batch)
+	}
+	ab.last = pic
+
+	s.DrawColorMask(batch, m, mask)
+}
+
+// Flush draws all the Sprites added since the last Flush onto the Target and removes them from the
+// AutoBatcher.
+func (ab *AutoBatcher) Flush(t Target) {
+	for _, batch := range ab.batches {
+		batch.Draw(t)
+		batch.Clear()
+	}
+	ab.flushes = len(ab.batches)
+
+	ab.batches = ab.batches[:0]
+	ab.last = nil
+	for _, pool := range ab.pools {
+		pool.used = 0
+	}
+}
+
+// Flushes returns the number of Batch draws done in the last Flush.
+func (ab *AutoBatcher) Flushes() int {
+	return ab.flushes
+}
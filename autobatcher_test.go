@@ -0,0 +1,41 @@
+package pixel_test
+
+import (
+	"image"
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+func TestAutoBatcher(t *testing.T) {
+	picA := pixel.PictureDataFromImage(image.NewRGBA(image.Rect(0, 0, 16, 16)))
+	picB := pixel.PictureDataFromImage(image.NewRGBA(image.Rect(0, 0, 16, 16)))
+	a := pixel.NewSprite(picA, picA.Bounds())
+	b := pixel.NewSprite(picB, picB.Bounds())
+
+	for _, tt := range []struct {
+		unordered bool
+		flushes   int
+	}{{false, 3}, {true, 2}} {
+		ab := pixel.NewAutoBatcher()
+		ab.Unordered = tt.unordered
+		target := &nopTarget{}
+
+		for frame := 0; frame < 2; frame++ {
+			ab.Draw(a, pixel.IM, nil)
+			ab.Draw(a, pixel.IM.Moved(pixel.V(16, 0)), nil)
+			ab.Draw(b, pixel.IM, nil)
+			ab.Draw(a, pixel.IM.Moved(pixel.V(32, 0)), nil)
+			ab.Flush(target)
+
+			if ab.Flushes() != tt.flushes {
+				t.Errorf("Unordered = %v: Flushes() = %d, want %d", tt.unordered, ab.Flushes(), tt.flushes)
+			}
+		}
+
+		ab.Flush(target)
+		if ab.Flushes() != 0 {
+			t.Errorf("Unordered = %v: Flushes() = %d after an empty frame, want 0", tt.unordered, ab.Flushes())
+		}
+	}
+}
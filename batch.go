@@ -9,11 +9,24 @@ import (
 //
 // To put an object into a Batch, just draw it onto it:
 //   object.Draw(batch)
+//
+// The triangles of the objects are appended to the Batch's container in the order the objects were
+// drawn onto the Batch, and they are drawn onto other Targets in that order too, so later objects
+// always cover earlier ones.
 type Batch struct {
 	cont Drawer
 
-	mat Matrix
-	col RGBA
+	mat  Matrix
+	col  RGBA
+	clip Rect
+
+	label   string
+	version uint64
+	mem     *memoryAccount
+	static  bool
+
+	growth      GrowthPolicy
+	growthState growthState
 }
 
 var _ BasicTarget = (*Batch)(nil)
@@ -24,9 +37,12 @@ var _ BasicTarget = (*Batch)(nil)
 // properties, that the supplied container supports. If you retain access to the container and
 // change it, call Dirty to notify Batch about the change.
 //
-// Note, that if the container does not support TrianglesColor, color masking will not work.
+// Note, that if the container does not support TrianglesColor, color masking will not work, see
+// SupportsColorMask.
 func NewBatch(container Triangles, pic Picture) *Batch {
 	b := &Batch{cont: Drawer{Triangles: container, Picture: pic}}
+	b.mem = newMemoryAccount("Batch", fmt.Sprintf("%p", b))
+	b.mem.setBytes(trianglesDataBytes(container))
 	b.SetMatrix(IM)
 	b.SetColorMask(Alpha(1))
 	return b
@@ -41,39 +57,246 @@ func NewBatch(container Triangles, pic Picture) *Batch {
 //   batch.Dirty()        // notify Batch about the change
 func (b *Batch) Dirty() {
 	b.cont.Dirty()
+	b.version++
+	b.mem.setBytes(trianglesDataBytes(b.cont.Triangles))
+}
+
+// DirtyRange notifies Batch about an external modification of the vertices in range [i, j) of it's
+// container. Unlike Dirty, only the modified vertices are updated in the Targets the Batch is drawn
+// to (for example, only that part of the vertex buffer is uploaded to the video memory).
+//
+//   (*container)[7].Position = pixel.V(100, 100)
+//   batch.DirtyRange(7, 8)
+//
+// The ranges of multiple calls between draws are merged into the smallest range covering all of
+// them. If the len of the container changed (including by Clear), the whole container is updated
+// as if Dirty was called.
+func (b *Batch) DirtyRange(i, j int) {
+	b.cont.DirtyRange(i, j)
+	b.version++
+	b.mem.setBytes(trianglesDataBytes(b.cont.Triangles))
+}
+
+// SetTriangles replaces the container of the Batch, keeping its Picture, Matrix, color mask and
+// the Targets it was drawn to, e.g. to reuse a pooled Batch for another layer:
+//
+//   batch.SetTriangles(layers[i])
+//   batch.Draw(win)
+//
+// The new container must support all the properties (TrianglesPosition, TrianglesColor and
+// TrianglesPicture) the current one supports, otherwise SetTriangles panics. The Batch is drawn as
+// if Dirty was called.
+func (b *Batch) SetTriangles(container Triangles) {
+	for _, p := range []struct {
+		name     string
+		old, new bool
+	}{
+		{"TrianglesPosition", isTrianglesPosition(b.cont.Triangles), isTrianglesPosition(container)},
+		{"TrianglesColor", isTrianglesColor(b.cont.Triangles), isTrianglesColor(container)},
+		{"TrianglesPicture", isTrianglesPicture(b.cont.Triangles), isTrianglesPicture(container)},
+	} {
+		if p.old && !p.new {
+			panic(fmt.Errorf("(%T).SetTriangles: container %T doesn't support %s like %T", b, container, p.name, b.cont.Triangles))
+		}
+	}
+	b.cont.Triangles = container
+	b.Dirty()
 }
 
-// Clear removes all objects from the Batch.
+func isTrianglesPosition(t Triangles) bool {
+	_, ok := t.(TrianglesPosition)
+	return ok
+}
+
+func isTrianglesColor(t Triangles) bool {
+	_, ok := t.(TrianglesColor)
+	return ok
+}
+
+func isTrianglesPicture(t Triangles) bool {
+	_, ok := t.(TrianglesPicture)
+	return ok
+}
+
+// Clear removes all objects from the Batch. With GrowthShrink (see SetGrowthPolicy), it may also
+// shrink the container.
 func (b *Batch) Clear() {
+	oldLen := b.cont.Triangles.Len()
 	b.cont.Triangles.SetLen(0)
-	b.cont.Dirty()
+	b.shrink(oldLen)
+	b.Dirty()
+}
+
+// Len returns the number of vertices currently in the Batch (three per triangle), e.g. to draw
+// and clear the Batch once it grows over a threshold:
+//
+//   if batch.Len() > 60000 {
+//       batch.Draw(win)
+//       batch.Clear()
+//   }
+func (b *Batch) Len() int {
+	return b.cont.Triangles.Len()
+}
+
+// Cap returns the number of vertices the container of the Batch can hold without growing. If the
+// container is a TrianglesData, it's the capacity of the slice. Other containers report their
+// capacity with a Cap() int method, if they don't have one, Cap returns Len.
+func (b *Batch) Cap() int {
+	switch cont := b.cont.Triangles.(type) {
+	case *TrianglesData:
+		return cap(*cont)
+	case interface{ Cap() int }:
+		return cont.Cap()
+	}
+	return b.Len()
+}
+
+// Triangles returns a copy of the Batch's current content as TrianglesData.
+//
+// Only the properties supported by the Batch's container (TrianglesPosition, TrianglesColor and
+// TrianglesPicture) are copied, the rest is left with default values. The returned TrianglesData
+// is independent of the Batch, so it's safe to modify or keep it around.
+func (b *Batch) Triangles() *TrianglesData {
+	td := MakeTrianglesData(b.cont.Triangles.Len())
+	td.Update(b.cont.Triangles)
+	return td
+}
+
+// TrianglesInPictureRect returns the indices of the triangles in the Batch whose picture
+// coordinates all lie within the rectangle (including its borders), e.g. to highlight the sprites
+// drawn from a frame of an atlas in a debugging tool. Triangle i consists of the vertices 3*i,
+// 3*i+1 and 3*i+2 of the container.
+//
+// Only the triangles drawn with the Picture are considered, i.e. with a non-zero intensity at any
+// vertex. The container must support TrianglesPicture, otherwise no triangles are returned.
+func (b *Batch) TrianglesInPictureRect(r Rect) []int {
+	tp, ok := b.cont.Triangles.(TrianglesPicture)
+	if !ok {
+		return nil
+	}
+	r = r.Norm()
+	var indices []int
+	for i := 0; i+2 < b.cont.Triangles.Len(); i += 3 {
+		inside, pictured := true, false
+		for k := i; k < i+3; k++ {
+			pic, intensity := tp.Picture(k)
+			inside = inside && r.Contains(pic)
+			pictured = pictured || intensity != 0
+		}
+		if inside && pictured {
+			indices = append(indices, i/3)
+		}
+	}
+	return indices
 }
 
 // Draw draws all objects that are currently in the Batch onto another Target.
 func (b *Batch) Draw(t Target) {
+	if dt, ok := t.(DebugTarget); ok && b.label != "" {
+		dt.PushDebugGroup(b.label)
+		defer dt.PopDebugGroup()
+	}
 	b.cont.Draw(t)
 }
 
+// Version returns a number which changes whenever the content of the Batch changes: when an object
+// is drawn onto it, and on Clear, Dirty and DirtyRange. Comparing it with an earlier Version tells
+// whether the Batch needs to be drawn again, e.g. to skip redrawing a static UI.
+func (b *Batch) Version() uint64 {
+	return b.version
+}
+
+// SetLabel sets the name of the Batch. When the Batch is drawn onto a DebugTarget, its draws are
+// grouped under this name in graphics debuggers. Batches have no label by default.
+func (b *Batch) SetLabel(label string) {
+	b.label = label
+	b.mem.setName(label)
+}
+
+// Label returns the label of the Batch, see SetLabel.
+func (b *Batch) Label() string {
+	return b.label
+}
+
 // SetMatrix sets a Matrix that every point will be projected by.
 func (b *Batch) SetMatrix(m Matrix) {
 	b.mat = m
 }
 
+// SupportsColorMask returns whether the container of the Batch supports TrianglesColor, which is
+// needed for SetColorMask.
+func (b *Batch) SupportsColorMask() bool {
+	_, ok := b.cont.Triangles.(TrianglesColor)
+	return ok
+}
+
 // SetColorMask sets a mask color used in the following draws onto the Batch.
+//
+// SetColorMask panics if the mask is not white (or nil) and the container doesn't support
+// TrianglesColor, because the mask would have no effect.
 func (b *Batch) SetColorMask(c color.Color) {
 	if c == nil {
 		b.col = Alpha(1)
 		return
 	}
-	b.col = ToRGBA(c)
+	col := ToRGBA(c)
+	if col != Alpha(1) && !b.SupportsColorMask() {
+		panic(fmt.Errorf("(%T).SetColorMask: container %T doesn't support TrianglesColor, the mask %v would have no effect", b, b.cont.Triangles, col))
+	}
+	b.col = col
+}
+
+// SetClipRect sets a rectangle which clips the following draws onto the Batch, e.g. for scrolling
+// lists in a batched UI. The zero Rect (the default) turns the clipping off.
+//
+// The triangles are clipped against the rectangle when they're drawn onto the Batch, after the
+// Batch's Matrix is applied. Triangles fully inside are added unchanged, triangles fully outside
+// are dropped and the rest is cut at the edges of the rectangle, with the colors and the picture
+// coordinates interpolated for the new vertices. Unlike a scissor rectangle of a Target, the clip
+// rectangle may change between the draws, while the whole Batch is still drawn at once.
+func (b *Batch) SetClipRect(r Rect) {
+	b.clip = r.Norm()
+}
+
+// ClipRect returns the clip rectangle set by SetClipRect.
+func (b *Batch) ClipRect() Rect {
+	return b.clip
+}
+
+// SetStatic sets whether the objects drawn onto the Batch are mostly static. Normally, every draw
+// onto the Batch transforms the triangles of the object by the Matrix and the color mask of the
+// Batch. For a static Batch, the transformed triangles are kept and reused as long as neither the
+// triangles, nor the Matrix, nor the color mask changed, e.g. for decorations drawn onto the Batch
+// every frame without moving:
+//
+//   decorations.SetStatic(true)
+//   decorations.SetMatrix(pixel.IM.Scaled(pixel.ZV, 4))
+//   for !win.Closed() {
+//       decorations.Clear()
+//       for _, d := range scenery {
+//           d.Draw(decorations, d.Matrix) // transformed only in the first frame
+//       }
+//       ...
+//   }
+//
+// With the identity Matrix and no color mask, there's nothing to transform, so SetStatic makes no
+// difference. Batches aren't static by default.
+func (b *Batch) SetStatic(static bool) {
+	b.static = static
+}
+
+// Static returns whether the Batch is static, see SetStatic.
+func (b *Batch) Static() bool {
+	return b.static
 }
 
 // MakeTriangles returns a specialized copy of the provided Triangles that draws onto this Batch.
 func (b *Batch) MakeTriangles(t Triangles) TargetTriangles {
 	bt := &batchTriangles{
-		tri: t.Copy(),
-		tmp: MakeTrianglesData(t.Len()),
-		dst: b,
+		tri:   t.Copy(),
+		tmp:   MakeTrianglesData(t.Len()),
+		dst:   b,
+		cache: &batchTransform{},
 	}
 	return bt
 }
@@ -94,6 +317,20 @@ type batchTriangles struct {
 	tri Triangles
 	tmp *TrianglesData
 	dst *Batch
+
+	// cache describes the content of tmp, it's shared with the slices, which share tmp too
+	cache   *batchTransform
+	partial bool // a slice of other batchTriangles
+
+	// buffers for clipping
+	clipped, poly, polyTmp TrianglesData
+}
+
+// batchTransform describes the transformed triangles kept for a static Batch.
+type batchTransform struct {
+	valid bool
+	mat   Matrix
+	col   RGBA
 }
 
 func (bt *batchTriangles) Len() int {
@@ -103,42 +340,150 @@ func (bt *batchTriangles) Len() int {
 func (bt *batchTriangles) SetLen(len int) {
 	bt.tri.SetLen(len)
 	bt.tmp.SetLen(len)
+	bt.cache.valid = false
 }
 
 func (bt *batchTriangles) Slice(i, j int) Triangles {
 	return &batchTriangles{
-		tri: bt.tri.Slice(i, j),
-		tmp: bt.tmp.Slice(i, j).(*TrianglesData),
-		dst: bt.dst,
+		tri:     bt.tri.Slice(i, j),
+		tmp:     bt.tmp.Slice(i, j).(*TrianglesData),
+		dst:     bt.dst,
+		cache:   bt.cache,
+		partial: true,
 	}
 }
 
 func (bt *batchTriangles) Update(t Triangles) {
 	bt.tri.Update(t)
+	bt.cache.valid = false
 }
 
 func (bt *batchTriangles) Copy() Triangles {
 	return &batchTriangles{
-		tri: bt.tri.Copy(),
-		tmp: bt.tmp.Copy().(*TrianglesData),
-		dst: bt.dst,
+		tri:   bt.tri.Copy(),
+		tmp:   bt.tmp.Copy().(*TrianglesData),
+		dst:   bt.dst,
+		cache: &batchTransform{},
 	}
 }
 
 func (bt *batchTriangles) draw(bp *batchPicture) {
-	bt.tmp.Update(bt.tri)
-
-	for i := range *bt.tmp {
-		(*bt.tmp)[i].Position = bt.dst.mat.Project((*bt.tmp)[i].Position)
-		(*bt.tmp)[i].Color = bt.dst.col.Mul((*bt.tmp)[i].Color)
+	if bt.dst.clip != (Rect{}) {
+		bt.drawClipped()
+		return
 	}
 
+	bt.dst.reserve(bt.tri.Len())
 	cont := bt.dst.cont.Triangles
 	cont.SetLen(cont.Len() + bt.tri.Len())
 	added := cont.Slice(cont.Len()-bt.tri.Len(), cont.Len())
 	added.Update(bt.tri)
-	added.Update(bt.tmp)
-	bt.dst.cont.Dirty()
+
+	// fast path: with the identity matrix and a white mask, there's nothing to transform
+	if bt.dst.mat != IM || bt.dst.col != Alpha(1) {
+		bt.transform()
+		added.Update(bt.tmp)
+	}
+
+	bt.dst.Dirty()
+}
+
+// transform fills tmp with the triangles transformed by the Matrix and the color mask of the Batch.
+// For a static Batch, tmp is left as it is if it's still up to date.
+func (bt *batchTriangles) transform() {
+	c := bt.cache
+	if bt.dst.static && !bt.partial && c.valid && c.mat == bt.dst.mat && c.col == bt.dst.col {
+		return
+	}
+
+	bt.tmp.Update(bt.tri)
+
+	bt.dst.mat.ProjectTrianglesData(bt.tmp)
+	for i := range *bt.tmp {
+		(*bt.tmp)[i].Color = bt.dst.col.Mul((*bt.tmp)[i].Color)
+	}
+
+	// a slice transforms only its part of the shared tmp
+	c.valid = !bt.partial
+	c.mat, c.col = bt.dst.mat, bt.dst.col
+}
+
+// drawClipped appends the triangles clipped by the clip rectangle of the Batch.
+func (bt *batchTriangles) drawClipped() {
+	bt.transform()
+
+	clip := bt.dst.clip
+	clipped := bt.clipped[:0]
+	for i := 0; i+2 < len(*bt.tmp); i += 3 {
+		tri := (*bt.tmp)[i : i+3]
+
+		inside := true
+		for _, v := range tri {
+			if !clip.Contains(v.Position) {
+				inside = false
+				break
+			}
+		}
+		if inside {
+			clipped = append(clipped, tri...)
+			continue
+		}
+
+		poly := clipPolygon(append(bt.poly[:0], tri...), clip, &bt.polyTmp)
+		bt.poly = poly
+		for j := 1; j+1 < len(poly); j++ {
+			clipped = append(clipped, poly[0], poly[j], poly[j+1])
+		}
+	}
+	bt.clipped = clipped
+
+	if len(clipped) > 0 {
+		bt.dst.reserve(len(clipped))
+		cont := bt.dst.cont.Triangles
+		cont.SetLen(cont.Len() + len(clipped))
+		cont.Slice(cont.Len()-len(clipped), cont.Len()).Update(&clipped)
+	}
+
+	bt.dst.Dirty()
+}
+
+// clipPolygon clips the convex polygon by the rectangle (Sutherland-Hodgman), tmp is a buffer for
+// the intermediate results. The returned polygon shares the memory with poly or tmp.
+func clipPolygon(poly TrianglesData, r Rect, tmp *TrianglesData) TrianglesData {
+	// the signed distances from the four sides, positive inside
+	sides := [...]func(v Vec) float64{
+		func(v Vec) float64 { return v.X - r.Min.X },
+		func(v Vec) float64 { return r.Max.X - v.X },
+		func(v Vec) float64 { return v.Y - r.Min.Y },
+		func(v Vec) float64 { return r.Max.Y - v.Y },
+	}
+
+	in, out := poly, (*tmp)[:0]
+	for _, dist := range sides {
+		out = out[:0]
+		for i := range in {
+			a, b := in[i], in[(i+1)%len(in)]
+			da, db := dist(a.Position), dist(b.Position)
+			if da >= 0 {
+				out = append(out, a)
+			}
+			if (da >= 0) != (db >= 0) {
+				t := da / (da - db)
+				v := a
+				v.Position = Lerp(a.Position, b.Position, t)
+				v.Color = a.Color.Add(b.Color.Sub(a.Color).Scaled(t))
+				v.Picture = Lerp(a.Picture, b.Picture, t)
+				v.Intensity = a.Intensity + (b.Intensity-a.Intensity)*t
+				out = append(out, v)
+			}
+		}
+		in, out = out, in
+		if len(in) == 0 {
+			break
+		}
+	}
+	*tmp = out
+	return in
 }
 
 func (bt *batchTriangles) Draw() {
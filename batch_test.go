@@ -0,0 +1,420 @@
+package pixel_test
+
+import (
+	"image"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+func TestBatchTriangles(t *testing.T) {
+	pic := pixel.PictureDataFromImage(image.NewRGBA(image.Rect(0, 0, 16, 16)))
+	sprite := pixel.NewSprite(pic, pic.Bounds())
+	batch := pixel.NewBatch(&pixel.TrianglesData{}, pic)
+
+	sprite.Draw(batch, pixel.IM.Moved(pixel.V(8, 8)))
+
+	td := batch.Triangles()
+	if td.Len() != 6 {
+		t.Fatalf("got %d vertices, want 6", td.Len())
+	}
+	if got, want := td.Position(0), pixel.V(0, 0); got != want {
+		t.Errorf("got position %v, want %v", got, want)
+	}
+
+	// the copy must be independent of the Batch
+	(*td)[0].Position = pixel.V(100, 100)
+	if got := batch.Triangles().Position(0); got != pixel.V(0, 0) {
+		t.Errorf("modifying the copy changed the Batch: %v", got)
+	}
+}
+
+func BenchmarkBatchDraw(b *testing.B) {
+	pic := pixel.PictureDataFromImage(image.NewRGBA(image.Rect(0, 0, 64, 64)))
+	tri := pixel.MakeTrianglesData(600)
+	for _, bc := range []struct {
+		name string
+		mat  pixel.Matrix
+	}{
+		{"Identity", pixel.IM},
+		{"Transformed", pixel.IM.Moved(pixel.V(10, 20))},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			batch := pixel.NewBatch(&pixel.TrianglesData{}, pic)
+			batch.SetMatrix(bc.mat)
+			d := pixel.Drawer{Triangles: tri, Picture: pic}
+			for i := 0; i < b.N; i++ {
+				batch.Clear()
+				d.Draw(batch)
+			}
+		})
+	}
+}
+
+// debugTarget records the debug groups and the draws made onto it.
+type debugTarget struct {
+	nopTarget
+	log []string
+}
+
+func (dt *debugTarget) PushDebugGroup(name string) { dt.log = append(dt.log, "push "+name) }
+func (dt *debugTarget) PopDebugGroup()             { dt.log = append(dt.log, "pop") }
+
+func TestBatchLabel(t *testing.T) {
+	batch := pixel.NewBatch(&pixel.TrianglesData{}, nil)
+	target := &debugTarget{}
+
+	batch.Draw(target)
+	if len(target.log) != 0 {
+		t.Errorf("unlabeled batch made debug groups %v", target.log)
+	}
+
+	batch.SetLabel("particles")
+	batch.Draw(target)
+	if len(target.log) != 2 || target.log[0] != "push particles" || target.log[1] != "pop" {
+		t.Errorf("labeled batch made debug groups %v, want [push particles pop]", target.log)
+	}
+}
+
+func TestBatchVersion(t *testing.T) {
+	batch := pixel.NewBatch(&pixel.TrianglesData{}, nil)
+	tri := pixel.MakeTrianglesData(3)
+	d := pixel.Drawer{Triangles: tri}
+
+	v := batch.Version()
+	batch.Draw(&nopTarget{})
+	if batch.Version() != v {
+		t.Errorf("drawing the batch onto a target changed its version")
+	}
+
+	for _, change := range []func(){
+		func() { d.Draw(batch) },
+		func() { batch.DirtyRange(0, 1) },
+		func() { batch.Dirty() },
+		func() { batch.Clear() },
+	} {
+		change()
+		if batch.Version() == v {
+			t.Errorf("version didn't change")
+		}
+		v = batch.Version()
+	}
+}
+
+func TestBatchDirtyRange(t *testing.T) {
+	container := pixel.MakeTrianglesData(9)
+	batch := pixel.NewBatch(container, nil)
+	target := &nopTarget{}
+	batch.Draw(target)
+
+	for i := range *container {
+		(*container)[i].Position = pixel.V(float64(i), 1)
+	}
+	batch.DirtyRange(2, 3)
+	batch.DirtyRange(5, 6)
+	batch.Draw(target)
+
+	// the ranges are merged, the vertices outside of them are not updated
+	for i, v := range *target.tris {
+		want := pixel.ZV
+		if i >= 2 && i < 6 {
+			want = pixel.V(float64(i), 1)
+		}
+		if v.Position != want {
+			t.Errorf("vertex %d at %v, want %v", i, v.Position, want)
+		}
+	}
+
+	// changing the len updates everything
+	container.SetLen(12)
+	batch.DirtyRange(11, 12)
+	batch.Draw(target)
+	if target.tris.Len() != 12 {
+		t.Fatalf("target has %d vertices after growing, want 12", target.tris.Len())
+	}
+	for i, v := range *target.tris {
+		if v.Position != (*container)[i].Position {
+			t.Errorf("vertex %d at %v after growing, want %v", i, v.Position, (*container)[i].Position)
+		}
+	}
+
+	// a range pending before Clear doesn't outlive it
+	batch.DirtyRange(0, 3)
+	batch.Clear()
+	batch.Draw(target)
+	if target.tris.Len() != 0 {
+		t.Errorf("target has %d vertices after Clear, want 0", target.tris.Len())
+	}
+}
+
+func TestBatchSetTriangles(t *testing.T) {
+	a := pixel.MakeTrianglesData(3)
+	b := pixel.MakeTrianglesData(6)
+	for i := range *b {
+		(*b)[i].Position = pixel.V(float64(i), 2)
+	}
+	batch := pixel.NewBatch(a, nil)
+	target := &nopTarget{}
+	batch.Draw(target)
+
+	v := batch.Version()
+	batch.SetTriangles(b)
+	if batch.Version() == v {
+		t.Errorf("version didn't change")
+	}
+	batch.Draw(target)
+	if target.tris.Len() != 6 {
+		t.Fatalf("target has %d vertices, want 6", target.tris.Len())
+	}
+	for i, v := range *target.tris {
+		if v.Position != (*b)[i].Position {
+			t.Errorf("vertex %d at %v, want %v", i, v.Position, (*b)[i].Position)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("no panic for a container without TrianglesColor")
+		}
+	}()
+	batch.SetTriangles(&positionTriangles{})
+}
+
+func TestBatchLen(t *testing.T) {
+	batch := pixel.NewBatch(&pixel.TrianglesData{}, nil)
+	d := pixel.Drawer{Triangles: pixel.MakeTrianglesData(6)}
+	for i := 1; i <= 3; i++ {
+		d.Draw(batch)
+		if batch.Len() != 6*i {
+			t.Errorf("Len() = %d after %d draws, want %d", batch.Len(), i, 6*i)
+		}
+		if batch.Cap() < batch.Len() {
+			t.Errorf("Cap() = %d is less than Len() = %d", batch.Cap(), batch.Len())
+		}
+	}
+	batch.Clear()
+	if batch.Len() != 0 {
+		t.Errorf("Len() = %d after Clear, want 0", batch.Len())
+	}
+
+	pos := positionTriangles(make([]pixel.Vec, 9))
+	if got := pixel.NewBatch(&pos, nil).Cap(); got != 9 {
+		t.Errorf("Cap() = %d for a container without capacity, want its Len 9", got)
+	}
+}
+
+func TestBatchStatic(t *testing.T) {
+	tri := pixel.MakeTrianglesData(3)
+	for i := range *tri {
+		(*tri)[i].Position = pixel.V(float64(i), 1)
+		(*tri)[i].Color = pixel.Alpha(1)
+	}
+	d := pixel.Drawer{Triangles: tri}
+
+	batch := pixel.NewBatch(&pixel.TrianglesData{}, nil)
+	batch.SetStatic(true)
+	check := func(name string, m pixel.Matrix, mask pixel.RGBA) {
+		t.Helper()
+		batch.Clear()
+		batch.SetMatrix(m)
+		batch.SetColorMask(mask)
+		d.Draw(batch)
+		got := batch.Triangles()
+		for i := range *tri {
+			if want := m.Project((*tri)[i].Position); (*got)[i].Position != want {
+				t.Errorf("%s: vertex %d at %v, want %v", name, i, (*got)[i].Position, want)
+			}
+			if want := mask.Mul((*tri)[i].Color); (*got)[i].Color != want {
+				t.Errorf("%s: vertex %d colored %v, want %v", name, i, (*got)[i].Color, want)
+			}
+		}
+	}
+
+	m := pixel.IM.Scaled(pixel.ZV, 2)
+	check("first draw", m, pixel.Alpha(1))
+	check("same draw", m, pixel.Alpha(1))
+	check("moved", m.Moved(pixel.V(5, 0)), pixel.Alpha(1))
+	check("masked", m.Moved(pixel.V(5, 0)), pixel.RGB(1, 0, 0))
+
+	(*tri)[1].Position = pixel.V(7, 7)
+	d.DirtyRange(1, 2)
+	check("vertex changed", m.Moved(pixel.V(5, 0)), pixel.RGB(1, 0, 0))
+
+	(*tri)[2].Position = pixel.V(-3, 3)
+	d.Dirty()
+	check("triangles changed", m.Moved(pixel.V(5, 0)), pixel.RGB(1, 0, 0))
+}
+
+func TestBatchGrowthPolicy(t *testing.T) {
+	d := pixel.Drawer{Triangles: pixel.MakeTrianglesData(3)}
+
+	exact := pixel.NewBatch(&pixel.TrianglesData{}, nil)
+	exact.SetGrowthPolicy(pixel.GrowthExact)
+	double := pixel.NewBatch(&pixel.TrianglesData{}, nil)
+	for i := 1; i <= 5; i++ {
+		d.Draw(exact)
+		d.Draw(double)
+		if exact.Cap() != 3*i {
+			t.Errorf("GrowthExact: Cap() = %d after %d draws, want %d", exact.Cap(), i, 3*i)
+		}
+	}
+	if double.Cap() != 24 {
+		t.Errorf("GrowthDouble: Cap() = %d after 5 draws, want 24", double.Cap())
+	}
+
+	shrink := pixel.NewBatch(&pixel.TrianglesData{}, nil)
+	shrink.SetGrowthPolicy(pixel.GrowthShrink)
+	big := pixel.Drawer{Triangles: pixel.MakeTrianglesData(300)}
+	big.Draw(shrink)
+	for i := 0; i < 60; i++ {
+		shrink.Clear()
+		d.Draw(shrink)
+	}
+	if shrink.Cap() < 300 {
+		t.Errorf("GrowthShrink: shrunk to %d within 60 clears of a large content", shrink.Cap())
+	}
+	for i := 0; i < 60; i++ {
+		shrink.Clear()
+		d.Draw(shrink)
+	}
+	if shrink.Cap() != 6 {
+		t.Errorf("GrowthShrink: Cap() = %d after 60 clears of small content, want 6", shrink.Cap())
+	}
+	if shrink.Len() != 3 {
+		t.Errorf("GrowthShrink: Len() = %d, want 3", shrink.Len())
+	}
+}
+
+func BenchmarkBatchGrowthPolicy(b *testing.B) {
+	// a content fluctuating between 30 and 3000 vertices, with a rare spike of 30000
+	sizes := make([]int, 120)
+	for i := range sizes {
+		sizes[i] = 10 + (i*37)%1000
+	}
+	sizes[60] = 10000
+	tri := pixel.MakeTrianglesData(3)
+
+	for _, policy := range []struct {
+		name   string
+		policy pixel.GrowthPolicy
+	}{
+		{"Double", pixel.GrowthDouble},
+		{"Exact", pixel.GrowthExact},
+		{"Shrink", pixel.GrowthShrink},
+	} {
+		b.Run(policy.name, func(b *testing.B) {
+			b.ReportAllocs()
+			batch := pixel.NewBatch(&pixel.TrianglesData{}, nil)
+			batch.SetGrowthPolicy(policy.policy)
+			d := pixel.Drawer{Triangles: tri}
+			capSum := 0
+			for i := 0; i < b.N; i++ {
+				batch.Clear()
+				for j := 0; j < sizes[i%len(sizes)]; j++ {
+					d.Draw(batch)
+				}
+				capSum += batch.Cap()
+			}
+			b.ReportMetric(float64(capSum)/float64(b.N), "vertices-cap/op")
+		})
+	}
+}
+
+func TestBatchClipRect(t *testing.T) {
+	pic := pixel.PictureDataFromImage(image.NewRGBA(image.Rect(0, 0, 16, 16)))
+	batch := pixel.NewBatch(&pixel.TrianglesData{}, pic)
+	batch.SetClipRect(pixel.R(4, -10, 20, 12))
+
+	sprite := pixel.NewSprite(pic, pic.Bounds())
+	sprite.Draw(batch, pixel.IM.Moved(pixel.V(8, 8)))     // partially clipped
+	sprite.Draw(batch, pixel.IM.Moved(pixel.V(100, 100))) // fully outside
+
+	batch.SetClipRect(pixel.Rect{})
+	sprite.Draw(batch, pixel.IM.Moved(pixel.V(100, 100))) // clipping turned off
+
+	td := batch.Triangles()
+	area := 0.0
+	for i := 0; i+2 < td.Len(); i += 3 {
+		a, b, c := td.Position(i), td.Position(i+1), td.Position(i+2)
+		area += math.Abs(a.To(b).Cross(a.To(c))) / 2
+
+		for j := i; j < i+3; j++ {
+			pos := td.Position(j)
+			// the sprite maps its picture 1:1, so the picture coordinates must follow the positions
+			if uv, _ := td.Picture(j); pos.X < 50 && uv.To(pos).Len() > 1e-9 {
+				t.Errorf("vertex at %v has picture coordinates %v", pos, uv)
+			}
+		}
+	}
+
+	// 12x12 of the first sprite and the whole third one
+	if want := 12.0*12 + 16*16; math.Abs(area-want) > 1e-9 {
+		t.Errorf("got area %v, want %v", area, want)
+	}
+}
+
+// positionTriangles are Triangles supporting only TrianglesPosition.
+type positionTriangles []pixel.Vec
+
+func (pt *positionTriangles) Len() int                       { return len(*pt) }
+func (pt *positionTriangles) SetLen(len int)                 { *pt = make(positionTriangles, len) }
+func (pt *positionTriangles) Slice(i, j int) pixel.Triangles { s := (*pt)[i:j]; return &s }
+func (pt *positionTriangles) Position(i int) pixel.Vec       { return (*pt)[i] }
+
+func (pt *positionTriangles) Copy() pixel.Triangles {
+	c := append(positionTriangles(nil), *pt...)
+	return &c
+}
+
+func (pt *positionTriangles) Update(t pixel.Triangles) {
+	if tp, ok := t.(pixel.TrianglesPosition); ok {
+		for i := range *pt {
+			(*pt)[i] = tp.Position(i)
+		}
+	}
+}
+
+func TestBatchSupportsColorMask(t *testing.T) {
+	if b := pixel.NewBatch(&pixel.TrianglesData{}, nil); !b.SupportsColorMask() {
+		t.Error("Batch of TrianglesData doesn't support the color mask")
+	}
+
+	b := pixel.NewBatch(&positionTriangles{}, nil)
+	if b.SupportsColorMask() {
+		t.Error("Batch of positionTriangles supports the color mask")
+	}
+	b.SetColorMask(nil)
+	b.SetColorMask(pixel.Alpha(1))
+
+	defer func() {
+		if recover() == nil {
+			t.Error("SetColorMask with an unsupported container didn't panic")
+		}
+	}()
+	b.SetColorMask(pixel.RGB(1, 0, 0))
+}
+
+func TestBatchTrianglesInPictureRect(t *testing.T) {
+	pic := pixel.MakePictureData(pixel.R(0, 0, 64, 32))
+	batch := pixel.NewBatch(&pixel.TrianglesData{}, pic)
+	left := pixel.NewSprite(pic, pixel.R(0, 0, 32, 32))
+	right := pixel.NewSprite(pic, pixel.R(32, 0, 64, 32))
+	right.Draw(batch, pixel.IM)
+	left.Draw(batch, pixel.IM)
+	right.Draw(batch, pixel.IM.Moved(pixel.V(100, 0)))
+
+	imd := pixel.MakeTrianglesData(3) // a triangle without the Picture, zero intensity
+	pixel.NewBatch(imd, nil).Draw(batch)
+
+	if got, want := batch.TrianglesInPictureRect(pixel.R(32, 0, 64, 32)), []int{0, 1, 4, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("right frame: got triangles %v, want %v", got, want)
+	}
+	if got, want := batch.TrianglesInPictureRect(pixel.R(0, 32, 32, 0)), []int{2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("left frame: got triangles %v, want %v", got, want)
+	}
+	if got := pixel.NewBatch(&positionTriangles{}, nil).TrianglesInPictureRect(pic.Rect); got != nil {
+		t.Errorf("container without TrianglesPicture: got triangles %v, want none", got)
+	}
+}
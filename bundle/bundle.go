@@ -0,0 +1,131 @@
+// Package bundle packs the assets of a game (pictures with their sprite frames, fonts, triangle
+// meshes and arbitrary blobs) into a single file and loads them from it.
+//
+// A bundle is created by a Writer:
+//
+//   w := bundle.NewWriter(file)
+//   w.AddPicture("hero", heroPic, map[string]pixel.Rect{"idle": idle, "jump": jump})
+//   w.Compression = bundle.Deflate
+//   w.AddFont("ui", ttfBytes)
+//   if err := w.Close(); err != nil {
+//       // handle error
+//   }
+//
+// and read by a Reader, which only reads the index when opened and decodes each entry on the first
+// use. The bundle can be embedded into the executable:
+//
+//   //go:embed assets.bundle
+//   var assets embed.FS
+//
+//   b, err := bundle.OpenFS(assets, "assets.bundle")
+//   hero, err := b.OpenAtlas("hero")
+//   face, err := b.OpenFontFace("ui", 14)
+//
+// Every entry is stored with a SHA-256 hash of its content. A damaged entry fails with an
+// *EntryError wrapping ErrCorrupt when it's opened, the other entries can still be used.
+package bundle
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind is the type of an entry.
+type Kind uint8
+
+// Here's the list of all kinds of entries.
+const (
+	// KindBlob is an arbitrary sequence of bytes.
+	KindBlob Kind = iota
+
+	// KindPicture is a PictureData with optional named frames.
+	KindPicture
+
+	// KindFont is a TrueType font file.
+	KindFont
+
+	// KindTriangles is a TrianglesData.
+	KindTriangles
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindBlob:
+		return "blob"
+	case KindPicture:
+		return "picture"
+	case KindFont:
+		return "font"
+	case KindTriangles:
+		return "triangles"
+	}
+	return fmt.Sprintf("Kind(%d)", uint8(k))
+}
+
+// Compression is the method used to compress an entry.
+type Compression uint8
+
+// Here's the list of all compression methods.
+const (
+	// NoCompression stores the content as is, which is the fastest to load.
+	NoCompression Compression = iota
+
+	// Deflate compresses the content with DEFLATE. It works well for pictures with large uniform
+	// areas and for fonts.
+	Deflate
+)
+
+var (
+	// ErrNotFound is returned when there is no entry of the given name.
+	ErrNotFound = errors.New("entry not found")
+
+	// ErrKind is returned when an entry is opened as a different kind than it was added as.
+	ErrKind = errors.New("wrong kind of entry")
+
+	// ErrCorrupt is returned when the content of an entry doesn't match its hash or can't be
+	// decoded.
+	ErrCorrupt = errors.New("corrupt entry")
+
+	// ErrFormat is returned when a file is not a bundle or its index is damaged.
+	ErrFormat = errors.New("not a valid bundle")
+)
+
+// EntryError is the error of opening a single entry. Use errors.Is to check for ErrNotFound,
+// ErrKind and ErrCorrupt.
+type EntryError struct {
+	Name string
+	Err  error
+}
+
+func (e *EntryError) Error() string {
+	return fmt.Sprintf("bundle: entry %q: %v", e.Name, e.Err)
+}
+
+// Unwrap returns the cause of the error.
+func (e *EntryError) Unwrap() error {
+	return e.Err
+}
+
+// Info describes an entry of a bundle.
+type Info struct {
+	Name        string
+	Kind        Kind
+	Compression Compression
+
+	// Size is the size of the content, StoredSize is the size after the compression.
+	Size, StoredSize int64
+
+	// Hash is the SHA-256 hash of the content.
+	Hash [32]byte
+}
+
+// A bundle starts with the magic and the version, followed by the stored contents of the entries,
+// the index of the entries and finally the offset of the index and the magic again. All numbers
+// are little-endian.
+const (
+	magic   = "PXBUNDLE"
+	version = 1
+
+	headerSize = len(magic) + 4
+	footerSize = 8 + len(magic)
+)
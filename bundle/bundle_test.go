@@ -0,0 +1,156 @@
+package bundle_test
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/bundle"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func testPicture() *pixel.PictureData {
+	pd := pixel.MakePictureData(pixel.R(0, 0, 4, 2))
+	for i := range pd.Pix {
+		pd.Pix[i] = color.RGBA{uint8(i), uint8(2 * i), uint8(3 * i), 255}
+	}
+	return pd
+}
+
+func testTriangles() *pixel.TrianglesData {
+	td := pixel.MakeTrianglesData(3)
+	for i := range *td {
+		(*td)[i].Position = pixel.V(float64(i), -float64(i))
+		(*td)[i].Color = pixel.RGB(0.5, 0.25, 1)
+		(*td)[i].Picture = pixel.V(0.5, float64(i))
+		(*td)[i].Intensity = 1
+	}
+	return td
+}
+
+func writeBundle(t *testing.T, compression bundle.Compression) []byte {
+	var buf bytes.Buffer
+	w := bundle.NewWriter(&buf)
+	w.Compression = compression
+	frames := map[string]pixel.Rect{"left": pixel.R(0, 0, 2, 2), "right": pixel.R(2, 0, 4, 2)}
+	for _, err := range []error{
+		w.AddPicture("pic", testPicture(), frames),
+		w.AddFont("font", goregular.TTF),
+		w.AddTriangles("mesh", testTriangles()),
+		w.AddBlob("blob", []byte("hello")),
+		w.Close(),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, compression := range []bundle.Compression{bundle.NoCompression, bundle.Deflate} {
+		data := writeBundle(t, compression)
+		b, err := bundle.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if names := b.Names(); !reflect.DeepEqual(names, []string{"blob", "font", "mesh", "pic"}) {
+			t.Errorf("got names %v", names)
+		}
+
+		atlas, err := b.OpenAtlas("pic")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(atlas.Picture, testPicture()) {
+			t.Errorf("picture differs: %v", atlas.Picture)
+		}
+		if atlas.Frames["right"] != pixel.R(2, 0, 4, 2) || len(atlas.Frames) != 2 {
+			t.Errorf("got frames %v", atlas.Frames)
+		}
+
+		face, err := b.OpenFontFace("font", 12)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := face.GlyphAdvance('A'); !ok {
+			t.Error("font face has no glyph for A")
+		}
+
+		td, err := b.OpenTriangles("mesh")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(td, testTriangles()) {
+			t.Errorf("triangles differ: %v", *td)
+		}
+
+		blob, err := b.OpenBlob("blob")
+		if err != nil || string(blob) != "hello" {
+			t.Errorf("got blob %q, %v", blob, err)
+		}
+	}
+}
+
+func TestOpenFS(t *testing.T) {
+	fsys := fstest.MapFS{"assets.bundle": {Data: writeBundle(t, bundle.Deflate)}}
+	b, err := bundle.OpenFS(fsys, "assets.bundle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	if _, err := b.OpenPicture("pic"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEntryErrors(t *testing.T) {
+	data := writeBundle(t, bundle.NoCompression)
+
+	// damage the first byte of the pixels of the picture
+	info := func(b *bundle.Reader, name string) bundle.Info {
+		i, err := b.Info(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return i
+	}
+	b, err := bundle.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pic := info(b, "pic")
+	damaged := append([]byte(nil), data...)
+	damaged[12+pic.StoredSize-1] ^= 0xff
+
+	b, err = bundle.NewReader(bytes.NewReader(damaged), int64(len(damaged)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.OpenPicture("pic"); !errors.Is(err, bundle.ErrCorrupt) {
+		t.Errorf("damaged picture: got error %v, want ErrCorrupt", err)
+	}
+	var entryErr *bundle.EntryError
+	if _, err := b.OpenPicture("pic"); !errors.As(err, &entryErr) || entryErr.Name != "pic" {
+		t.Errorf("damaged picture: got error %v, want an EntryError", err)
+	}
+	if _, err := b.OpenFontFace("font", 12); err != nil {
+		t.Errorf("other entries must still work: %v", err)
+	}
+
+	if _, err := b.OpenPicture("missing"); !errors.Is(err, bundle.ErrNotFound) {
+		t.Errorf("missing entry: got error %v, want ErrNotFound", err)
+	}
+	if _, err := b.OpenTriangles("blob"); !errors.Is(err, bundle.ErrKind) {
+		t.Errorf("wrong kind: got error %v, want ErrKind", err)
+	}
+
+	if _, err := bundle.NewReader(bytes.NewReader(data[:len(data)-1]), int64(len(data)-1)); !errors.Is(err, bundle.ErrFormat) {
+		t.Errorf("truncated bundle: got error %v, want ErrFormat", err)
+	}
+}
@@ -0,0 +1,397 @@
+package bundle
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"image/color"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/faiface/pixel"
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+)
+
+// Atlas is a Picture of a bundle with its named frames.
+type Atlas struct {
+	Picture *pixel.PictureData
+	Frames  map[string]pixel.Rect
+}
+
+// Reader reads the entries of a bundle. The entries are read from the underlying io.ReaderAt and
+// decoded when they're opened for the first time, and cached after that.
+//
+// It's safe to use a Reader from multiple goroutines.
+type Reader struct {
+	r      io.ReaderAt
+	closer io.Closer
+
+	infos map[string]Info
+	offs  map[string]int64
+
+	mu    sync.Mutex
+	cache map[string]interface{} // decoded entries: *Atlas, *truetype.Font, *pixel.TrianglesData or []byte
+}
+
+// NewReader reads the index of the bundle of the given size from r.
+func NewReader(r io.ReaderAt, size int64) (*Reader, error) {
+	if size < int64(headerSize+footerSize) {
+		return nil, fmt.Errorf("bundle: %w: too short", ErrFormat)
+	}
+	header := make([]byte, headerSize)
+	footer := make([]byte, footerSize)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, fmt.Errorf("bundle: reading header: %w", err)
+	}
+	if _, err := r.ReadAt(footer, size-int64(footerSize)); err != nil {
+		return nil, fmt.Errorf("bundle: reading footer: %w", err)
+	}
+	if string(header[:len(magic)]) != magic || string(footer[8:]) != magic {
+		return nil, fmt.Errorf("bundle: %w: bad magic", ErrFormat)
+	}
+	if v := binary.LittleEndian.Uint32(header[len(magic):]); v != version {
+		return nil, fmt.Errorf("bundle: %w: unsupported version %d", ErrFormat, v)
+	}
+
+	indexOff := int64(binary.LittleEndian.Uint64(footer))
+	indexEnd := size - int64(footerSize)
+	if indexOff < int64(headerSize) || indexOff > indexEnd {
+		return nil, fmt.Errorf("bundle: %w: bad index offset", ErrFormat)
+	}
+	index := make([]byte, indexEnd-indexOff)
+	if _, err := r.ReadAt(index, indexOff); err != nil {
+		return nil, fmt.Errorf("bundle: reading index: %w", err)
+	}
+
+	br := &Reader{
+		r:     r,
+		infos: make(map[string]Info),
+		offs:  make(map[string]int64),
+		cache: make(map[string]interface{}),
+	}
+	d := decoder{b: index}
+	count := d.uint32()
+	for i := uint32(0); i < count && d.err == nil; i++ {
+		var info Info
+		info.Name = d.string()
+		info.Kind = Kind(d.byte())
+		info.Compression = Compression(d.byte())
+		off := d.int64()
+		info.StoredSize = d.int64()
+		info.Size = d.int64()
+		copy(info.Hash[:], d.bytes(len(info.Hash)))
+		if d.err == nil && (off < int64(headerSize) || info.StoredSize < 0 || off+info.StoredSize > indexOff) {
+			return nil, fmt.Errorf("bundle: %w: entry %q out of bounds", ErrFormat, info.Name)
+		}
+		br.infos[info.Name] = info
+		br.offs[info.Name] = off
+	}
+	if d.err != nil {
+		return nil, fmt.Errorf("bundle: %w: truncated index", ErrFormat)
+	}
+	return br, nil
+}
+
+// Open opens the bundle file at the path. The entries are read from the file as they're opened,
+// so the file stays open until Close.
+func Open(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	br, err := NewReader(f, stat.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	br.closer = f
+	return br, nil
+}
+
+// OpenFS opens the bundle file of the given name in the file system, e.g. an embed.FS. If the file
+// doesn't implement io.ReaderAt, it's read into the memory as a whole.
+func OpenFS(fsys fs.FS, name string) (*Reader, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if ra, ok := f.(io.ReaderAt); ok {
+		br, err := NewReader(ra, stat.Size())
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		br.closer = f
+		return br, nil
+	}
+
+	data, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+	return NewReader(bytes.NewReader(data), int64(len(data)))
+}
+
+// Close closes the file opened by Open or OpenFS. It does nothing for a Reader created by
+// NewReader. The entries which were already opened remain valid.
+func (br *Reader) Close() error {
+	if br.closer == nil {
+		return nil
+	}
+	return br.closer.Close()
+}
+
+// Names returns the names of all the entries in the bundle, sorted.
+func (br *Reader) Names() []string {
+	names := make([]string, 0, len(br.infos))
+	for name := range br.infos {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Info returns the description of an entry.
+func (br *Reader) Info(name string) (Info, error) {
+	info, ok := br.infos[name]
+	if !ok {
+		return Info{}, &EntryError{Name: name, Err: ErrNotFound}
+	}
+	return info, nil
+}
+
+// OpenBlob returns the content of an entry of any kind. The returned slice is shared by all the
+// callers, don't modify it.
+func (br *Reader) OpenBlob(name string) ([]byte, error) {
+	v, err := br.open(name, anyKind)
+	if err != nil {
+		return nil, err
+	}
+	return v.([]byte), nil
+}
+
+// OpenPicture returns the PictureData of a picture entry. The PictureData is shared by all the
+// callers, don't modify it.
+//
+// To upload many pictures to the video memory at once, pass them to pixelgl.UploadPictures.
+func (br *Reader) OpenPicture(name string) (*pixel.PictureData, error) {
+	atlas, err := br.OpenAtlas(name)
+	if err != nil {
+		return nil, err
+	}
+	return atlas.Picture, nil
+}
+
+// OpenAtlas returns the PictureData of a picture entry along with its named frames. The Atlas is
+// shared by all the callers, don't modify it.
+func (br *Reader) OpenAtlas(name string) (*Atlas, error) {
+	v, err := br.open(name, KindPicture)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*Atlas), nil
+}
+
+// OpenFontFace returns a new font.Face of a font entry with the given size in points. The font is
+// parsed once, the faces of different sizes share it.
+func (br *Reader) OpenFontFace(name string, size float64) (font.Face, error) {
+	v, err := br.open(name, KindFont)
+	if err != nil {
+		return nil, err
+	}
+	return truetype.NewFace(v.(*truetype.Font), &truetype.Options{Size: size}), nil
+}
+
+// OpenTriangles returns a copy of the TrianglesData of a triangles entry.
+func (br *Reader) OpenTriangles(name string) (*pixel.TrianglesData, error) {
+	v, err := br.open(name, KindTriangles)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*pixel.TrianglesData).Copy().(*pixel.TrianglesData), nil
+}
+
+// anyKind opens any entry as a blob.
+const anyKind Kind = math.MaxUint8
+
+// open returns the decoded content of an entry, decoding it if it's not cached yet.
+func (br *Reader) open(name string, kind Kind) (interface{}, error) {
+	info, ok := br.infos[name]
+	if !ok {
+		return nil, &EntryError{Name: name, Err: ErrNotFound}
+	}
+	if kind != anyKind && info.Kind != kind {
+		return nil, &EntryError{Name: name, Err: fmt.Errorf("%w: %v, not %v", ErrKind, info.Kind, kind)}
+	}
+
+	key := name
+	if kind == anyKind {
+		key = "\x00blob:" + name
+	}
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	if v, ok := br.cache[key]; ok {
+		return v, nil
+	}
+
+	content, err := br.read(info)
+	if err != nil {
+		return nil, &EntryError{Name: name, Err: err}
+	}
+	var v interface{}
+	switch {
+	case kind == anyKind || info.Kind == KindBlob:
+		v = content
+	case info.Kind == KindPicture:
+		v, err = decodeAtlas(content)
+	case info.Kind == KindFont:
+		v, err = truetype.Parse(content)
+	case info.Kind == KindTriangles:
+		v, err = decodeTriangles(content)
+	default:
+		err = fmt.Errorf("unknown kind %v", info.Kind)
+	}
+	if err != nil {
+		return nil, &EntryError{Name: name, Err: fmt.Errorf("%w: %v", ErrCorrupt, err)}
+	}
+	br.cache[key] = v
+	return v, nil
+}
+
+// read reads, decompresses and verifies the content of an entry.
+func (br *Reader) read(info Info) ([]byte, error) {
+	stored := make([]byte, info.StoredSize)
+	if _, err := br.r.ReadAt(stored, br.offs[info.Name]); err != nil {
+		return nil, err
+	}
+
+	var content []byte
+	switch info.Compression {
+	case NoCompression:
+		content = stored
+	case Deflate:
+		fr := flate.NewReader(bytes.NewReader(stored))
+		var err error
+		content, err = io.ReadAll(io.LimitReader(fr, info.Size+1))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown compression %d", ErrCorrupt, info.Compression)
+	}
+
+	if int64(len(content)) != info.Size || sha256.Sum256(content) != info.Hash {
+		return nil, fmt.Errorf("%w: hash mismatch", ErrCorrupt)
+	}
+	return content, nil
+}
+
+func decodeAtlas(content []byte) (*Atlas, error) {
+	d := decoder{b: content}
+	pd := &pixel.PictureData{Rect: d.rect()}
+	pd.Stride = int(d.uint32())
+	n := d.uint32()
+	frames := make(map[string]pixel.Rect)
+	for i, count := uint32(0), d.uint32(); i < count && d.err == nil; i++ {
+		name := d.string()
+		frames[name] = d.rect()
+	}
+	pix := d.bytes(4 * int(n))
+	if d.err != nil || len(d.b) != 0 {
+		return nil, fmt.Errorf("invalid picture")
+	}
+	pd.Pix = make([]color.RGBA, n)
+	for i := range pd.Pix {
+		pd.Pix[i] = color.RGBA{R: pix[4*i], G: pix[4*i+1], B: pix[4*i+2], A: pix[4*i+3]}
+	}
+	return &Atlas{Picture: pd, Frames: frames}, nil
+}
+
+func decodeTriangles(content []byte) (*pixel.TrianglesData, error) {
+	d := decoder{b: content}
+	n := int(d.uint32())
+	if d.err != nil || len(d.b) != 9*8*n {
+		return nil, fmt.Errorf("invalid triangles")
+	}
+	td := pixel.MakeTrianglesData(n)
+	for i := range *td {
+		v := &(*td)[i]
+		v.Position = pixel.V(d.float64(), d.float64())
+		v.Color = pixel.RGBA{R: d.float64(), G: d.float64(), B: d.float64(), A: d.float64()}
+		v.Picture = pixel.V(d.float64(), d.float64())
+		v.Intensity = d.float64()
+	}
+	return td, nil
+}
+
+// decoder reads little-endian values from a byte slice. After the first error, it returns zero
+// values.
+type decoder struct {
+	b   []byte
+	err error
+}
+
+func (d *decoder) bytes(n int) []byte {
+	if d.err != nil || n < 0 || n > len(d.b) {
+		d.err = io.ErrUnexpectedEOF
+		return make([]byte, 0)
+	}
+	p := d.b[:n]
+	d.b = d.b[n:]
+	return p
+}
+
+func (d *decoder) byte() byte {
+	if p := d.bytes(1); len(p) == 1 {
+		return p[0]
+	}
+	return 0
+}
+
+func (d *decoder) uint32() uint32 {
+	if p := d.bytes(4); len(p) == 4 {
+		return binary.LittleEndian.Uint32(p)
+	}
+	return 0
+}
+
+func (d *decoder) int64() int64 {
+	if p := d.bytes(8); len(p) == 8 {
+		return int64(binary.LittleEndian.Uint64(p))
+	}
+	return 0
+}
+
+func (d *decoder) float64() float64 {
+	return math.Float64frombits(uint64(d.int64()))
+}
+
+func (d *decoder) string() string {
+	var n int
+	if p := d.bytes(2); len(p) == 2 {
+		n = int(binary.LittleEndian.Uint16(p))
+	}
+	return string(d.bytes(n))
+}
+
+func (d *decoder) rect() pixel.Rect {
+	return pixel.R(d.float64(), d.float64(), d.float64(), d.float64())
+}
@@ -0,0 +1,172 @@
+package bundle
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/faiface/pixel"
+)
+
+// Writer writes a bundle. Add all the entries and Close the Writer to finish the bundle.
+//
+// The first error stops the Writer, all the following calls return it.
+type Writer struct {
+	// Compression is the compression of the following entries, NoCompression by default.
+	Compression Compression
+
+	w     io.Writer
+	off   int64
+	index []Info
+	offs  []int64
+	names map[string]bool
+	err   error
+}
+
+// NewWriter creates a Writer writing a bundle to w. The bundle is only complete after Close.
+func NewWriter(w io.Writer) *Writer {
+	bw := &Writer{w: w, names: make(map[string]bool)}
+	var header [headerSize]byte
+	copy(header[:], magic)
+	binary.LittleEndian.PutUint32(header[len(magic):], version)
+	bw.write(header[:])
+	return bw
+}
+
+func (bw *Writer) write(p []byte) {
+	if bw.err != nil {
+		return
+	}
+	n, err := bw.w.Write(p)
+	bw.off += int64(n)
+	bw.err = err
+}
+
+// add compresses and writes the content of an entry.
+func (bw *Writer) add(name string, kind Kind, content []byte) error {
+	if bw.err != nil {
+		return bw.err
+	}
+	if name == "" || len(name) > math.MaxUint16 {
+		return fmt.Errorf("bundle: invalid entry name %q", name)
+	}
+	if bw.names[name] {
+		return fmt.Errorf("bundle: duplicate entry %q", name)
+	}
+
+	stored := content
+	switch bw.Compression {
+	case NoCompression:
+	case Deflate:
+		var buf bytes.Buffer
+		fw, _ := flate.NewWriter(&buf, flate.BestCompression)
+		fw.Write(content)
+		fw.Close()
+		stored = buf.Bytes()
+	default:
+		return fmt.Errorf("bundle: invalid compression %d", bw.Compression)
+	}
+
+	bw.names[name] = true
+	bw.index = append(bw.index, Info{
+		Name:        name,
+		Kind:        kind,
+		Compression: bw.Compression,
+		Size:        int64(len(content)),
+		StoredSize:  int64(len(stored)),
+		Hash:        sha256.Sum256(content),
+	})
+	bw.offs = append(bw.offs, bw.off)
+	bw.write(stored)
+	return bw.err
+}
+
+// AddBlob adds an entry with arbitrary content, see Reader.OpenBlob.
+func (bw *Writer) AddBlob(name string, data []byte) error {
+	return bw.add(name, KindBlob, data)
+}
+
+// AddFont adds a TrueType font file, see Reader.OpenFontFace.
+func (bw *Writer) AddFont(name string, ttf []byte) error {
+	return bw.add(name, KindFont, ttf)
+}
+
+// AddPicture adds a PictureData with optional named frames within it (e.g. the frames of the
+// sprites in a sprite sheet), see Reader.OpenPicture and Reader.OpenAtlas.
+func (bw *Writer) AddPicture(name string, pd *pixel.PictureData, frames map[string]pixel.Rect) error {
+	var buf bytes.Buffer
+	writeRect(&buf, pd.Rect)
+	binary.Write(&buf, binary.LittleEndian, uint32(pd.Stride))
+	binary.Write(&buf, binary.LittleEndian, uint32(len(pd.Pix)))
+
+	frameNames := make([]string, 0, len(frames))
+	for frameName := range frames {
+		frameNames = append(frameNames, frameName)
+	}
+	sort.Strings(frameNames)
+	binary.Write(&buf, binary.LittleEndian, uint32(len(frameNames)))
+	for _, frameName := range frameNames {
+		writeString(&buf, frameName)
+		writeRect(&buf, frames[frameName])
+	}
+
+	for _, c := range pd.Pix {
+		buf.Write([]byte{c.R, c.G, c.B, c.A})
+	}
+	return bw.add(name, KindPicture, buf.Bytes())
+}
+
+// AddTriangles adds a TrianglesData, e.g. a pre-triangulated mesh, see Reader.OpenTriangles.
+func (bw *Writer) AddTriangles(name string, td *pixel.TrianglesData) error {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint32(td.Len()))
+	for _, v := range *td {
+		binary.Write(&buf, binary.LittleEndian, [...]float64{
+			v.Position.X, v.Position.Y,
+			v.Color.R, v.Color.G, v.Color.B, v.Color.A,
+			v.Picture.X, v.Picture.Y,
+			v.Intensity,
+		})
+	}
+	return bw.add(name, KindTriangles, buf.Bytes())
+}
+
+// Close writes the index of the bundle. It doesn't close the underlying io.Writer.
+func (bw *Writer) Close() error {
+	if bw.err != nil {
+		return bw.err
+	}
+
+	indexOff := bw.off
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint32(len(bw.index)))
+	for i, info := range bw.index {
+		writeString(&buf, info.Name)
+		buf.Write([]byte{byte(info.Kind), byte(info.Compression)})
+		binary.Write(&buf, binary.LittleEndian, [...]int64{bw.offs[i], info.StoredSize, info.Size})
+		buf.Write(info.Hash[:])
+	}
+	binary.Write(&buf, binary.LittleEndian, indexOff)
+	buf.WriteString(magic)
+	bw.write(buf.Bytes())
+
+	if bw.err == nil {
+		bw.err = fmt.Errorf("bundle: writer closed")
+		return nil
+	}
+	return bw.err
+}
+
+func writeString(buf *bytes.Buffer, s string) {
+	binary.Write(buf, binary.LittleEndian, uint16(len(s)))
+	buf.WriteString(s)
+}
+
+func writeRect(buf *bytes.Buffer, r pixel.Rect) {
+	binary.Write(buf, binary.LittleEndian, [...]float64{r.Min.X, r.Min.Y, r.Max.X, r.Max.Y})
+}
@@ -0,0 +1,120 @@
+package pixel
+
+import (
+	"fmt"
+	"image/color"
+)
+
+// CountingTarget is a BasicTarget which doesn't draw anything, but counts the calls made to it and
+// records the state of the last draw, for testing the drawing code without a window:
+//
+//   target := pixel.NewCountingTarget()
+//   widget.Draw(target)
+//   if target.Draws != 3 || target.LastDraw.ColorMask != tint {
+//       t.Errorf(...)
+//   }
+//
+// The counters and the fields may be reset or changed freely between the draws.
+type CountingTarget struct {
+	// TrianglesMade and PicturesMade count the calls of MakeTriangles and MakePicture.
+	TrianglesMade int
+	PicturesMade  int
+
+	// Draws counts the draws of the made TargetTriangles, with or without a TargetPicture.
+	// PictureDraws counts only the draws with a TargetPicture.
+	Draws        int
+	PictureDraws int
+
+	// Vertices counts the vertices of all the draws.
+	Vertices int
+
+	// Matrix and ColorMask are the last values set by SetMatrix and SetColorMask.
+	Matrix    Matrix
+	ColorMask RGBA
+
+	// LastDraw is the state of the last draw.
+	LastDraw CountedDraw
+}
+
+// CountedDraw is the state of a draw onto a CountingTarget.
+type CountedDraw struct {
+	Matrix    Matrix
+	ColorMask RGBA
+
+	// Triangles are a copy of the drawn triangles, as of the last MakeTriangles or Update.
+	Triangles *TrianglesData
+
+	// Picture is the Picture passed to MakePicture, or nil for a draw without a Picture.
+	Picture Picture
+}
+
+var _ BasicTarget = (*CountingTarget)(nil)
+
+// NewCountingTarget creates a CountingTarget with zero counters, the identity Matrix and no
+// color mask.
+func NewCountingTarget() *CountingTarget {
+	return &CountingTarget{Matrix: IM, ColorMask: Alpha(1)}
+}
+
+// MakeTriangles counts the call and returns a copy of the Triangles, which counts its draws.
+func (ct *CountingTarget) MakeTriangles(t Triangles) TargetTriangles {
+	ct.TrianglesMade++
+	td := MakeTrianglesData(t.Len())
+	td.Update(t)
+	return &countingTriangles{TrianglesData: td, ct: ct}
+}
+
+// MakePicture counts the call and returns a TargetPicture, which counts its draws.
+func (ct *CountingTarget) MakePicture(p Picture) TargetPicture {
+	ct.PicturesMade++
+	return &countingPicture{Picture: p, ct: ct}
+}
+
+// SetMatrix records the Matrix.
+func (ct *CountingTarget) SetMatrix(m Matrix) {
+	ct.Matrix = m
+}
+
+// SetColorMask records the color mask. Nil means no mask, which is recorded as white.
+func (ct *CountingTarget) SetColorMask(c color.Color) {
+	if c == nil {
+		c = Alpha(1)
+	}
+	ct.ColorMask = ToRGBA(c)
+}
+
+func (ct *CountingTarget) draw(td *TrianglesData, pic Picture) {
+	ct.Draws++
+	if pic != nil {
+		ct.PictureDraws++
+	}
+	ct.Vertices += td.Len()
+	ct.LastDraw = CountedDraw{
+		Matrix:    ct.Matrix,
+		ColorMask: ct.ColorMask,
+		Triangles: td,
+		Picture:   pic,
+	}
+}
+
+type countingTriangles struct {
+	*TrianglesData
+	ct *CountingTarget
+}
+
+func (ct *countingTriangles) Draw() {
+	ct.ct.draw(ct.TrianglesData, nil)
+}
+
+type countingPicture struct {
+	Picture
+	ct *CountingTarget
+}
+
+func (cp *countingPicture) Draw(t TargetTriangles) {
+	tri, ok := t.(*countingTriangles)
+	if !ok {
+		panic(fmt.Errorf("(%T).Draw: TargetTriangles %T not made by a CountingTarget", cp, t))
+	}
+	cp.ct.draw(tri.TrianglesData, cp.Picture)
+}
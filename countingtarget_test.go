@@ -0,0 +1,36 @@
+package pixel_test
+
+import (
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+func TestCountingTarget(t *testing.T) {
+	pic := pixel.MakePictureData(pixel.R(0, 0, 8, 8))
+	sprite := pixel.NewSprite(pic, pic.Bounds())
+	target := pixel.NewCountingTarget()
+
+	tint := pixel.RGB(1, 0.5, 0)
+	target.SetColorMask(tint)
+	for i := 0; i < 3; i++ {
+		sprite.Draw(target, pixel.IM.Moved(pixel.V(float64(i), 0)))
+	}
+	target.SetColorMask(nil)
+
+	if target.TrianglesMade != 1 || target.PicturesMade != 1 {
+		t.Errorf("made %d Triangles and %d Pictures, want 1 and 1", target.TrianglesMade, target.PicturesMade)
+	}
+	if target.Draws != 3 || target.PictureDraws != 3 || target.Vertices != 18 {
+		t.Errorf("got %d draws, %d with a Picture, %d vertices, want 3, 3, 18", target.Draws, target.PictureDraws, target.Vertices)
+	}
+	if target.LastDraw.ColorMask != tint || target.LastDraw.Picture != pixel.Picture(pic) {
+		t.Errorf("last draw with mask %v and Picture %v, want %v and %v", target.LastDraw.ColorMask, target.LastDraw.Picture, tint, pic)
+	}
+	if target.ColorMask != pixel.Alpha(1) {
+		t.Errorf("color mask %v after reset, want white", target.ColorMask)
+	}
+	if pos := target.LastDraw.Triangles.Position(0); pos != pixel.V(-2, -4) {
+		t.Errorf("last drawn vertex at %v, want %v", pos, pixel.V(-2, -4))
+	}
+}
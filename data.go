@@ -117,6 +117,30 @@ func (td *TrianglesData) Picture(i int) (pic Vec, intensity float64) {
 	return (*td)[i].Picture, (*td)[i].Intensity
 }
 
+// FadeRadial fades the vertices out by their distance from the center, e.g. for vignettes or the
+// edges of a fog of war. The vertices within innerRadius are left untouched, the vertices at
+// outerRadius or farther become fully transparent and the ones between fade linearly. The colors
+// are alpha-premultiplied, so all the components of a color are scaled, not just the alpha.
+//
+// The fading is per vertex, so large triangles crossing the radii fade only roughly, subdivide
+// them for a smooth fade. FadeRadial panics if outerRadius is smaller than innerRadius.
+func (td *TrianglesData) FadeRadial(center Vec, innerRadius, outerRadius float64) {
+	if outerRadius < innerRadius {
+		panic(fmt.Errorf("(%T).FadeRadial: outer radius %v smaller than inner radius %v", td, outerRadius, innerRadius))
+	}
+	for i := range *td {
+		d := center.To((*td)[i].Position).Len()
+		switch {
+		case d <= innerRadius:
+			continue
+		case d >= outerRadius:
+			(*td)[i].Color = Alpha(0)
+		default:
+			(*td)[i].Color = (*td)[i].Color.Scaled((outerRadius - d) / (outerRadius - innerRadius))
+		}
+	}
+}
+
 // PictureData specifies an in-memory rectangular area of pixels and implements Picture and
 // PictureColor.
 //
@@ -163,8 +187,13 @@ func verticalFlip(rgba *image.RGBA) {
 
 // PictureDataFromImage converts an image.Image into PictureData.
 //
+// The colors are converted according to the image's color model. Images with straight alpha (such
+// as image.NRGBA, which is what the PNG decoder produces) get premultiplied, while images with
+// alpha-premultiplied colors (such as image.RGBA) are copied as they are.
+//
 // The resulting PictureData's Bounds will be the equivalent of the supplied image.Image's Bounds.
 func PictureDataFromImage(img image.Image) *PictureData {
+	// image.RGBA is alpha-premultiplied, so this premultiplies the colors of straight alpha images
 	rgba := image.NewRGBA(img.Bounds())
 	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
 
@@ -187,6 +216,58 @@ func PictureDataFromImage(img image.Image) *PictureData {
 	return pd
 }
 
+// PictureDataFromRGBA converts an image.RGBA into PictureData. It's faster than
+// PictureDataFromImage and saves its intermediate copy of the image, because the bytes of the
+// image.RGBA are taken as they are, without converting the colors through the color.Color
+// interface. The colors are taken as alpha-premultiplied.
+//
+// The pixels are copied, the PictureData doesn't reference the pixels of the image.RGBA. That's not
+// possible without flipping the image.RGBA upside down, because PictureData stores the bottom row
+// first, while image.RGBA stores the top row first. The image.RGBA is left untouched.
+func PictureDataFromRGBA(img *image.RGBA) *PictureData {
+	bounds := img.Bounds()
+	pd := MakePictureData(R(
+		float64(bounds.Min.X),
+		float64(bounds.Min.Y),
+		float64(bounds.Max.X),
+		float64(bounds.Max.Y),
+	))
+
+	w := bounds.Dx()
+	for y := 0; y < bounds.Dy(); y++ {
+		row := img.Pix[img.PixOffset(bounds.Min.X, bounds.Max.Y-1-y):][:w*4]
+		dst := pd.Pix[y*pd.Stride:][:w]
+		for i := range dst {
+			dst[i] = color.RGBA{row[4*i], row[4*i+1], row[4*i+2], row[4*i+3]}
+		}
+	}
+	return pd
+}
+
+// PictureDataFromImagePremultiplied converts an image.Image with straight (not premultiplied)
+// alpha into PictureData, premultiplying the colors during the conversion.
+//
+// Unlike PictureDataFromImage, it treats the channels of an image.RGBA as straight alpha too. Use
+// it when the pixels of an image.RGBA were filled with straight alpha data (e.g. copied from a raw
+// RGBA buffer), which would otherwise cause bright halos around semi-transparent edges. For other
+// images, the result is the same as with PictureDataFromImage.
+func PictureDataFromImagePremultiplied(img image.Image) *PictureData {
+	bounds := img.Bounds()
+	straight := image.NewNRGBA(bounds)
+	if rgba, ok := img.(*image.RGBA); ok {
+		// reinterpret the channels as straight alpha
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			copy(
+				straight.Pix[straight.PixOffset(bounds.Min.X, y):][:bounds.Dx()*4],
+				rgba.Pix[rgba.PixOffset(bounds.Min.X, y):],
+			)
+		}
+	} else {
+		draw.Draw(straight, bounds, img, bounds.Min, draw.Src)
+	}
+	return PictureDataFromImage(straight)
+}
+
 // PictureDataFromPicture converts an arbitrary Picture into PictureData (the conversion may be
 // lossy, because PictureData works with unit-sized pixels).
 //
@@ -257,11 +338,94 @@ func (pd *PictureData) Index(at Vec) int {
 	return y*pd.Stride + x
 }
 
+// UV returns the normalized texture coordinates of the frame, as used by texture samplers.
+//
+// The texture of a PictureData covers the whole pixels of its Bounds, so (0, 0) is the bottom-left
+// corner of the pixel containing Bounds().Min and (1, 1) is the top-right corner of the pixel
+// containing Bounds().Max. This is useful when building textured geometry for custom shaders by
+// hand:
+//
+//   uv := sheet.UV(pixel.R(32, 0, 48, 16))
+//
+// An empty PictureData has no pixels to map onto, UV returns the zero Rect then.
+func (pd *PictureData) UV(frame Rect) Rect {
+	if pd.Stride == 0 {
+		return Rect{}
+	}
+	origin := pd.Rect.Min.Map(math.Floor)
+	size := V(float64(pd.Stride), float64(len(pd.Pix)/pd.Stride))
+	return Rect{
+		Min: frame.Min.Sub(origin).ScaledXY(V(1/size.X, 1/size.Y)),
+		Max: frame.Max.Sub(origin).ScaledXY(V(1/size.X, 1/size.Y)),
+	}
+}
+
 // Bounds returns the bounds of this PictureData.
 func (pd *PictureData) Bounds() Rect {
 	return pd.Rect
 }
 
+// DrawOver composites src over the PictureData on the CPU, with the bottom-left corner of the
+// Bounds of src placed at the position at (rounded to whole pixels), e.g. to combine a base sprite
+// with overlays at load time:
+//
+//   body.DrawOver(helmet, pixel.V(4, 12))
+//
+// The pixels of src outside of the PictureData are clipped, so drawing an empty src or onto an
+// empty PictureData does nothing. Both PictureDatas are alpha-premultiplied, so the colors are
+// composited by the Porter-Duff over operator.
+func (pd *PictureData) DrawOver(src *PictureData, at Vec) {
+	if src.Stride == 0 || pd.Stride == 0 {
+		return // nothing to draw or nothing to draw onto
+	}
+
+	// position of the first pixel of src among the pixels of pd
+	origin := pd.Rect.Min.Map(math.Floor)
+	offX := int(math.Floor(at.X+0.5) - origin.X)
+	offY := int(math.Floor(at.Y+0.5) - origin.Y)
+
+	// the range of the pixels of src within pd
+	x0, x1 := 0, src.Stride
+	y0, y1 := 0, len(src.Pix)/src.Stride
+	if offX < 0 {
+		x0 = -offX
+	}
+	if offY < 0 {
+		y0 = -offY
+	}
+	if w := pd.Stride - offX; x1 > w {
+		x1 = w
+	}
+	if h := len(pd.Pix)/pd.Stride - offY; y1 > h {
+		y1 = h
+	}
+
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			s := src.Pix[y*src.Stride+x]
+			if s.A == 0 {
+				continue
+			}
+			d := &pd.Pix[(y+offY)*pd.Stride+x+offX]
+			inv := 255 - uint32(s.A)
+			d.R = compositeOver(s.R, d.R, inv)
+			d.G = compositeOver(s.G, d.G, inv)
+			d.B = compositeOver(s.B, d.B, inv)
+			d.A = compositeOver(s.A, d.A, inv)
+		}
+	}
+}
+
+// compositeOver returns the premultiplied color component s over d, where inv is 255 minus the
+// alpha of s.
+func compositeOver(s, d uint8, inv uint32) uint8 {
+	v := uint32(s) + (uint32(d)*inv+127)/255
+	if v > 255 {
+		v = 255
+	}
+	return uint8(v)
+}
+
 // Color returns the color located at the given position.
 func (pd *PictureData) Color(at Vec) RGBA {
 	if !pd.Rect.Contains(at) {
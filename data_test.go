@@ -0,0 +1,175 @@
+package pixel_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+func TestPictureDataFromImagePremultiplied(t *testing.T) {
+	straight := color.RGBA{R: 255, G: 64, B: 0, A: 128}
+	premultiplied := color.RGBA{R: 128, G: 32, B: 0, A: 128}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	rgba.Pix[0], rgba.Pix[1], rgba.Pix[2], rgba.Pix[3] = straight.R, straight.G, straight.B, straight.A
+	nrgba := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	nrgba.Set(0, 0, color.NRGBA(straight))
+
+	for _, tt := range []struct {
+		name string
+		pd   *pixel.PictureData
+		want color.RGBA
+	}{
+		{"PictureDataFromImage(RGBA)", pixel.PictureDataFromImage(rgba), straight},
+		{"PictureDataFromImage(NRGBA)", pixel.PictureDataFromImage(nrgba), premultiplied},
+		{"PictureDataFromImagePremultiplied(RGBA)", pixel.PictureDataFromImagePremultiplied(rgba), premultiplied},
+		{"PictureDataFromImagePremultiplied(NRGBA)", pixel.PictureDataFromImagePremultiplied(nrgba), premultiplied},
+	} {
+		if got := tt.pd.Pix[0]; got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPictureDataUV(t *testing.T) {
+	pd := pixel.MakePictureData(pixel.R(-10, 20, 30, 40))
+
+	for _, tt := range []struct {
+		frame, want pixel.Rect
+	}{
+		{pixel.R(-10, 20, 30, 40), pixel.R(0, 0, 1, 1)},
+		{pixel.R(-10, 20, 10, 30), pixel.R(0, 0, 0.5, 0.5)},
+		{pixel.R(0, 25, 30, 40), pixel.R(0.25, 0.25, 1, 1)},
+	} {
+		if got := pd.UV(tt.frame); got != tt.want {
+			t.Errorf("UV(%v) = %v, want %v", tt.frame, got, tt.want)
+		}
+	}
+
+	// an empty PictureData has no texture to map onto
+	empty := pixel.MakePictureData(pixel.R(0, 0, 0, 0))
+	if got := empty.UV(pixel.R(0, 0, 1, 1)); got != (pixel.Rect{}) {
+		t.Errorf("empty UV = %v, want %v", got, pixel.Rect{})
+	}
+}
+
+func TestPictureDataFromRGBA(t *testing.T) {
+	newImage := func() *image.RGBA {
+		img := image.NewRGBA(image.Rect(10, 20, 13, 22))
+		for y := 20; y < 22; y++ {
+			for x := 10; x < 13; x++ {
+				img.Set(x, y, color.RGBA{uint8(x), uint8(y), 0, 255})
+			}
+		}
+		return img
+	}
+
+	want := pixel.PictureDataFromImage(newImage())
+
+	img := newImage()
+	pd := pixel.PictureDataFromRGBA(img)
+	if pd.Rect != want.Rect || pd.Stride != want.Stride || len(pd.Pix) != len(want.Pix) {
+		t.Fatalf("got %v with stride %d, want %v with stride %d", pd.Rect, pd.Stride, want.Rect, want.Stride)
+	}
+	for i := range pd.Pix {
+		if pd.Pix[i] != want.Pix[i] {
+			t.Errorf("pixel %d: got %v, want %v", i, pd.Pix[i], want.Pix[i])
+		}
+	}
+
+	// the image is left untouched and doesn't share the pixels
+	pd.Pix[0] = color.RGBA{1, 2, 3, 4}
+	if got := img.RGBAAt(10, 20); got != (color.RGBA{10, 20, 0, 255}) {
+		t.Errorf("the image was modified, top-left pixel %v", got)
+	}
+
+	// sub-images don't have contiguous rows
+	sub := img.SubImage(image.Rect(11, 20, 13, 22)).(*image.RGBA)
+	pd = pixel.PictureDataFromRGBA(sub)
+	pd.Pix[0] = color.RGBA{}
+	if img.RGBAAt(11, 21) != (color.RGBA{11, 21, 0, 255}) {
+		t.Errorf("the sub-image was modified")
+	}
+	// the y axis points up in PictureData, so the bottom row of the image is at the bottom
+	if got, want := pd.Color(pixel.V(12.5, 21.5)), pixel.ToRGBA(color.RGBA{12, 20, 0, 255}); got != want {
+		t.Errorf("sub-image: got %v at (12.5, 21.5), want %v", got, want)
+	}
+}
+
+func TestTrianglesDataFadeRadial(t *testing.T) {
+	td := pixel.MakeTrianglesData(5)
+	for i, x := range []float64{0, 10, 15, 20, 30} {
+		(*td)[i].Position = pixel.V(100, 50).Add(pixel.V(0, x))
+		(*td)[i].Color = pixel.RGBA{R: 0.5, G: 1, B: 0.25, A: 1}
+	}
+	td.FadeRadial(pixel.V(100, 50), 10, 20)
+
+	// the vertices exactly at the inner and the outer radius are untouched and transparent
+	want := []float64{1, 1, 0.5, 0, 0}
+	for i := range want {
+		if got := td.Color(i); got != (pixel.RGBA{R: 0.5, G: 1, B: 0.25, A: 1}).Scaled(want[i]) {
+			t.Errorf("vertex %d: got %v, want alpha %v", i, got, want[i])
+		}
+	}
+}
+
+func TestPictureDataDrawOver(t *testing.T) {
+	dst := pixel.MakePictureData(pixel.R(0, 0, 4, 4))
+	for i := range dst.Pix {
+		dst.Pix[i] = color.RGBA{R: 0, G: 0, B: 200, A: 200}
+	}
+	src := pixel.MakePictureData(pixel.R(10, 10, 13, 13))
+	for i := range src.Pix {
+		src.Pix[i] = color.RGBA{R: 128, G: 0, B: 0, A: 128} // half transparent red
+	}
+	src.Pix[0] = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+
+	// the bottom-left 3x3 corner of src hangs off the top-right corner of dst, only its
+	// bottom-left 2x2 pixels stay, the opaque pixel is the corner
+	dst.DrawOver(src, pixel.V(2.4, 1.6))
+
+	blended := color.RGBA{R: 128, G: 0, B: 100, A: 228}
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			want := color.RGBA{R: 0, G: 0, B: 200, A: 200}
+			switch {
+			case x == 2 && y == 2:
+				want = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			case x >= 2 && y >= 2:
+				want = blended
+			}
+			if got := dst.Pix[dst.Index(pixel.V(float64(x), float64(y)))]; got != want {
+				t.Errorf("pixel (%d, %d) = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+
+	// fully clipped
+	dst.DrawOver(src, pixel.V(-3, 0))
+	dst.DrawOver(src, pixel.V(4, 4))
+	if got := dst.Pix[0]; got != (color.RGBA{R: 0, G: 0, B: 200, A: 200}) {
+		t.Errorf("pixel (0, 0) = %v after clipped draws, want it unchanged", got)
+	}
+
+	// clipped at the bottom-left corner, the opaque pixel is cut off
+	dst.DrawOver(src, pixel.V(-1, -1))
+	if got := dst.Pix[0]; got != blended {
+		t.Errorf("pixel (0, 0) = %v, want %v", got, blended)
+	}
+	if got := dst.Pix[dst.Index(pixel.V(2, 0))]; got != (color.RGBA{R: 0, G: 0, B: 200, A: 200}) {
+		t.Errorf("pixel (2, 0) = %v, want it unchanged", got)
+	}
+
+	// empty PictureDatas draw nothing
+	before := append([]color.RGBA(nil), dst.Pix...)
+	empty := pixel.MakePictureData(pixel.R(0, 0, 0, 0))
+	dst.DrawOver(empty, pixel.V(1, 1))
+	empty.DrawOver(src, pixel.V(0, 0))
+	for i := range dst.Pix {
+		if dst.Pix[i] != before[i] {
+			t.Errorf("pixel %d = %v after drawing an empty PictureData, want %v", i, dst.Pix[i], before[i])
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package pixel
 
+import "image/color"
+
 // Drawer glues all the fundamental interfaces (Target, Triangles, Picture) into a coherent and the
 // only intended usage pattern.
 //
@@ -14,7 +16,16 @@ package pixel
 // Picture.
 //
 // Whenever you change the Triangles, call Dirty to notify Drawer that Triangles changed. You don't
-// need to notify Drawer about a change of the Picture.
+// need to notify Drawer about a change of the Picture. If only a few vertices changed, call
+// DirtyRange instead, so that only the changed vertices are copied to the Targets on the next Draw.
+//
+// If the Picture implements VolatilePicture, changes of the Picture's content are detected from its
+// Version. Similarly, if a Target implements VolatileTarget, everything made by it is made again
+// when its Generation changes.
+//
+// Drawer keeps the Targets it's drawn to in a map, but the order of the map never affects the
+// result: the triangles drawn onto each Target depend only on the Triangles, the Picture and the
+// Dirty and DirtyRange calls, not on the order in which the Targets are drawn to.
 //
 // Note, that Drawer caches the results of MakePicture from Targets it's drawn to for each Picture
 // it's set to. What it means is that using a Drawer with an unbounded number of Pictures leads to a
@@ -29,9 +40,14 @@ type Drawer struct {
 }
 
 type drawerTarget struct {
-	tris  TargetTriangles
-	pics  map[Picture]TargetPicture
-	clean bool
+	tris     TargetTriangles
+	pics     map[Picture]TargetPicture
+	versions map[Picture]uint64
+	gen      uint64
+	clean    bool
+
+	// range of vertices changed since the last Draw, empty if dirtyI >= dirtyJ
+	dirtyI, dirtyJ int
 }
 
 func (d *Drawer) lazyInit() {
@@ -51,6 +67,35 @@ func (d *Drawer) Dirty() {
 	}
 }
 
+// DirtyRange marks the vertices in range [i, j) of the Triangles of this Drawer as changed. On the
+// next Draw, only the changed vertices are updated, which is much cheaper than Dirty if the
+// Triangles are large and only a small part of them changed.
+//
+// Calling DirtyRange multiple times between Draws marks the smallest range covering all the
+// ranges. If the len of the Triangles changed, everything is updated as if Dirty was called.
+func (d *Drawer) DirtyRange(i, j int) {
+	d.lazyInit()
+
+	if i >= j {
+		return
+	}
+	for _, t := range d.targets {
+		if !t.clean {
+			continue
+		}
+		if t.dirtyI >= t.dirtyJ {
+			t.dirtyI, t.dirtyJ = i, j
+			continue
+		}
+		if i < t.dirtyI {
+			t.dirtyI = i
+		}
+		if j > t.dirtyJ {
+			t.dirtyJ = j
+		}
+	}
+}
+
 // Draw efficiently draws Triangles with Picture onto the provided Target.
 //
 // If Triangles is nil, nothing will be drawn. If Picture is nil, Triangles will be drawn without a
@@ -65,21 +110,42 @@ func (d *Drawer) Draw(t Target) {
 	dt := d.targets[t]
 	if dt == nil {
 		dt = &drawerTarget{
-			pics: make(map[Picture]TargetPicture),
+			pics:     make(map[Picture]TargetPicture),
+			versions: make(map[Picture]uint64),
 		}
 		d.targets[t] = dt
 	}
 
+	if vt, ok := t.(VolatileTarget); ok && vt.Generation() != dt.gen {
+		dt.tris = nil
+		dt.pics = make(map[Picture]TargetPicture)
+		dt.versions = make(map[Picture]uint64)
+		dt.gen = vt.Generation()
+	}
+
 	if dt.tris == nil {
 		dt.tris = t.MakeTriangles(d.Triangles)
 		dt.clean = true
 	}
 
+	if dt.dirtyI < dt.dirtyJ && dt.tris.Len() != d.Triangles.Len() {
+		dt.clean = false
+	}
+
 	if !dt.clean {
 		dt.tris.SetLen(d.Triangles.Len())
 		dt.tris.Update(d.Triangles)
 		dt.clean = true
+	} else if dt.dirtyI < dt.dirtyJ {
+		i, j := dt.dirtyI, dt.dirtyJ
+		if j > d.Triangles.Len() {
+			j = d.Triangles.Len()
+		}
+		if i < j {
+			dt.tris.Slice(i, j).Update(d.Triangles.Slice(i, j))
+		}
 	}
+	dt.dirtyI, dt.dirtyJ = 0, 0
 
 	if d.Picture == nil {
 		dt.tris.Draw()
@@ -87,10 +153,46 @@ func (d *Drawer) Draw(t Target) {
 	}
 
 	pic := dt.pics[d.Picture]
+	if vp, ok := d.Picture.(VolatilePicture); ok && pic != nil && vp.Version() != dt.versions[d.Picture] {
+		if up, ok := pic.(UpdatableTargetPicture); ok && up.Update(d.Picture) {
+			dt.versions[d.Picture] = vp.Version()
+		} else {
+			pic = nil
+		}
+	}
 	if pic == nil {
 		pic = t.MakePicture(d.Picture)
 		dt.pics[d.Picture] = pic
+		if vp, ok := d.Picture.(VolatilePicture); ok {
+			dt.versions[d.Picture] = vp.Version()
+		}
 	}
 
 	pic.Draw(dt.tris)
 }
+
+// DrawTriangles draws the TrianglesData with the Picture onto the Target in one call, e.g. for
+// one-off custom geometry. The positions are transformed by the Matrix and the colors multiplied
+// by the mask, the TrianglesData itself is left unchanged. If the mask is nil, a fully opaque white
+// mask is used, which causes no effect. If the Picture is nil, the triangles are drawn without a
+// Picture.
+//
+// DrawTriangles copies the triangles and makes them and the Picture in the Target again on every
+// call. For drawing the same triangles repeatedly, e.g. every frame, use a Drawer or a Batch, which
+// keep them in the Target between the draws.
+func DrawTriangles(t Target, td *TrianglesData, pic Picture, matrix Matrix, mask color.Color) {
+	if mask == nil {
+		mask = Alpha(1)
+	}
+	rgba := ToRGBA(mask)
+
+	tri := MakeTrianglesData(len(*td))
+	for i, v := range *td {
+		v.Position = matrix.Project(v.Position)
+		v.Color = v.Color.Mul(rgba)
+		(*tri)[i] = v
+	}
+
+	d := Drawer{Triangles: tri, Picture: pic}
+	d.Draw(t)
+}
@@ -16,3 +16,228 @@ func BenchmarkSpriteDrawBatch(b *testing.B) {
 		sprite.Draw(batch, pixel.IM)
 	}
 }
+
+// nopTarget is a Target that keeps a copy of the last made Triangles, but doesn't draw anything.
+type nopTarget struct {
+	tris *pixel.TrianglesData
+	pics int
+}
+
+func (nt *nopTarget) MakeTriangles(t pixel.Triangles) pixel.TargetTriangles {
+	nt.tris = t.Copy().(*pixel.TrianglesData)
+	return nopTriangles{nt.tris}
+}
+
+func (nt *nopTarget) MakePicture(p pixel.Picture) pixel.TargetPicture {
+	nt.pics++
+	return nopPicture{p}
+}
+
+type nopTriangles struct {
+	*pixel.TrianglesData
+}
+
+func (nopTriangles) Draw() {}
+
+type nopPicture struct {
+	pixel.Picture
+}
+
+func (nopPicture) Draw(pixel.TargetTriangles) {}
+
+func TestDrawerDirtyRange(t *testing.T) {
+	tri := pixel.MakeTrianglesData(6)
+	target := &nopTarget{}
+	d := pixel.Drawer{Triangles: tri}
+	d.Draw(target)
+
+	for i := range *tri {
+		(*tri)[i].Position = pixel.V(float64(i), 1)
+	}
+	d.DirtyRange(2, 3)
+	d.DirtyRange(4, 5)
+	d.Draw(target)
+
+	for i, v := range *target.tris {
+		want := pixel.ZV
+		if i >= 2 && i < 5 {
+			want = pixel.V(float64(i), 1)
+		}
+		if v.Position != want {
+			t.Errorf("vertex %d at %v, want %v", i, v.Position, want)
+		}
+	}
+
+	// changing the len updates everything
+	tri.SetLen(7)
+	d.DirtyRange(6, 7)
+	d.Draw(target)
+
+	for i, v := range *target.tris {
+		if v.Position != (*tri)[i].Position {
+			t.Errorf("vertex %d at %v, want %v", i, v.Position, (*tri)[i].Position)
+		}
+	}
+}
+
+type volatilePicture struct {
+	*pixel.PictureData
+	version uint64
+}
+
+func (vp *volatilePicture) Version() uint64 {
+	return vp.version
+}
+
+func TestDrawerVolatilePicture(t *testing.T) {
+	pic := &volatilePicture{PictureData: pixel.MakePictureData(pixel.R(0, 0, 4, 4))}
+	target := &nopTarget{}
+	d := pixel.Drawer{Triangles: pixel.MakeTrianglesData(6), Picture: pic}
+
+	d.Draw(target)
+	d.Draw(target)
+	if target.pics != 1 {
+		t.Fatalf("made %d pictures, want 1", target.pics)
+	}
+
+	pic.version++
+	d.Draw(target)
+	d.Draw(target)
+	if target.pics != 2 {
+		t.Fatalf("made %d pictures after a version change, want 2", target.pics)
+	}
+}
+
+// updatingTarget is a Target whose TargetPictures can be updated in place.
+type updatingTarget struct {
+	nopTarget
+	updates int
+}
+
+type updatingPicture struct {
+	nopPicture
+	t *updatingTarget
+}
+
+func (ut *updatingTarget) MakePicture(p pixel.Picture) pixel.TargetPicture {
+	return updatingPicture{ut.nopTarget.MakePicture(p).(nopPicture), ut}
+}
+
+func (up updatingPicture) Update(p pixel.Picture) bool {
+	up.t.updates++
+	return true
+}
+
+func TestDrawerUpdatesPicture(t *testing.T) {
+	pic := &volatilePicture{PictureData: pixel.MakePictureData(pixel.R(0, 0, 4, 4))}
+	target := &updatingTarget{}
+	d := pixel.Drawer{Triangles: pixel.MakeTrianglesData(6), Picture: pic}
+
+	d.Draw(target)
+	pic.version++
+	d.Draw(target)
+	d.Draw(target)
+	if target.pics != 1 || target.updates != 1 {
+		t.Fatalf("made %d pictures and updated %d times, want 1 and 1", target.pics, target.updates)
+	}
+}
+
+type volatileTarget struct {
+	nopTarget
+	gen uint64
+}
+
+func (vt *volatileTarget) Generation() uint64 {
+	return vt.gen
+}
+
+func TestDrawerVolatileTarget(t *testing.T) {
+	tri := pixel.MakeTrianglesData(6)
+	target := &volatileTarget{}
+	d := pixel.Drawer{Triangles: tri, Picture: pixel.MakePictureData(pixel.R(0, 0, 4, 4))}
+	d.Draw(target)
+
+	// a change without Dirty is not visible until the Target loses everything it made
+	(*tri)[0].Position = pixel.V(1, 2)
+	d.Draw(target)
+	if (*target.tris)[0].Position != pixel.ZV {
+		t.Fatalf("vertex updated without Dirty")
+	}
+
+	target.gen++
+	d.Draw(target)
+	if (*target.tris)[0].Position != pixel.V(1, 2) {
+		t.Errorf("triangles not made again after a generation change")
+	}
+	if target.pics != 2 {
+		t.Errorf("made %d pictures after a generation change, want 2", target.pics)
+	}
+}
+
+func BenchmarkDrawerDirtyRange(b *testing.B) {
+	const vertices = 50000 * 3
+	tri := pixel.MakeTrianglesData(vertices)
+	target := &nopTarget{}
+	d := pixel.Drawer{Triangles: tri}
+	d.Draw(target)
+
+	b.Run("Dirty", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			(*tri)[i%vertices].Position = pixel.V(float64(i), 0)
+			d.Dirty()
+			d.Draw(target)
+		}
+	})
+
+	b.Run("DirtyRange1%", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			start := i % (vertices - vertices/100)
+			(*tri)[start].Position = pixel.V(float64(i), 0)
+			d.DirtyRange(start, start+vertices/100)
+			d.Draw(target)
+		}
+	})
+}
+
+func TestDrawTriangles(t *testing.T) {
+	td := &pixel.TrianglesData{
+		{Position: pixel.V(0, 0), Color: pixel.RGB(1, 1, 1), Picture: pixel.V(0, 0), Intensity: 1},
+		{Position: pixel.V(10, 0), Color: pixel.RGB(1, 0, 0), Picture: pixel.V(10, 0), Intensity: 1},
+		{Position: pixel.V(0, 10), Color: pixel.RGB(0, 1, 0), Picture: pixel.V(0, 10), Intensity: 1},
+	}
+	orig := *td.Copy().(*pixel.TrianglesData)
+	pic := pixel.MakePictureData(pixel.R(0, 0, 10, 10))
+	target := &nopTarget{}
+
+	pixel.DrawTriangles(target, td, pic, pixel.IM.Scaled(pixel.ZV, 2).Moved(pixel.V(5, 5)), pixel.RGB(0.5, 0.5, 0.5))
+	if target.pics != 1 {
+		t.Errorf("MakePicture called %d times, want 1", target.pics)
+	}
+	if target.tris == nil || target.tris.Len() != 3 {
+		t.Fatalf("drawn %v, want 3 vertices", target.tris)
+	}
+	for i, want := range []pixel.Vec{pixel.V(5, 5), pixel.V(25, 5), pixel.V(5, 25)} {
+		if got := target.tris.Position(i); got != want {
+			t.Errorf("vertex %d at %v, want %v", i, got, want)
+		}
+		if got, want := target.tris.Color(i), orig[i].Color.Mul(pixel.RGB(0.5, 0.5, 0.5)); got != want {
+			t.Errorf("vertex %d colored %v, want %v", i, got, want)
+		}
+		if got, _ := target.tris.Picture(i); got != orig[i].Picture {
+			t.Errorf("vertex %d shows %v, want %v", i, got, orig[i].Picture)
+		}
+	}
+	for i := range *td {
+		if (*td)[i] != orig[i] {
+			t.Errorf("vertex %d of the TrianglesData changed to %v", i, (*td)[i])
+		}
+	}
+
+	// a nil mask has no effect
+	pixel.DrawTriangles(target, td, nil, pixel.IM, nil)
+	for i := range orig {
+		if got := target.tris.Color(i); got != orig[i].Color {
+			t.Errorf("nil mask: vertex %d colored %v, want %v", i, got, orig[i].Color)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package pixel
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// AtlasEntry is a handle of a Picture inserted into a DynamicAtlas. The frame of the entry may
+// change when the DynamicAtlas is compacted, so always get it from the DynamicAtlas using the
+// handle instead of keeping the frame around.
+type AtlasEntry int
+
+// DynamicAtlas packs Pictures (such as sprites or glyphs loaded on demand) into a single Picture of
+// a fixed size, so that they can be drawn in a single Batch. Pictures can be inserted and removed
+// at any time.
+//
+// Changes of the DynamicAtlas (Insert, Remove and Compact) are pending until Commit, which should
+// be called at a frame boundary, after the Batches using the DynamicAtlas were drawn for the last
+// time in the frame. Until then, the DynamicAtlas keeps showing the content and the frames of the
+// last Commit, so the triangles drawn earlier in the frame (e.g. into a Batch) keep sampling the
+// content they were made for.
+//
+//   win.Update()
+//   atlas.Commit()
+//
+// Removing entries leaves holes in the DynamicAtlas. When an insertion doesn't fit, but the holes
+// take more than the FragmentationThreshold of the DynamicAtlas, it's compacted (all entries are
+// repacked) and the insertion is tried again. Compaction changes the frames of the entries, use
+// SetCompactCallback to get notified on the following Commit and update the frames of your Sprites:
+//
+//   atlas.SetCompactCallback(func() {
+//       for _, u := range units {
+//           u.sprite.Set(atlas, atlas.Frame(u.entry))
+//       }
+//   })
+//
+// DynamicAtlas implements VolatilePicture, so every Commit is picked up by Drawers (and thus
+// Sprites and Batches) on their next Draw. The whole Picture gets uploaded to the video memory
+// after each Commit with changes though, so don't commit more often than once per frame.
+type DynamicAtlas struct {
+	// FragmentationThreshold is the fraction of the DynamicAtlas's area which must be wasted by
+	// the holes after removed entries for an automatic compaction to happen. The default is 0.25.
+	FragmentationThreshold float64
+
+	pd      *PictureData // committed, never modified
+	work    *PictureData // pending changes, nil if none
+	entries map[AtlasEntry]*atlasEntry
+	shelves []atlasShelf
+	next    AtlasEntry
+	version uint64
+	mem     *memoryAccount
+
+	compacted       bool // since the last Commit
+	compactCallback func()
+}
+
+type atlasEntry struct {
+	frame     Rect         // in the pending content
+	committed Rect         // in the committed content
+	isNew     bool         // not committed yet
+	pix       *PictureData // for repacking
+}
+
+// atlasShelf is a horizontal strip of the DynamicAtlas. The entries are placed onto the shelves
+// from left to right.
+type atlasShelf struct {
+	y, height, x float64
+}
+
+var _ VolatilePicture = (*DynamicAtlas)(nil)
+
+// NewDynamicAtlas creates an empty DynamicAtlas of the given size in pixels.
+func NewDynamicAtlas(width, height int) *DynamicAtlas {
+	da := &DynamicAtlas{
+		FragmentationThreshold: 0.25,
+		pd:                     MakePictureData(R(0, 0, float64(width), float64(height))),
+		entries:                make(map[AtlasEntry]*atlasEntry),
+	}
+	da.mem = newMemoryAccount("DynamicAtlas", fmt.Sprintf("%dx%d %p", width, height, da))
+	da.updateMemory()
+	return da
+}
+
+// updateMemory updates the MemoryStats of the DynamicAtlas, including the copies of the entries.
+func (da *DynamicAtlas) updateMemory() {
+	bytes := pictureDataBytes(da.pd)
+	if da.work != nil {
+		bytes += pictureDataBytes(da.work)
+	}
+	for _, e := range da.entries {
+		bytes += pictureDataBytes(e.pix)
+	}
+	da.mem.setBytes(int64(bytes))
+}
+
+// SetCompactCallback sets a function called by Commit when the DynamicAtlas was compacted since the
+// previous Commit, whether automatically or manually. Passing nil removes the callback.
+func (da *DynamicAtlas) SetCompactCallback(callback func()) {
+	da.compactCallback = callback
+}
+
+// Insert copies the Picture into the DynamicAtlas and returns the handle of the new entry. The
+// entry shows up with the next Commit. If the Picture doesn't fit even after compacting, ok is
+// false.
+func (da *DynamicAtlas) Insert(pic Picture) (entry AtlasEntry, ok bool) {
+	pd := PictureDataFromPicture(pic)
+	if pd == pic {
+		// keep our own copy for repacking
+		pd = &PictureData{
+			Pix:    append([]color.RGBA(nil), pd.Pix...),
+			Stride: pd.Stride,
+			Rect:   pd.Rect,
+		}
+	}
+	size := pictureDataSize(pd)
+
+	frame, ok := da.place(size)
+	if !ok && da.Fragmentation() > da.FragmentationThreshold {
+		da.Compact()
+		frame, ok = da.place(size)
+	}
+	if !ok {
+		return 0, false
+	}
+
+	da.next++
+	entry = da.next
+	da.entries[entry] = &atlasEntry{frame: frame, isNew: true, pix: pd}
+	da.blit(frame, pd)
+	da.updateMemory()
+	return entry, true
+}
+
+// Remove removes the entry from the DynamicAtlas with the next Commit. The space it occupied is
+// reclaimed only by compacting the DynamicAtlas.
+func (da *DynamicAtlas) Remove(entry AtlasEntry) {
+	e, ok := da.entries[entry]
+	if !ok {
+		panic(fmt.Errorf("(%T).Remove: invalid entry %d", da, entry))
+	}
+	da.clear(e.frame)
+	delete(da.entries, entry)
+	da.updateMemory()
+}
+
+// Frame returns the frame of the entry within the committed content of the DynamicAtlas. For an
+// entry inserted since the last Commit, it's the frame the entry will have after the Commit.
+func (da *DynamicAtlas) Frame(entry AtlasEntry) Rect {
+	e, ok := da.entries[entry]
+	if !ok {
+		panic(fmt.Errorf("(%T).Frame: invalid entry %d", da, entry))
+	}
+	if e.isNew {
+		return e.frame
+	}
+	return e.committed
+}
+
+// Fragmentation returns the fraction of the DynamicAtlas's area wasted by the holes after removed
+// entries and unused ends of the shelves.
+func (da *DynamicAtlas) Fragmentation() float64 {
+	allocated, used := 0.0, 0.0
+	for _, s := range da.shelves {
+		allocated += s.x * s.height
+	}
+	for _, e := range da.entries {
+		used += e.frame.Area()
+	}
+	return (allocated - used) / da.pd.Rect.Area()
+}
+
+// Compact repacks all entries of the DynamicAtlas to remove the holes after removed entries. The
+// frames of the entries change with the next Commit, which calls the compact callback.
+//
+// In the rare case the repacked entries don't fit, the DynamicAtlas is left unchanged.
+func (da *DynamicAtlas) Compact() {
+	handles := make([]AtlasEntry, 0, len(da.entries))
+	for h := range da.entries {
+		handles = append(handles, h)
+	}
+	// tallest first packs shelves tightly, the handles make the order deterministic
+	sort.Slice(handles, func(i, j int) bool {
+		hi, hj := da.entries[handles[i]].frame.H(), da.entries[handles[j]].frame.H()
+		if hi != hj {
+			return hi > hj
+		}
+		return handles[i] < handles[j]
+	})
+
+	oldShelves := da.shelves
+	da.shelves = nil
+	frames := make([]Rect, len(handles))
+	for i, h := range handles {
+		frame, ok := da.place(pictureDataSize(da.entries[h].pix))
+		if !ok {
+			da.shelves = oldShelves
+			return
+		}
+		frames[i] = frame
+	}
+
+	work := da.pending()
+	for i := range work.Pix {
+		work.Pix[i] = color.RGBA{}
+	}
+	for i, h := range handles {
+		e := da.entries[h]
+		e.frame = frames[i]
+		da.blit(e.frame, e.pix)
+	}
+	da.compacted = true
+}
+
+// Commit publishes the changes made since the previous Commit: the content, Version and the frames
+// of the entries change and the compact callback is called if the DynamicAtlas was compacted. Call
+// it at a frame boundary, see DynamicAtlas. Commit does nothing if there are no changes.
+func (da *DynamicAtlas) Commit() {
+	if da.work == nil {
+		return
+	}
+	// the Drawers may still hold the old content, so it stays unmodified and the work becomes the
+	// committed content
+	da.pd, da.work = da.work, nil
+	for _, e := range da.entries {
+		e.committed, e.isNew = e.frame, false
+	}
+	da.version++
+	da.updateMemory()
+
+	if da.compacted {
+		da.compacted = false
+		if da.compactCallback != nil {
+			da.compactCallback()
+		}
+	}
+}
+
+// pending returns the PictureData with the pending changes, copying the committed content for the
+// first change since the last Commit.
+func (da *DynamicAtlas) pending() *PictureData {
+	if da.work == nil {
+		da.work = &PictureData{
+			Pix:    append([]color.RGBA(nil), da.pd.Pix...),
+			Stride: da.pd.Stride,
+			Rect:   da.pd.Rect,
+		}
+	}
+	return da.work
+}
+
+// Bounds returns the bounds of the DynamicAtlas.
+func (da *DynamicAtlas) Bounds() Rect {
+	return da.pd.Bounds()
+}
+
+// Color returns the color of the committed content of the DynamicAtlas at the given position.
+func (da *DynamicAtlas) Color(at Vec) RGBA {
+	return da.pd.Color(at)
+}
+
+// Version returns a number which changes with every Commit which changes the content of the
+// DynamicAtlas.
+func (da *DynamicAtlas) Version() uint64 {
+	return da.version
+}
+
+// PictureData returns the committed content of the DynamicAtlas. The returned PictureData stays the
+// same after later changes and Commits, don't modify it.
+func (da *DynamicAtlas) PictureData() *PictureData {
+	return da.pd
+}
+
+// place finds a free place for a rectangle of the given size.
+func (da *DynamicAtlas) place(size Vec) (Rect, bool) {
+	bounds := da.pd.Rect
+	if size.X > bounds.W() || size.Y > bounds.H() {
+		return Rect{}, false
+	}
+
+	best := -1
+	for i, s := range da.shelves {
+		if s.height >= size.Y && bounds.W()-s.x >= size.X {
+			if best < 0 || s.height < da.shelves[best].height {
+				best = i
+			}
+		}
+	}
+	if best < 0 {
+		top := 0.0
+		if len(da.shelves) > 0 {
+			last := da.shelves[len(da.shelves)-1]
+			top = last.y + last.height
+		}
+		if bounds.H()-top < size.Y {
+			return Rect{}, false
+		}
+		da.shelves = append(da.shelves, atlasShelf{y: top, height: size.Y})
+		best = len(da.shelves) - 1
+	}
+
+	s := &da.shelves[best]
+	frame := R(s.x, s.y, s.x+size.X, s.y+size.Y)
+	s.x += size.X
+	return frame, true
+}
+
+// blit copies the pixels of the PictureData into the frame.
+func (da *DynamicAtlas) blit(frame Rect, pd *PictureData) {
+	work := da.pending()
+	w, h := int(frame.W()), int(frame.H())
+	x0, y0 := int(frame.Min.X), int(frame.Min.Y)
+	for y := 0; y < h; y++ {
+		copy(work.Pix[(y0+y)*work.Stride+x0:][:w], pd.Pix[y*pd.Stride:][:w])
+	}
+}
+
+// clear makes the frame transparent.
+func (da *DynamicAtlas) clear(frame Rect) {
+	work := da.pending()
+	w, h := int(frame.W()), int(frame.H())
+	x0, y0 := int(frame.Min.X), int(frame.Min.Y)
+	for y := 0; y < h; y++ {
+		row := work.Pix[(y0+y)*work.Stride+x0:][:w]
+		for i := range row {
+			row[i] = color.RGBA{}
+		}
+	}
+}
+
+// pictureDataSize returns the size of the PictureData in whole pixels.
+func pictureDataSize(pd *PictureData) Vec {
+	if pd.Stride == 0 {
+		return ZV
+	}
+	return V(float64(pd.Stride), math.Ceil(float64(len(pd.Pix))/float64(pd.Stride)))
+}
@@ -0,0 +1,150 @@
+package pixel_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+func solidPicture(w, h int, c color.RGBA) *pixel.PictureData {
+	pd := pixel.MakePictureData(pixel.R(0, 0, float64(w), float64(h)))
+	for i := range pd.Pix {
+		pd.Pix[i] = c
+	}
+	return pd
+}
+
+func TestDynamicAtlasCompact(t *testing.T) {
+	atlas := pixel.NewDynamicAtlas(64, 64)
+	compacted := 0
+	atlas.SetCompactCallback(func() { compacted++ })
+
+	// fill the atlas with 16 tiles
+	var entries []pixel.AtlasEntry
+	for i := 0; i < 16; i++ {
+		e, ok := atlas.Insert(solidPicture(16, 16, color.RGBA{uint8(i), 0, 0, 255}))
+		if !ok {
+			t.Fatalf("tile %d doesn't fit", i)
+		}
+		entries = append(entries, e)
+	}
+	atlas.Commit()
+	if _, ok := atlas.Insert(solidPicture(16, 16, color.RGBA{})); ok {
+		t.Fatalf("tile fits into a full atlas")
+	}
+
+	// remove every other tile, a large picture fits only after compaction
+	version := atlas.Version()
+	for i := 0; i < 16; i += 2 {
+		atlas.Remove(entries[i])
+	}
+	if atlas.Version() != version {
+		t.Errorf("Version() changed before Commit")
+	}
+	big, ok := atlas.Insert(solidPicture(64, 32, color.RGBA{0, 255, 0, 255}))
+	if !ok {
+		t.Fatalf("picture doesn't fit after compaction, fragmentation %v", atlas.Fragmentation())
+	}
+	if compacted != 0 {
+		t.Errorf("compacted %d times before Commit, want 0", compacted)
+	}
+	atlas.Commit()
+	if atlas.Version() == version {
+		t.Errorf("Version() didn't change after Commit")
+	}
+	if compacted != 1 {
+		t.Errorf("compacted %d times, want 1", compacted)
+	}
+
+	// the remaining tiles moved, but kept their content
+	for i := 1; i < 16; i += 2 {
+		frame := atlas.Frame(entries[i])
+		if got := atlas.Color(frame.Center()); got != pixel.ToRGBA(color.RGBA{uint8(i), 0, 0, 255}) {
+			t.Errorf("tile %d has color %v at %v", i, got, frame)
+		}
+		if frame.Intersect(atlas.Frame(big)).Area() != 0 {
+			t.Errorf("tile %d at %v overlaps the big picture at %v", i, frame, atlas.Frame(big))
+		}
+	}
+}
+
+func TestDynamicAtlasCopiesPicture(t *testing.T) {
+	atlas := pixel.NewDynamicAtlas(16, 16)
+	pd := pixel.PictureDataFromImage(image.NewRGBA(image.Rect(0, 0, 4, 4)))
+	e, _ := atlas.Insert(pd)
+	pd.Pix[0] = color.RGBA{255, 255, 255, 255}
+
+	atlas.Compact()
+	atlas.Commit()
+	if got := atlas.Color(atlas.Frame(e).Min); got != pixel.Alpha(0) {
+		t.Errorf("atlas changed with the inserted picture, color %v", got)
+	}
+}
+
+// snapshotTarget is a Target that keeps a copy of the content of the last made Picture.
+type snapshotTarget struct {
+	nopTarget
+	pic *pixel.PictureData
+}
+
+func (st *snapshotTarget) MakePicture(p pixel.Picture) pixel.TargetPicture {
+	pd := pixel.PictureDataFromPicture(p)
+	st.pic = &pixel.PictureData{
+		Pix:    append([]color.RGBA(nil), pd.Pix...),
+		Stride: pd.Stride,
+		Rect:   pd.Rect,
+	}
+	return st.nopTarget.MakePicture(p)
+}
+
+func TestDynamicAtlasCommit(t *testing.T) {
+	red, green := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 255, 0, 255}
+	atlas := pixel.NewDynamicAtlas(32, 16)
+	a, _ := atlas.Insert(solidPicture(16, 16, red))
+	b, _ := atlas.Insert(solidPicture(16, 16, green))
+	atlas.Commit()
+
+	sprite := pixel.NewSprite(atlas, atlas.Frame(b))
+	atlas.SetCompactCallback(func() {
+		sprite.Set(atlas, atlas.Frame(b))
+	})
+	batch := pixel.NewBatch(&pixel.TrianglesData{}, atlas)
+	target := &snapshotTarget{}
+
+	// checkBatch draws the batch and checks that its triangles sample the green picture
+	checkBatch := func(when string) {
+		batch.Draw(target)
+		for i := 0; i < target.tris.Len(); i++ {
+			pic, _ := target.tris.Picture(i)
+			// the vertices are at the edges of the frame, sample inside
+			at := pic.Add(atlas.Frame(b).Center().Sub(pic).Unit())
+			if got := pixel.ToRGBA(target.pic.Color(at)); got != pixel.ToRGBA(green) {
+				t.Errorf("%s: vertex %d samples %v at %v", when, i, got, at)
+			}
+		}
+	}
+
+	// draw into the batch, then compact in the middle of the frame
+	sprite.Draw(batch, pixel.IM)
+	frame := atlas.Frame(b)
+	atlas.Remove(a)
+	atlas.Compact()
+	if atlas.Frame(b) != frame {
+		t.Errorf("frame changed to %v before Commit", atlas.Frame(b))
+	}
+	checkBatch("before Commit")
+
+	// the next frame uses the new frames
+	atlas.Commit()
+	if atlas.Frame(b) == frame {
+		t.Errorf("frame %v didn't change after compaction", frame)
+	}
+	batch.Clear()
+	sprite.Draw(batch, pixel.IM)
+	checkBatch("after Commit")
+	if target.pics != 2 {
+		t.Errorf("made %d pictures, want 2", target.pics)
+	}
+}
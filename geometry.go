@@ -1,6 +1,7 @@
 package pixel
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 )
@@ -92,6 +93,21 @@ func (u Vec) Floor() Vec {
 	}
 }
 
+// Snap rounds each component of u to the nearest multiple of the corresponding component of grid,
+// e.g. for snapping to the grid of a level editor. Halfway values are rounded up. A zero
+// component of grid leaves that component of u unchanged.
+//
+//   pixel.V(7.4, 9).Snap(pixel.V(2.5, 0)) // pixel.V(7.5, 9)
+func (u Vec) Snap(grid Vec) Vec {
+	snap := func(x, g float64) float64 {
+		if g == 0 {
+			return x
+		}
+		return math.Floor(x/g+0.5) * g
+	}
+	return Vec{snap(u.X, grid.X), snap(u.Y, grid.Y)}
+}
+
 // To returns the vector from u to v. Equivalent to v.Sub(u).
 func (u Vec) To(v Vec) Vec {
 	return Vec{
@@ -172,6 +188,32 @@ func (u Vec) Map(f func(float64) float64) Vec {
 	}
 }
 
+// vecEpsilon is the tolerance of Vec.Eq and Vec.IsZero.
+const vecEpsilon = 1e-9
+
+// Eq checks whether vectors u and v are equal up to rounding errors.
+//
+// The components are compared with a relative tolerance of 1e-9 (absolute for components smaller
+// than 1), which is enough for errors accumulated by a few arithmetic operations. Use EqEpsilon for
+// a different tolerance.
+func (u Vec) Eq(v Vec) bool {
+	eq := func(a, b float64) bool {
+		return math.Abs(a-b) <= vecEpsilon*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+	}
+	return eq(u.X, v.X) && eq(u.Y, v.Y)
+}
+
+// EqEpsilon checks whether both components of vectors u and v differ by at most eps.
+func (u Vec) EqEpsilon(v Vec, eps float64) bool {
+	return math.Abs(u.X-v.X) <= eps && math.Abs(u.Y-v.Y) <= eps
+}
+
+// IsZero checks whether the vector u is a zero vector up to rounding errors, that is both of its
+// components are at most 1e-9 in absolute value.
+func (u Vec) IsZero() bool {
+	return u.EqEpsilon(ZV, vecEpsilon)
+}
+
 // Lerp returns a linear interpolation between vectors a and b.
 //
 // This function basically returns a point along the line between a and b and t chooses which one.
@@ -287,6 +329,41 @@ func (r Rect) ResizedMin(size Vec) Rect {
 	}
 }
 
+// Inset returns the Rect with all sides moved inwards by amount. Negative amount grows the Rect.
+//
+// If the Rect is too small to be inset by amount, the result collapses to a zero-size Rect at its
+// center. The Rect must be normalized.
+func (r Rect) Inset(amount float64) Rect {
+	return r.Pad(amount, amount, amount, amount)
+}
+
+// Pad returns the Rect with each side moved inwards by its own amount, negative amounts grow the
+// Rect.
+//
+//   content := panel.Pad(8, 8, 8, 24) // leave room for a title bar
+//
+// If the sides would cross, the result collapses to zero width (or height) in the middle between
+// them instead of getting inverted. The Rect must be normalized.
+func (r Rect) Pad(left, bottom, right, top float64) Rect {
+	t := R(r.Min.X+left, r.Min.Y+bottom, r.Max.X-right, r.Max.Y-top)
+	if t.Min.X > t.Max.X {
+		t.Min.X = (t.Min.X + t.Max.X) / 2
+		t.Max.X = t.Min.X
+	}
+	if t.Min.Y > t.Max.Y {
+		t.Min.Y = (t.Min.Y + t.Max.Y) / 2
+		t.Max.Y = t.Min.Y
+	}
+	return t
+}
+
+// Lerp returns a linear interpolation between Rects r and s, Min and Max are interpolated
+// independently with Lerp. If t is 0, r is returned, if t is 1, s is returned. Values of t outside
+// of [0, 1] extrapolate, clamp t with Clamp if that's not wanted.
+func (r Rect) Lerp(s Rect, t float64) Rect {
+	return Rect{Lerp(r.Min, s.Min, t), Lerp(r.Max, s.Max, t)}
+}
+
 // Contains checks whether a vector u is contained within this Rect (including it's borders).
 func (r Rect) Contains(u Vec) bool {
 	return r.Min.X <= u.X && u.X <= r.Max.X && r.Min.Y <= u.Y && u.Y <= r.Max.Y
@@ -318,6 +395,303 @@ func (r Rect) Intersect(s Rect) Rect {
 	return t
 }
 
+// Intersects checks whether the Rects r and s overlap, without computing the intersection. Rects
+// sharing only a border don't overlap. Rects r and s must be normalized.
+//
+// It's the same as checking that r.Intersect(s) is not the zero Rect.
+func (r Rect) Intersects(s Rect) bool {
+	return r.Min.X < s.Max.X && s.Min.X < r.Max.X && r.Min.Y < s.Max.Y && s.Min.Y < r.Max.Y
+}
+
+// Circle is a 2D circle. It is defined by two properties, Center and Radius.
+//
+// The Radius should be non-negative, Norm makes it so.
+type Circle struct {
+	Center Vec
+	Radius float64
+}
+
+// C returns a new Circle with the given center and radius.
+//
+// Note that the returned Circle is not automatically normalized.
+func C(center Vec, radius float64) Circle {
+	return Circle{
+		Center: center,
+		Radius: radius,
+	}
+}
+
+// String returns the string representation of the Circle.
+//
+//   c := pixel.C(pixel.V(10, 20), 5)
+//   c.String()     // returns "Circle(Vec(10, 20), 5)"
+//   fmt.Println(c) // Circle(Vec(10, 20), 5)
+func (c Circle) String() string {
+	return fmt.Sprintf("Circle(%v, %v)", c.Center, c.Radius)
+}
+
+// Norm returns the Circle in normal form, such that the Radius is non-negative.
+func (c Circle) Norm() Circle {
+	return Circle{
+		Center: c.Center,
+		Radius: math.Abs(c.Radius),
+	}
+}
+
+// Area returns the area of the Circle.
+func (c Circle) Area() float64 {
+	return math.Pi * c.Radius * c.Radius
+}
+
+// Moved returns the Circle moved by the given vector delta.
+func (c Circle) Moved(delta Vec) Circle {
+	return Circle{
+		Center: c.Center.Add(delta),
+		Radius: c.Radius,
+	}
+}
+
+// Resized returns the Circle with the given radius and the same center.
+func (c Circle) Resized(radius float64) Circle {
+	return Circle{
+		Center: c.Center,
+		Radius: radius,
+	}
+}
+
+// Contains checks whether a vector u is contained within this Circle (including it's border).
+func (c Circle) Contains(u Vec) bool {
+	return c.Center.To(u).Len() <= c.Radius
+}
+
+// Union returns the minimal Circle which covers both c and d. Circles c and d must be normalized.
+func (c Circle) Union(d Circle) Circle {
+	dist := c.Center.To(d.Center).Len()
+	if dist+d.Radius <= c.Radius {
+		return c
+	}
+	if dist+c.Radius <= d.Radius {
+		return d
+	}
+	radius := (dist + c.Radius + d.Radius) / 2
+	return Circle{
+		Center: c.Center.Add(c.Center.To(d.Center).Scaled((radius - c.Radius) / dist)),
+		Radius: radius,
+	}
+}
+
+// Intersect returns the maximal Circle which is covered by both c and d. Circles c and d must be
+// normalized.
+//
+// If one Circle covers the other (e.g. concentric circles), the smaller one is returned. If c and
+// d don't overlap, this function returns C(ZV, 0).
+func (c Circle) Intersect(d Circle) Circle {
+	dist := c.Center.To(d.Center).Len()
+	if dist >= c.Radius+d.Radius {
+		return Circle{}
+	}
+	if dist+c.Radius <= d.Radius {
+		return c
+	}
+	if dist+d.Radius <= c.Radius {
+		return d
+	}
+	// the overlap spans from dist-d.Radius to c.Radius on the line from c.Center to d.Center
+	return Circle{
+		Center: c.Center.Add(c.Center.To(d.Center).Scaled((c.Radius + dist - d.Radius) / 2 / dist)),
+		Radius: (c.Radius - dist + d.Radius) / 2,
+	}
+}
+
+// IntersectRect returns the minimal translation vector which moves the Circle out of the Rect, or
+// ZV if they don't overlap. Touching counts as not overlapping. The Circle and the Rect must be
+// normalized.
+//
+//   if mtv := ball.IntersectRect(wall); mtv != pixel.ZV {
+//       ball = ball.Moved(mtv)
+//   }
+//
+// If the center of the Circle lies within the Rect, the Circle is moved out through the nearest
+// side of the Rect.
+func (c Circle) IntersectRect(r Rect) Vec {
+	closest := V(Clamp(c.Center.X, r.Min.X, r.Max.X), Clamp(c.Center.Y, r.Min.Y, r.Max.Y))
+	if closest != c.Center {
+		out := closest.To(c.Center)
+		dist := out.Len()
+		if dist >= c.Radius {
+			return ZV
+		}
+		return out.Scaled((c.Radius - dist) / dist)
+	}
+
+	// the center is within the Rect, find the nearest side
+	mtv := V(r.Min.X-c.Center.X-c.Radius, 0)
+	if d := r.Max.X - c.Center.X + c.Radius; d < -mtv.X {
+		mtv = V(d, 0)
+	}
+	if d := r.Min.Y - c.Center.Y - c.Radius; -d < mtv.Len() {
+		mtv = V(0, d)
+	}
+	if d := r.Max.Y - c.Center.Y + c.Radius; d < mtv.Len() {
+		mtv = V(0, d)
+	}
+	if mtv.Len() == 0 {
+		return ZV // a point on the side of the Rect
+	}
+	return mtv
+}
+
+// Line is a 2D line segment between the points A and B.
+type Line struct {
+	A, B Vec
+}
+
+// L returns a new Line between the given points.
+func L(from, to Vec) Line {
+	return Line{
+		A: from,
+		B: to,
+	}
+}
+
+// String returns the string representation of the Line.
+//
+//   l := pixel.L(pixel.V(1, 2), pixel.V(3, 4))
+//   l.String()     // returns "Line(Vec(1, 2), Vec(3, 4))"
+//   fmt.Println(l) // Line(Vec(1, 2), Vec(3, 4))
+func (l Line) String() string {
+	return fmt.Sprintf("Line(%v, %v)", l.A, l.B)
+}
+
+// Len returns the length of the Line.
+func (l Line) Len() float64 {
+	return l.A.To(l.B).Len()
+}
+
+// Moved returns the Line moved (both A and B) by the given vector delta.
+func (l Line) Moved(delta Vec) Line {
+	return Line{
+		A: l.A.Add(delta),
+		B: l.B.Add(delta),
+	}
+}
+
+// Closest returns the point of the Line closest to the point v.
+func (l Line) Closest(v Vec) Vec {
+	ab := l.A.To(l.B)
+	d := ab.Dot(ab)
+	if d == 0 {
+		return l.A
+	}
+	return l.A.Add(ab.Scaled(Clamp(l.A.To(v).Dot(ab)/d, 0, 1)))
+}
+
+// epsilon returns the tolerance of the Line's point tests, relative to its coordinates like the
+// tolerance of Vec.Eq.
+func (l Line) epsilon() float64 {
+	return vecEpsilon * math.Max(1, math.Max(
+		math.Max(math.Abs(l.A.X), math.Abs(l.A.Y)),
+		math.Max(math.Abs(l.B.X), math.Abs(l.B.Y)),
+	))
+}
+
+// Contains checks whether the point v lies on the Line (including its end points), up to rounding
+// errors like Vec.Eq.
+func (l Line) Contains(v Vec) bool {
+	return l.Closest(v).To(v).Len() <= l.epsilon()
+}
+
+// Intersect returns the point where the Line and the other Line cross and true, or false if they
+// don't. Touching counts as crossing, e.g. when an end point lies on the other Line.
+//
+// If the Lines are collinear and overlap, there are infinitely many common points, Intersect
+// returns the one closest to l.A. Parallel Lines which aren't collinear never cross.
+func (l Line) Intersect(other Line) (Vec, bool) {
+	d1, d2 := l.A.To(l.B), other.A.To(other.B)
+	ao := l.A.To(other.A)
+	denom := d1.Cross(d2)
+	eps := math.Max(l.epsilon(), other.epsilon())
+
+	if math.Abs(denom) <= eps*math.Max(1, d1.Len()*d2.Len()) {
+		// parallel, only collinear overlapping Lines have common points
+		var (
+			best  Vec
+			found bool
+		)
+		for _, v := range []Vec{l.A, l.B, other.A, other.B} {
+			if !l.Contains(v) || !other.Contains(v) {
+				continue
+			}
+			if !found || l.A.To(v).Len() < l.A.To(best).Len() {
+				best, found = v, true
+			}
+		}
+		return best, found
+	}
+
+	t := ao.Cross(d2) / denom
+	u := ao.Cross(d1) / denom
+	tolT := eps / math.Max(eps, d1.Len())
+	tolU := eps / math.Max(eps, d2.Len())
+	if t < -tolT || t > 1+tolT || u < -tolU || u > 1+tolU {
+		return ZV, false
+	}
+	return l.A.Add(d1.Scaled(Clamp(t, 0, 1))), true
+}
+
+// IntersectCircle returns the minimal translation vector which moves the Line out of the Circle,
+// or ZV if they don't overlap. Touching counts as not overlapping, like Circle.IntersectRect. The
+// Circle must be normalized.
+//
+// If the Line passes through the center of the Circle, it's moved perpendicular to itself.
+func (l Line) IntersectCircle(c Circle) Vec {
+	out := c.Center.To(l.Closest(c.Center))
+	dist := out.Len()
+	if dist >= c.Radius {
+		return ZV
+	}
+	if dist == 0 {
+		normal := l.A.To(l.B).Normal()
+		if normal == ZV {
+			normal = V(0, 1)
+		}
+		return normal.Unit().Scaled(c.Radius)
+	}
+	return out.Scaled((c.Radius - dist) / dist)
+}
+
+// IntersectRect returns the minimal translation vector which moves the Line out of the Rect, or ZV
+// if they don't overlap. Touching counts as not overlapping, like Circle.IntersectRect. The Rect
+// must be normalized.
+func (l Line) IntersectRect(r Rect) Vec {
+	axes := []Vec{V(1, 0), V(0, 1)}
+	if n := l.A.To(l.B).Normal(); n != ZV {
+		axes = append(axes, n.Unit())
+	}
+	corners := [...]Vec{r.Min, V(r.Max.X, r.Min.Y), r.Max, V(r.Min.X, r.Max.Y)}
+
+	mtv, best := ZV, math.Inf(1)
+	for _, axis := range axes {
+		lmin, lmax := math.Min(l.A.Dot(axis), l.B.Dot(axis)), math.Max(l.A.Dot(axis), l.B.Dot(axis))
+		rmin, rmax := math.Inf(1), math.Inf(-1)
+		for _, c := range corners {
+			rmin, rmax = math.Min(rmin, c.Dot(axis)), math.Max(rmax, c.Dot(axis))
+		}
+		// the Line moves either forward past rmax or backward past rmin
+		forward, backward := rmax-lmin, lmax-rmin
+		if forward <= 0 || backward <= 0 {
+			return ZV // separated along this axis
+		}
+		if forward < best {
+			mtv, best = axis.Scaled(forward), forward
+		}
+		if backward < best {
+			mtv, best = axis.Scaled(-backward), backward
+		}
+	}
+	return mtv
+}
+
 // Matrix is a 2x3 affine matrix that can be used for all kinds of spatial transforms, such
 // as movement, scaling and rotations.
 //
@@ -379,6 +753,20 @@ func (m Matrix) Rotated(around Vec, angle float64) Matrix {
 	return m
 }
 
+// ShearedXY shears everything around a given point. Each point moves horizontally by shear.X times
+// its vertical distance from the point around and vertically by shear.Y times its horizontal
+// distance. For example, this slants text to the right like italics:
+//
+//   pixel.IM.ShearedXY(baseline, pixel.V(0.2, 0))
+//
+// The shear is invertible unless shear.X*shear.Y is 1.
+func (m Matrix) ShearedXY(around Vec, shear Vec) Matrix {
+	m[4], m[5] = m[4]-around.X, m[5]-around.Y
+	m = m.Chained(Matrix{1, shear.Y, shear.X, 1, 0, 0})
+	m[4], m[5] = m[4]+around.X, m[5]+around.Y
+	return m
+}
+
 // Chained adds another Matrix to this one. All tranformations by the next Matrix will be applied
 // after the transformations of this Matrix.
 func (m Matrix) Chained(next Matrix) Matrix {
@@ -399,15 +787,87 @@ func (m Matrix) Project(u Vec) Vec {
 	return Vec{m[0]*u.X + m[2]*u.Y + m[4], m[1]*u.X + m[3]*u.Y + m[5]}
 }
 
+// ProjectSlice projects all vectors in src by the Matrix and stores the results in dst, which must
+// be at least as long as src. The dst and src may be the same slice.
+//
+// The results are exactly the same as calling Project for each vector, but it's faster for large
+// numbers of vectors, such as the vertices of a Batch.
+func (m Matrix) ProjectSlice(dst, src []Vec) {
+	if len(dst) < len(src) {
+		panic(fmt.Errorf("(%T).ProjectSlice: dst shorter than src", m))
+	}
+	dst = dst[:len(src)]
+	a, b, c, d, e, f := m[0], m[1], m[2], m[3], m[4], m[5]
+	for i := range src {
+		x, y := src[i].X, src[i].Y
+		dst[i] = Vec{a*x + c*y + e, b*x + d*y + f}
+	}
+}
+
+// ProjectRect projects all four corners of the Rect by the Matrix and returns their bounding box.
+// Unlike projecting just Min and Max, this gives the correct bounds of a rotated or sheared Rect,
+// e.g. for culling or hit-testing a rotated sprite.
+func (m Matrix) ProjectRect(r Rect) Rect {
+	a := m.Project(r.Min)
+	b := m.Project(Vec{r.Max.X, r.Min.Y})
+	c := m.Project(r.Max)
+	d := m.Project(Vec{r.Min.X, r.Max.Y})
+	return Rect{
+		Min: Vec{math.Min(math.Min(a.X, b.X), math.Min(c.X, d.X)), math.Min(math.Min(a.Y, b.Y), math.Min(c.Y, d.Y))},
+		Max: Vec{math.Max(math.Max(a.X, b.X), math.Max(c.X, d.X)), math.Max(math.Max(a.Y, b.Y), math.Max(c.Y, d.Y))},
+	}
+}
+
+// ProjectTrianglesData projects the positions of all vertices of the TrianglesData by the Matrix in
+// place. The results are exactly the same as calling Project for each position.
+func (m Matrix) ProjectTrianglesData(td *TrianglesData) {
+	a, b, c, d, e, f := m[0], m[1], m[2], m[3], m[4], m[5]
+	for i := range *td {
+		v := &(*td)[i]
+		x, y := v.Position.X, v.Position.Y
+		v.Position = Vec{a*x + c*y + e, b*x + d*y + f}
+	}
+}
+
 // Unproject does the inverse operation to Project.
 //
 // It turns out that multiplying a vector by the inverse matrix of m can be nearly-accomplished by
 // subtracting the translate part of the matrix and multplying by the inverse of the top-left 2x2
 // matrix, and the inverse of a 2x2 matrix is simple enough to just be inlined in the computation.
 //
+// Unproject works for any composition of Moved, Rotated, Scaled and ScaledXY, e.g. to convert the
+// mouse position to the world coordinates of a camera:
+//
+//   world := cam.Unproject(win.MousePosition())
+//
+// A singular Matrix (with a zero determinant, such as scaled by zero in some direction) has no
+// inverse, then Unproject returns a Vec with both components NaN.
+//
 // Time complexity is O(1).
 func (m Matrix) Unproject(u Vec) Vec {
 	d := (m[0] * m[3]) - (m[1] * m[2])
+	if d == 0 {
+		return Vec{math.NaN(), math.NaN()}
+	}
 	u.X, u.Y = (u.X-m[4])/d, (u.Y-m[5])/d
-	return Vec{u.X*m[3] - u.Y*m[1], u.Y*m[0] - u.X*m[2]}
+	return Vec{u.X*m[3] - u.Y*m[2], u.Y*m[0] - u.X*m[1]}
+}
+
+// MarshalJSON encodes the Matrix as a flat JSON array [a, b, c, d, e, f] of its six elements.
+func (m Matrix) MarshalJSON() ([]byte, error) {
+	return json.Marshal([6]float64(m))
+}
+
+// UnmarshalJSON decodes the Matrix from a flat JSON array of exactly six numbers, as produced by
+// MarshalJSON.
+func (m *Matrix) UnmarshalJSON(data []byte) error {
+	var elems []float64
+	if err := json.Unmarshal(data, &elems); err != nil {
+		return fmt.Errorf("pixel.Matrix: %v", err)
+	}
+	if len(elems) != len(m) {
+		return fmt.Errorf("pixel.Matrix: expected 6 elements, got %d", len(elems))
+	}
+	copy(m[:], elems)
+	return nil
 }
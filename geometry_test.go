@@ -2,6 +2,7 @@ package pixel_test
 
 import (
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/faiface/pixel"
@@ -77,3 +78,303 @@ func TestResizeRect(t *testing.T) {
 		})
 	}
 }
+
+func TestVecEq(t *testing.T) {
+	for _, tt := range []struct {
+		u, v pixel.Vec
+		want bool
+	}{
+		{pixel.V(0.1, 0.2).Add(pixel.V(0.2, 0.1)), pixel.V(0.3, 0.3), true},
+		{pixel.V(1e12, 0), pixel.V(1e12+1, 0), true},
+		{pixel.V(1, 2), pixel.V(1, 2.001), false},
+		{pixel.V(0, 1e-6), pixel.ZV, false},
+	} {
+		if got := tt.u.Eq(tt.v); got != tt.want {
+			t.Errorf("%v.Eq(%v) = %v, want %v", tt.u, tt.v, got, tt.want)
+		}
+	}
+
+	if !pixel.V(1, 2).EqEpsilon(pixel.V(1.05, 1.95), 0.1) {
+		t.Errorf("EqEpsilon with 0.1 tolerance failed")
+	}
+	if pixel.V(1, 2).EqEpsilon(pixel.V(1.05, 1.95), 0.01) {
+		t.Errorf("EqEpsilon with 0.01 tolerance succeeded")
+	}
+
+	if !pixel.V(1, 1).Sub(pixel.V(1, 1)).IsZero() || !pixel.V(1e-12, -1e-12).IsZero() {
+		t.Errorf("IsZero failed for a zero vector")
+	}
+	if pixel.V(0, 1e-6).IsZero() {
+		t.Errorf("IsZero succeeded for a non-zero vector")
+	}
+}
+
+func TestVecSnap(t *testing.T) {
+	for _, tt := range []struct {
+		u, grid, want pixel.Vec
+	}{
+		{pixel.V(7.4, 9), pixel.V(2.5, 0), pixel.V(7.5, 9)},
+		{pixel.V(0.3, -0.3), pixel.V(0.25, 0.25), pixel.V(0.25, -0.25)},
+		{pixel.V(-1.3, 1.3), pixel.V(0.8, 0.8), pixel.V(-1.6, 1.6)},
+		{pixel.V(10, 3.75), pixel.V(4, 1.5), pixel.V(12, 4.5)},
+		{pixel.V(1.1, 2.2), pixel.V(1.0/3, 0.1), pixel.V(1, 2.2)},
+		{pixel.V(3.3, 4.4), pixel.ZV, pixel.V(3.3, 4.4)},
+	} {
+		if got := tt.u.Snap(tt.grid); !got.Eq(tt.want) {
+			t.Errorf("%v.Snap(%v) = %v, want %v", tt.u, tt.grid, got, tt.want)
+		}
+	}
+}
+
+func TestRectPad(t *testing.T) {
+	r := pixel.R(0, 0, 100, 50)
+	for _, tt := range []struct {
+		name      string
+		got, want pixel.Rect
+	}{
+		{"Inset", r.Inset(10), pixel.R(10, 10, 90, 40)},
+		{"Inset negative", r.Inset(-5), pixel.R(-5, -5, 105, 55)},
+		{"Inset collapsed", r.Inset(30), pixel.R(30, 25, 70, 25)},
+		{"Inset fully collapsed", r.Inset(100), pixel.R(50, 25, 50, 25)},
+		{"Pad", r.Pad(1, 2, 3, 4), pixel.R(1, 2, 97, 46)},
+		{"Pad mixed", r.Pad(-10, 0, 10, -20), pixel.R(-10, 0, 90, 70)},
+		{"Pad collapsed", r.Pad(80, 0, 40, 0), pixel.R(70, 0, 70, 50)},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLerp(t *testing.T) {
+	a, b := pixel.V(1, 2), pixel.V(5, -2)
+	r, s := pixel.R(0, 0, 10, 10), pixel.R(10, -10, 30, 0)
+	for _, tt := range []struct {
+		t    float64
+		vec  pixel.Vec
+		rect pixel.Rect
+	}{
+		{0, a, r},
+		{1, b, s},
+		{0.5, pixel.V(3, 0), pixel.R(5, -5, 20, 5)},
+		{2, pixel.V(9, -6), pixel.R(20, -20, 50, -10)},
+		{-0.5, pixel.V(-1, 4), pixel.R(-5, 5, 0, 15)},
+	} {
+		if got := pixel.Lerp(a, b, tt.t); got != tt.vec {
+			t.Errorf("Lerp(%v, %v, %v) = %v, want %v", a, b, tt.t, got, tt.vec)
+		}
+		if got := r.Lerp(s, tt.t); got != tt.rect {
+			t.Errorf("%v.Lerp(%v, %v) = %v, want %v", r, s, tt.t, got, tt.rect)
+		}
+	}
+}
+
+func TestClamp(t *testing.T) {
+	for _, tt := range []struct {
+		x, want float64
+	}{
+		{-1, 0},
+		{0, 0},
+		{0.25, 0.25},
+		{1, 1},
+		{3, 1},
+	} {
+		if got := pixel.Clamp(tt.x, 0, 1); got != tt.want {
+			t.Errorf("Clamp(%v, 0, 1) = %v, want %v", tt.x, got, tt.want)
+		}
+	}
+}
+
+func TestRectIntersect(t *testing.T) {
+	r := pixel.R(0, 0, 10, 10)
+	for _, tt := range []struct {
+		name string
+		s    pixel.Rect
+		want pixel.Rect
+	}{
+		{"overlapping", pixel.R(5, -5, 15, 5), pixel.R(5, 0, 10, 5)},
+		{"inside", pixel.R(2, 3, 4, 5), pixel.R(2, 3, 4, 5)},
+		{"covering", pixel.R(-1, -1, 11, 11), r},
+		{"apart", pixel.R(20, 20, 30, 30), pixel.Rect{}},
+		{"sharing a side", pixel.R(10, 0, 20, 10), pixel.Rect{}},
+		{"sharing a corner", pixel.R(-5, -5, 0, 0), pixel.Rect{}},
+	} {
+		got := r.Intersect(tt.s)
+		if got != tt.want {
+			t.Errorf("%s: %v.Intersect(%v) = %v, want %v", tt.name, r, tt.s, got, tt.want)
+		}
+		if got.Min.X > got.Max.X || got.Min.Y > got.Max.Y {
+			t.Errorf("%s: Intersect returned a non-normalized %v", tt.name, got)
+		}
+		if want := tt.want != (pixel.Rect{}); r.Intersects(tt.s) != want || tt.s.Intersects(r) != want {
+			t.Errorf("%s: Intersects = %v, want %v", tt.name, r.Intersects(tt.s), want)
+		}
+	}
+}
+
+func TestCircle(t *testing.T) {
+	c := pixel.C(pixel.V(1, 2), 3)
+	if got, want := c.String(), "Circle(Vec(1, 2), 3)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got := pixel.C(pixel.ZV, -2).Norm(); got != pixel.C(pixel.ZV, 2) {
+		t.Errorf("Norm() = %v, want radius 2", got)
+	}
+	if got := c.Moved(pixel.V(1, -1)).Resized(5); got != pixel.C(pixel.V(2, 1), 5) {
+		t.Errorf("Moved and Resized = %v", got)
+	}
+	if !c.Contains(pixel.V(4, 2)) || c.Contains(pixel.V(4, 2.1)) {
+		t.Errorf("Contains failed at the border")
+	}
+
+	eq := func(a, b pixel.Circle) bool {
+		return a.Center.Eq(b.Center) && pixel.V(a.Radius, 0).Eq(pixel.V(b.Radius, 0))
+	}
+	for _, tt := range []struct {
+		name      string
+		got, want pixel.Circle
+	}{
+		{"Union", pixel.C(pixel.ZV, 1).Union(pixel.C(pixel.V(4, 0), 1)), pixel.C(pixel.V(2, 0), 3)},
+		{"Union unequal", pixel.C(pixel.ZV, 2).Union(pixel.C(pixel.V(0, 3), 1)), pixel.C(pixel.V(0, 1), 3)},
+		{"Union inside", pixel.C(pixel.ZV, 5).Union(pixel.C(pixel.V(1, 1), 1)), pixel.C(pixel.ZV, 5)},
+		{"Union concentric", pixel.C(pixel.ZV, 1).Union(pixel.C(pixel.ZV, 2)), pixel.C(pixel.ZV, 2)},
+		{"Union zero radius", pixel.C(pixel.ZV, 0).Union(pixel.C(pixel.V(2, 0), 0)), pixel.C(pixel.V(1, 0), 1)},
+		{"Intersect", pixel.C(pixel.ZV, 2).Intersect(pixel.C(pixel.V(3, 0), 2)), pixel.C(pixel.V(1.5, 0), 0.5)},
+		{"Intersect inside", pixel.C(pixel.ZV, 5).Intersect(pixel.C(pixel.V(1, 1), 1)), pixel.C(pixel.V(1, 1), 1)},
+		{"Intersect concentric", pixel.C(pixel.ZV, 1).Intersect(pixel.C(pixel.ZV, 2)), pixel.C(pixel.ZV, 1)},
+		{"Intersect apart", pixel.C(pixel.ZV, 1).Intersect(pixel.C(pixel.V(3, 0), 1)), pixel.C(pixel.ZV, 0)},
+		{"Intersect touching", pixel.C(pixel.ZV, 1).Intersect(pixel.C(pixel.V(2, 0), 1)), pixel.C(pixel.ZV, 0)},
+	} {
+		if !eq(tt.got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestCircleIntersectRect(t *testing.T) {
+	r := pixel.R(0, 0, 10, 4)
+	for _, tt := range []struct {
+		name string
+		c    pixel.Circle
+		want pixel.Vec
+	}{
+		{"apart", pixel.C(pixel.V(-3, 2), 2), pixel.ZV},
+		{"touching", pixel.C(pixel.V(-2, 2), 2), pixel.ZV},
+		{"left side", pixel.C(pixel.V(-1, 2), 2), pixel.V(-1, 0)},
+		{"top side", pixel.C(pixel.V(5, 5), 2), pixel.V(0, 1)},
+		{"corner", pixel.C(pixel.V(13, 8), 10), pixel.V(3, 4)},
+		{"center inside", pixel.C(pixel.V(9, 2), 1), pixel.V(2, 0)},
+		{"center inside near bottom", pixel.C(pixel.V(5, 0.5), 1), pixel.V(0, -1.5)},
+		{"center on the side", pixel.C(pixel.V(0, 2), 1), pixel.V(-1, 0)},
+		{"point inside", pixel.C(pixel.V(5, 3), 0), pixel.V(0, 1)},
+		{"point on the side", pixel.C(pixel.V(5, 4), 0), pixel.ZV},
+		{"covering the rect", pixel.C(pixel.V(5, 2.5), 20), pixel.V(0, 21.5)},
+	} {
+		if got := tt.c.IntersectRect(r); !got.Eq(tt.want) {
+			t.Errorf("%s: %v.IntersectRect(%v) = %v, want %v", tt.name, tt.c, r, got, tt.want)
+		}
+	}
+}
+
+func TestLine(t *testing.T) {
+	l := pixel.L(pixel.V(0, 0), pixel.V(3, 4))
+	if got, want := l.String(), "Line(Vec(0, 0), Vec(3, 4))"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if l.Len() != 5 {
+		t.Errorf("Len() = %v, want 5", l.Len())
+	}
+
+	for _, tt := range []struct {
+		v, closest pixel.Vec
+		contains   bool
+	}{
+		{pixel.V(0, 0), pixel.V(0, 0), true},
+		{pixel.V(0.3, 0.4).Scaled(1.0 / 3).Scaled(3), pixel.V(0.3, 0.4), true}, // rounding noise
+		{pixel.V(1.5, 2), pixel.V(1.5, 2), true},
+		{pixel.V(4, -3), pixel.V(0, 0), false},
+		{pixel.V(6, 8), pixel.V(3, 4), false},
+		{pixel.V(-1, 3), pixel.V(1.08, 1.44), false},
+	} {
+		if got := l.Closest(tt.v); !got.Eq(tt.closest) {
+			t.Errorf("Closest(%v) = %v, want %v", tt.v, got, tt.closest)
+		}
+		if got := l.Contains(tt.v); got != tt.contains {
+			t.Errorf("Contains(%v) = %v, want %v", tt.v, got, tt.contains)
+		}
+	}
+	if p := pixel.L(pixel.V(1, 1), pixel.V(1, 1)); p.Closest(pixel.V(5, 5)) != pixel.V(1, 1) {
+		t.Errorf("Closest on a zero length Line = %v", p.Closest(pixel.V(5, 5)))
+	}
+}
+
+func TestLineIntersect(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		a, b pixel.Line
+		want pixel.Vec
+		ok   bool
+	}{
+		{"crossing", pixel.L(pixel.V(0, 0), pixel.V(4, 4)), pixel.L(pixel.V(0, 4), pixel.V(4, 0)), pixel.V(2, 2), true},
+		{"apart", pixel.L(pixel.V(0, 0), pixel.V(1, 1)), pixel.L(pixel.V(0, 4), pixel.V(4, 0)), pixel.ZV, false},
+		{"touching at end points", pixel.L(pixel.V(0, 0), pixel.V(2, 2)), pixel.L(pixel.V(2, 2), pixel.V(5, 0)), pixel.V(2, 2), true},
+		{"end point on the other", pixel.L(pixel.V(0, 0), pixel.V(4, 0)), pixel.L(pixel.V(1, 0), pixel.V(1, 3)), pixel.V(1, 0), true},
+		{"parallel", pixel.L(pixel.V(0, 0), pixel.V(4, 0)), pixel.L(pixel.V(0, 1), pixel.V(4, 1)), pixel.ZV, false},
+		{"collinear apart", pixel.L(pixel.V(0, 0), pixel.V(1, 1)), pixel.L(pixel.V(2, 2), pixel.V(3, 3)), pixel.ZV, false},
+		{"collinear overlap", pixel.L(pixel.V(0, 0), pixel.V(4, 0)), pixel.L(pixel.V(6, 0), pixel.V(2, 0)), pixel.V(2, 0), true},
+		{"collinear touching", pixel.L(pixel.V(0, 0), pixel.V(2, 0)), pixel.L(pixel.V(2, 0), pixel.V(3, 0)), pixel.V(2, 0), true},
+		{"collinear inside", pixel.L(pixel.V(0, 0), pixel.V(4, 4)), pixel.L(pixel.V(1, 1), pixel.V(2, 2)), pixel.V(1, 1), true},
+		{"zero length on line", pixel.L(pixel.V(0, 0), pixel.V(4, 0)), pixel.L(pixel.V(3, 0), pixel.V(3, 0)), pixel.V(3, 0), true},
+	} {
+		got, ok := tt.a.Intersect(tt.b)
+		if ok != tt.ok || !got.Eq(tt.want) {
+			t.Errorf("%s: %v.Intersect(%v) = %v, %v, want %v, %v", tt.name, tt.a, tt.b, got, ok, tt.want, tt.ok)
+		}
+		if math.IsNaN(got.X) || math.IsNaN(got.Y) {
+			t.Errorf("%s: NaN", tt.name)
+		}
+	}
+}
+
+func TestLineIntersectShapes(t *testing.T) {
+	c := pixel.C(pixel.V(0, 0), 2)
+	for _, tt := range []struct {
+		name string
+		l    pixel.Line
+		want pixel.Vec
+	}{
+		{"apart", pixel.L(pixel.V(-5, 3), pixel.V(5, 3)), pixel.ZV},
+		{"touching", pixel.L(pixel.V(-5, 2), pixel.V(5, 2)), pixel.ZV},
+		{"crossing", pixel.L(pixel.V(-5, 1), pixel.V(5, 1)), pixel.V(0, 1)},
+		{"through the center", pixel.L(pixel.V(-5, 0), pixel.V(5, 0)), pixel.V(0, 2)},
+		{"end point inside", pixel.L(pixel.V(1, 0), pixel.V(5, 0)), pixel.V(1, 0)},
+	} {
+		if got := tt.l.IntersectCircle(c); !got.Eq(tt.want) {
+			t.Errorf("%s: %v.IntersectCircle(%v) = %v, want %v", tt.name, tt.l, c, got, tt.want)
+		}
+	}
+
+	r := pixel.R(0, 0, 10, 4)
+	for _, tt := range []struct {
+		name string
+		l    pixel.Line
+		want pixel.Vec
+	}{
+		{"apart", pixel.L(pixel.V(-1, 0), pixel.V(-1, 4)), pixel.ZV},
+		{"touching", pixel.L(pixel.V(0, 5), pixel.V(10, 4)), pixel.ZV},
+		{"diagonal apart", pixel.L(pixel.V(-2, 3), pixel.V(1, 6)), pixel.ZV},
+		{"crossing the top", pixel.L(pixel.V(-5, 3), pixel.V(15, 3)), pixel.V(0, 1)},
+		{"inside near the right", pixel.L(pixel.V(9, 1), pixel.V(9, 3)), pixel.V(1, 0)},
+		{"touching the corner", pixel.L(pixel.V(9, 5), pixel.V(11, 3)), pixel.ZV},
+		{"cutting the corner", pixel.L(pixel.V(9, 4.5), pixel.V(10.5, 3)), pixel.V(0.25, 0.25)},
+	} {
+		if got := tt.l.IntersectRect(r); !got.Eq(tt.want) {
+			t.Errorf("%s: %v.IntersectRect(%v) = %v, want %v", tt.name, tt.l, r, got, tt.want)
+		}
+		if mtv := tt.l.IntersectRect(r); mtv != pixel.ZV {
+			if again := tt.l.Moved(mtv).IntersectRect(r); !again.EqEpsilon(pixel.ZV, 1e-9) {
+				t.Errorf("%s: still overlapping by %v after moving by %v", tt.name, again, mtv)
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package pixel
+
+// GrowthPolicy specifies how the container of a Batch grows when objects are drawn onto it and
+// whether it shrinks again, see Batch.SetGrowthPolicy.
+type GrowthPolicy int
+
+const (
+	// GrowthDouble at least doubles the capacity of the container whenever it's full. It never
+	// shrinks. This is the default.
+	GrowthDouble GrowthPolicy = iota
+
+	// GrowthExact grows the capacity of the container exactly to the needed size. It never
+	// shrinks. It wastes no memory, but reallocates on every growth, which suits Batches that
+	// are filled once.
+	GrowthExact
+
+	// GrowthShrink grows like GrowthDouble and shrinks the container on Clear, when the content
+	// stayed at most a quarter of the capacity for the last shrinkAfterClears clears. It suits
+	// Batches which occasionally get much larger than usual.
+	GrowthShrink
+)
+
+// shrinkAfterClears is the number of clears after which GrowthShrink may shrink the container,
+// about a second of frames, so that a few small frames don't cause reallocations.
+const shrinkAfterClears = 60
+
+// growthState tracks the content of the container for GrowthShrink.
+type growthState struct {
+	peak   int // the largest len since the last shrink check
+	clears int
+}
+
+// SetGrowthPolicy sets how the container of the Batch grows when objects are drawn onto it, see
+// GrowthPolicy. It only applies to containers of the type *TrianglesData, other containers grow
+// the way they do on SetLen.
+//
+// The policy only affects the container of the Batch. The Targets the Batch is drawn to grow
+// their own buffers (such as the vertex buffers in the video memory) their own way.
+func (b *Batch) SetGrowthPolicy(policy GrowthPolicy) {
+	b.growth = policy
+	b.growthState = growthState{}
+}
+
+// GrowthPolicy returns the growth policy of the Batch, see SetGrowthPolicy.
+func (b *Batch) GrowthPolicy() GrowthPolicy {
+	return b.growth
+}
+
+// reserve makes room for n more vertices in the container according to the growth policy.
+func (b *Batch) reserve(n int) {
+	td, ok := b.cont.Triangles.(*TrianglesData)
+	if !ok {
+		return
+	}
+	need := len(*td) + n
+	if need <= cap(*td) {
+		return
+	}
+	newCap := need
+	if b.growth != GrowthExact && 2*cap(*td) > newCap {
+		newCap = 2 * cap(*td)
+	}
+	grown := make(TrianglesData, len(*td), newCap)
+	copy(grown, *td)
+	*td = grown
+}
+
+// shrink is called on Clear with the len of the container before clearing, it shrinks the
+// container according to the growth policy.
+func (b *Batch) shrink(oldLen int) {
+	td, ok := b.cont.Triangles.(*TrianglesData)
+	if !ok || b.growth != GrowthShrink {
+		return
+	}
+	gs := &b.growthState
+	if oldLen > gs.peak {
+		gs.peak = oldLen
+	}
+	gs.clears++
+	if gs.clears < shrinkAfterClears {
+		return
+	}
+	if 4*gs.peak <= cap(*td) {
+		*td = make(TrianglesData, len(*td), 2*gs.peak)
+	}
+	*gs = growthState{}
+}
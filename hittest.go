@@ -0,0 +1,72 @@
+package pixel
+
+import "math"
+
+// Placement is a Sprite drawn with a Matrix, as in sprite.Draw(target, matrix). It's used for hit
+// testing, see HitTest.
+type Placement struct {
+	Sprite *Sprite
+	Matrix Matrix
+
+	// AlphaTest makes only the pixels of the Sprite which aren't fully transparent hit. The
+	// Sprite's Picture must implement PictureColor, otherwise the whole frame hits.
+	AlphaTest bool
+}
+
+// Hit returns whether the point (in the coordinates of the Target the Sprite is drawn onto) is over
+// the Sprite. The anchor and the UV offset of the Sprite are taken into account.
+func (p Placement) Hit(point Vec) bool {
+	if p.Sprite == nil {
+		return false
+	}
+	frame := p.Sprite.Frame()
+	// the anchor is at the origin, just like in Sprite.calcData
+	origin := frame.Min.Add(p.Sprite.Anchor().ScaledXY(frame.Size()))
+	local := p.Matrix.Unproject(point).Add(origin)
+	if !frame.Contains(local) {
+		return false
+	}
+	if p.AlphaTest {
+		if pic, ok := p.Sprite.Picture().(PictureColor); ok {
+			offset := p.Sprite.UVOffset()
+			uv := V(
+				scrollingWrap(local.X, frame.Min.X, frame.W(), offset.X),
+				scrollingWrap(local.Y, frame.Min.Y, frame.H(), offset.Y),
+			)
+			return pic.Color(uv).A > 0
+		}
+	}
+	return true
+}
+
+// scrollingWrap returns the coordinate of the content shown at x within the frame starting at
+// start, when the content is scrolled by the offset, see Sprite.SetUVOffset.
+func scrollingWrap(x, start, size, offset float64) float64 {
+	if offset == 0 || size <= 0 {
+		return x
+	}
+	u := math.Mod(x-start+offset, size)
+	if u < 0 {
+		u += size
+	}
+	return start + u
+}
+
+// HitTest returns the index of the topmost placement the point is over. Placements are assumed to
+// be drawn in order, so later placements are on top of earlier ones. If the point isn't over any
+// of them, ok is false.
+//
+// This is a CPU-side alternative to picking on the GPU, useful for clicking on UI elements and
+// objects:
+//
+//   if i, ok := pixel.HitTest(cam.Unproject(win.MousePosition()), placements); ok {
+//       selected = i
+//   }
+func HitTest(point Vec, placements []Placement) (index int, ok bool) {
+	for i := len(placements) - 1; i >= 0; i-- {
+		if placements[i].Hit(point) {
+			return i, true
+		}
+	}
+	return -1, false
+}
@@ -0,0 +1,70 @@
+package pixel_test
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+func TestHitTest(t *testing.T) {
+	// the left half of the picture is transparent
+	pic := pixel.MakePictureData(pixel.R(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 2; x < 4; x++ {
+			pic.Pix[y*pic.Stride+x] = color.RGBA{255, 255, 255, 255}
+		}
+	}
+	sprite := pixel.NewSprite(pic, pic.Bounds())
+
+	placements := []pixel.Placement{
+		{Sprite: sprite, Matrix: pixel.IM.Moved(pixel.V(10, 10))},
+		{Sprite: sprite, Matrix: pixel.IM.Rotated(pixel.ZV, math.Pi/2).Moved(pixel.V(11, 10))},
+		{Sprite: sprite, Matrix: pixel.IM.Moved(pixel.V(20, 10)), AlphaTest: true},
+	}
+
+	tests := []struct {
+		point pixel.Vec
+		index int
+		ok    bool
+	}{
+		{pixel.V(9, 10.5), 0, true},
+		{pixel.V(11, 10.5), 1, true}, // both overlap, the later one is on top
+		{pixel.V(11, 11.5), 1, true}, // only the rotated one covers this
+		{pixel.V(12.5, 10.5), -1, false},
+		{pixel.V(21, 10.5), 2, true},
+		{pixel.V(19, 10.5), -1, false}, // transparent pixel
+		{pixel.V(0, 0), -1, false},
+	}
+	for _, test := range tests {
+		index, ok := pixel.HitTest(test.point, placements)
+		if index != test.index || ok != test.ok {
+			t.Errorf("HitTest(%v) = %d, %v, want %d, %v", test.point, index, ok, test.index, test.ok)
+		}
+	}
+
+	// anchored by the bottom-left corner, the Sprite covers (0, 0) to (4, 2)
+	anchored := pixel.NewSprite(pic, pic.Bounds())
+	anchored.SetAnchor(pixel.V(0, 0))
+	p := pixel.Placement{Sprite: anchored, Matrix: pixel.IM, AlphaTest: true}
+	for _, test := range []struct {
+		point pixel.Vec
+		hit   bool
+	}{
+		{pixel.V(3, 1), true},
+		{pixel.V(1, 1), false}, // transparent pixel
+		{pixel.V(-1, -0.5), false},
+		{pixel.V(5, 1), false},
+	} {
+		if hit := p.Hit(test.point); hit != test.hit {
+			t.Errorf("anchored Hit(%v) = %v, want %v", test.point, hit, test.hit)
+		}
+	}
+
+	// scrolled by half of the width, the opaque half shows on the left
+	anchored.SetUVOffset(pixel.V(2, 0))
+	if !p.Hit(pixel.V(1, 1)) || p.Hit(pixel.V(3, 1)) {
+		t.Errorf("Hit ignores the UV offset")
+	}
+}
@@ -3,6 +3,7 @@
 package imdraw
 
 import (
+	"fmt"
 	"image/color"
 	"math"
 
@@ -38,7 +39,7 @@ import (
 //
 // And here's the list of all shapes that can be drawn (all, except for line, can be filled or
 // outlined):
-//   - Line
+//   - Line (and Polyline, which takes the points directly)
 //   - Polygon
 //   - Circle
 //   - Circle arc
@@ -56,6 +57,10 @@ type IMDraw struct {
 	matrix pixel.Matrix
 	mask   pixel.RGBA
 
+	dash       []float64
+	dashOffset float64
+	antialias  float64
+
 	tri   *pixel.TrianglesData
 	batch *pixel.Batch
 }
@@ -108,7 +113,8 @@ func (imd *IMDraw) Clear() {
 
 // Reset restores all point properties to defaults and removes all Pushed points.
 //
-// This does not affect matrix and color mask set by SetMatrix and SetColorMask.
+// This does not affect matrix, color mask, dash pattern and anti-aliasing set by SetMatrix,
+// SetColorMask, SetDash and SetAntialias.
 func (imd *IMDraw) Reset() {
 	imd.points = imd.points[:0]
 	imd.Color = pixel.Alpha(1)
@@ -128,19 +134,24 @@ func (imd *IMDraw) Draw(t pixel.Target) {
 // Push adds some points to the IM queue. All Pushed points will have the same properties except for
 // the position.
 func (imd *IMDraw) Push(pts ...pixel.Vec) {
+	opts := imd.pointOpts()
+	for _, pt := range pts {
+		imd.pushPt(pt, opts)
+	}
+}
+
+// pointOpts returns the properties of a point Pushed right now.
+func (imd *IMDraw) pointOpts() point {
 	if _, ok := imd.Color.(pixel.RGBA); !ok {
 		imd.Color = pixel.ToRGBA(imd.Color)
 	}
-	opts := point{
+	return point{
 		col:       imd.Color.(pixel.RGBA),
 		pic:       imd.Picture,
 		in:        imd.Intensity,
 		precision: imd.Precision,
 		endshape:  imd.EndShape,
 	}
-	for _, pt := range pts {
-		imd.pushPt(pt, opts)
-	}
 }
 
 func (imd *IMDraw) pushPt(pos pixel.Vec, pt point) {
@@ -171,10 +182,75 @@ func (imd *IMDraw) MakePicture(p pixel.Picture) pixel.TargetPicture {
 }
 
 // Line draws a polyline of the specified thickness between the Pushed points.
+//
+// If a dash pattern is set by SetDash, the polyline is drawn dashed. Each dash is a separate
+// polyline with its own end shapes.
 func (imd *IMDraw) Line(thickness float64) {
+	defer imd.fringe(imd.tri.Len())
+	if len(imd.dash) > 0 {
+		imd.dashedLine(thickness)
+		return
+	}
 	imd.polyline(thickness, false)
 }
 
+// SetDash sets the dash pattern of the following Lines. The pattern alternates the lengths (in
+// units of the Pushed points) of the drawn dashes and the gaps between them, starting with a dash.
+// If it has an odd number of elements, it's repeated to make it even, so [5] is the same as [5, 5].
+//
+// The offset shifts the pattern along the line, so increasing it every frame makes the dashes
+// march along the line:
+//
+//   imd.SetDash([]float64{6, 4}, 10*time.Since(start).Seconds())
+//
+// Zero-length dashes with the RoundEndShape draw dotted lines:
+//
+//   imd.EndShape = imdraw.RoundEndShape
+//   imd.SetDash([]float64{0, 8}, 0)
+//
+// Pass an empty pattern to draw solid lines again.
+func (imd *IMDraw) SetDash(pattern []float64, offset float64) {
+	sum := 0.0
+	for _, x := range pattern {
+		if x < 0 {
+			panic(fmt.Errorf("(%T).SetDash: negative length in dash pattern %v", imd, pattern))
+		}
+		sum += x
+	}
+	if len(pattern) > 0 && sum == 0 {
+		panic(fmt.Errorf("(%T).SetDash: dash pattern %v has zero length", imd, pattern))
+	}
+
+	imd.dash = append(imd.dash[:0], pattern...)
+	if len(pattern)%2 == 1 {
+		imd.dash = append(imd.dash, pattern...)
+	}
+	imd.dashOffset = offset
+}
+
+// SetAntialias sets the width (in units of the Target the IMDraw is drawn onto, usually pixels) of
+// the feather strip added along the silhouette of each following shape. The strip fades from the
+// color of the shape to transparent, which smooths the jagged edges without multisampling. Zero
+// (the default) turns it off.
+//
+//   imd.SetAntialias(1.5)
+//
+// The width doesn't scale with the matrix set by SetMatrix, the strip is added after the shape is
+// transformed by it. Only the outer edges get a strip, not the edges shared by the triangles of the
+// shape, so the shapes grow by the width, but are not darkened inside. Overlapping shapes drawn by
+// the same call may get strips over each other, which are invisible with opaque colors.
+//
+// The strips cost two triangles per edge of the silhouette, which is cheap for the vertex count,
+// but the fill rate grows with the total length of the edges. Also note that with additive
+// blending (ComposePlus), the strips add up where they overlap, so they may show up as faint
+// seams between touching shapes.
+func (imd *IMDraw) SetAntialias(pixels float64) {
+	if pixels < 0 {
+		panic(fmt.Errorf("(%T).SetAntialias: negative width %v", imd, pixels))
+	}
+	imd.antialias = pixels
+}
+
 // Rectangle draws a rectangle between each two subsequent Pushed points. Drawing a rectangle
 // between two points means drawing a rectangle with sides parallel to the axes of the coordinate
 // system, where the two points specify it's two opposite corners.
@@ -182,6 +258,7 @@ func (imd *IMDraw) Line(thickness float64) {
 // If the thickness is 0, rectangles will be filled, otherwise will be outlined with the given
 // thickness.
 func (imd *IMDraw) Rectangle(thickness float64) {
+	defer imd.fringe(imd.tri.Len())
 	if thickness == 0 {
 		imd.fillRectangle()
 	} else {
@@ -197,6 +274,7 @@ func (imd *IMDraw) Rectangle(thickness float64) {
 // triangle is drawn between each two adjacent points and the first Pushed point. You can use this
 // property to draw certain kinds of concave polygons.
 func (imd *IMDraw) Polygon(thickness float64) {
+	defer imd.fringe(imd.tri.Len())
 	if thickness == 0 {
 		imd.fillPolygon()
 	} else {
@@ -207,6 +285,7 @@ func (imd *IMDraw) Polygon(thickness float64) {
 // Circle draws a circle of the specified radius around each Pushed point. If the thickness is 0,
 // the circle will be filled, otherwise a circle outline of the specified thickness will be drawn.
 func (imd *IMDraw) Circle(radius, thickness float64) {
+	defer imd.fringe(imd.tri.Len())
 	if thickness == 0 {
 		imd.fillEllipseArc(pixel.V(radius, radius), 0, 2*math.Pi)
 	} else {
@@ -223,6 +302,7 @@ func (imd *IMDraw) Circle(radius, thickness float64) {
 //
 // This line will fill the whole circle 4 times.
 func (imd *IMDraw) CircleArc(radius, low, high, thickness float64) {
+	defer imd.fringe(imd.tri.Len())
 	if thickness == 0 {
 		imd.fillEllipseArc(pixel.V(radius, radius), low, high)
 	} else {
@@ -234,6 +314,7 @@ func (imd *IMDraw) CircleArc(radius, low, high, thickness float64) {
 // thickness is 0, the ellipse will be filled, otherwise an ellipse outline of the specified
 // thickness will be drawn.
 func (imd *IMDraw) Ellipse(radius pixel.Vec, thickness float64) {
+	defer imd.fringe(imd.tri.Len())
 	if thickness == 0 {
 		imd.fillEllipseArc(radius, 0, 2*math.Pi)
 	} else {
@@ -250,6 +331,7 @@ func (imd *IMDraw) Ellipse(radius pixel.Vec, thickness float64) {
 //
 // This line will fill the whole ellipse 4 times.
 func (imd *IMDraw) EllipseArc(radius pixel.Vec, low, high, thickness float64) {
+	defer imd.fringe(imd.tri.Len())
 	if thickness == 0 {
 		imd.fillEllipseArc(radius, low, high)
 	} else {
@@ -276,9 +358,10 @@ func (imd *IMDraw) restorePoints(points []point) {
 }
 
 func (imd *IMDraw) applyMatrixAndMask(off int) {
-	for i := range (*imd.tri)[off:] {
-		(*imd.tri)[off+i].Position = imd.matrix.Project((*imd.tri)[off+i].Position)
-		(*imd.tri)[off+i].Color = imd.mask.Mul((*imd.tri)[off+i].Color)
+	added := (*imd.tri)[off:]
+	imd.matrix.ProjectTrianglesData(&added)
+	for i := range added {
+		added[i].Color = imd.mask.Mul(added[i].Color)
 	}
 }
 
@@ -625,3 +708,178 @@ func (imd *IMDraw) polyline(thickness float64, closed bool) {
 
 	imd.restorePoints(points)
 }
+
+func (imd *IMDraw) dashedLine(thickness float64) {
+	points := imd.getAndClearPoints()
+
+	if len(points) == 0 {
+		imd.restorePoints(points)
+		return
+	}
+
+	// find where in the pattern the line starts
+	total := 0.0
+	for _, x := range imd.dash {
+		total += x
+	}
+	phase := math.Mod(imd.dashOffset, total)
+	if phase < 0 {
+		phase += total
+	}
+	idx := 0
+	for phase >= imd.dash[idx] {
+		phase -= imd.dash[idx]
+		idx = (idx + 1) % len(imd.dash)
+	}
+	remain := imd.dash[idx] - phase
+
+	var dash []point
+	if idx%2 == 0 {
+		dash = append(dash, points[0])
+	}
+
+	for i := 0; i+1 < len(points); i++ {
+		a, b := points[i], points[i+1]
+		length := a.pos.To(b.pos).Len()
+
+		// split the segment by arc length wherever a dash or a gap ends
+		t := 0.0
+		for length-t > remain {
+			t += remain
+			pt := lerpPoint(a, b, t/length)
+
+			if idx%2 == 0 {
+				dash = append(dash, pt)
+				imd.dashPolyline(dash, thickness)
+				dash = dash[:0]
+			} else {
+				dash = append(dash[:0], pt)
+			}
+
+			idx = (idx + 1) % len(imd.dash)
+			remain = imd.dash[idx]
+		}
+		remain -= length - t
+
+		if idx%2 == 0 {
+			dash = append(dash, b)
+		}
+	}
+
+	if idx%2 == 0 && len(dash) > 0 {
+		imd.dashPolyline(dash, thickness)
+	}
+
+	imd.restorePoints(points)
+}
+
+// dashPolyline draws one dash of a dashed line.
+func (imd *IMDraw) dashPolyline(dash []point, thickness float64) {
+	for _, pt := range dash {
+		imd.pushPt(pt.pos, pt)
+	}
+	imd.polyline(thickness, false)
+}
+
+// lerpPoint returns a point between a and b with the position and the color interpolated.
+func lerpPoint(a, b point, t float64) point {
+	pt := a
+	pt.pos = pixel.Lerp(a.pos, b.pos, t)
+	pt.col = a.col.Add(b.col.Sub(a.col).Scaled(t))
+	return pt
+}
+
+// fringeKey identifies a vertex position in fringe. Positions are rounded so that vertices which
+// should be the same, but differ by rounding errors, such as the first and the last vertex of a
+// circle, are merged.
+type fringeKey [2]int64
+
+func makeFringeKey(v pixel.Vec) fringeKey {
+	const grid = 1 << 12
+	return fringeKey{int64(math.Floor(v.X*grid + 0.5)), int64(math.Floor(v.Y*grid + 0.5))}
+}
+
+// fringe adds the anti-aliasing strips along the silhouette of the triangles from off, if enabled
+// by SetAntialias. The silhouette consists of the edges which don't belong to two triangles.
+func (imd *IMDraw) fringe(off int) {
+	if imd.antialias <= 0 || imd.tri.Len() <= off {
+		return
+	}
+	width := imd.antialias
+	shape := (*imd.tri)[off:]
+
+	type edgeKey [2]fringeKey
+	makeEdgeKey := func(a, b fringeKey) edgeKey {
+		if b[0] < a[0] || (b[0] == a[0] && b[1] < a[1]) {
+			a, b = b, a
+		}
+		return edgeKey{a, b}
+	}
+	edges := make(map[edgeKey]int)
+	for i := 0; i+2 < len(shape); i += 3 {
+		for k := 0; k < 3; k++ {
+			a := makeFringeKey(shape[i+k].Position)
+			b := makeFringeKey(shape[i+(k+1)%3].Position)
+			if a != b {
+				edges[makeEdgeKey(a, b)]++
+			}
+		}
+	}
+
+	// outer edges with their outward normals
+	type outer struct {
+		a, b   int // indices into shape
+		normal pixel.Vec
+	}
+	var outers []outer
+	type corner struct {
+		normal pixel.Vec
+		count  int
+	}
+	corners := make(map[fringeKey]corner)
+	for i := 0; i+2 < len(shape); i += 3 {
+		for k := 0; k < 3; k++ {
+			ia, ib, ic := i+k, i+(k+1)%3, i+(k+2)%3
+			ka, kb := makeFringeKey(shape[ia].Position), makeFringeKey(shape[ib].Position)
+			if ka == kb || edges[makeEdgeKey(ka, kb)] != 1 {
+				continue
+			}
+			a, b, c := shape[ia].Position, shape[ib].Position, shape[ic].Position
+			n := a.To(b).Normal().Unit()
+			if n.Dot(a.To(c)) > 0 {
+				n = n.Scaled(-1)
+			}
+			outers = append(outers, outer{ia, ib, n})
+			for _, key := range [...]fringeKey{ka, kb} {
+				c := corners[key]
+				c.normal = c.normal.Add(n)
+				c.count++
+				corners[key] = c
+			}
+		}
+	}
+
+	// the outer vertex of a corner is moved along the average normal of its edges, far enough for
+	// the strips to keep the width, but not too far at sharp corners
+	extrude := func(v pixel.Vec, n pixel.Vec) pixel.Vec {
+		c := corners[makeFringeKey(v)]
+		avg := c.normal.Scaled(1 / float64(c.count))
+		if avg.Len() < 0.25 {
+			return v.Add(n.Scaled(width))
+		}
+		return v.Add(avg.Scaled(width / avg.Dot(avg)))
+	}
+
+	start := imd.tri.Len()
+	imd.tri.SetLen(start + 6*len(outers))
+	for i, o := range outers {
+		a, b := (*imd.tri)[off+o.a], (*imd.tri)[off+o.b]
+		aOut, bOut := a, b
+		aOut.Position, aOut.Color = extrude(a.Position, o.normal), pixel.Alpha(0)
+		bOut.Position, bOut.Color = extrude(b.Position, o.normal), pixel.Alpha(0)
+		j := start + 6*i
+		(*imd.tri)[j+0], (*imd.tri)[j+1], (*imd.tri)[j+2] = a, b, bOut
+		(*imd.tri)[j+3], (*imd.tri)[j+4], (*imd.tri)[j+5] = a, bOut, aOut
+	}
+	imd.batch.Dirty()
+}
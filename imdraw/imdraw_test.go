@@ -2,6 +2,7 @@ package imdraw_test
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 	"testing"
 
@@ -94,3 +95,186 @@ func BenchmarkEllipseOutline(b *testing.B) {
 		})
 	}
 }
+
+func TestDashedLine(t *testing.T) {
+	tests := []struct {
+		offset float64
+		dashes []pixel.Vec // [from, to] along the X axis
+	}{
+		{0, []pixel.Vec{{X: 0, Y: 2}, {X: 5, Y: 7}}},
+		{1, []pixel.Vec{{X: 0, Y: 1}, {X: 4, Y: 6}}},
+		{-1, []pixel.Vec{{X: 1, Y: 3}, {X: 6, Y: 8}}},
+	}
+
+	for _, test := range tests {
+		imd := imdraw.New(nil)
+		imd.SetDash([]float64{2, 3}, test.offset)
+		imd.Push(pixel.V(0, 0), pixel.V(4, 0), pixel.V(9, 0))
+		imd.Line(1)
+
+		tri := &pixel.TrianglesData{}
+		imd.Draw(pixel.NewBatch(tri, nil))
+
+		covered := make([]bool, len(test.dashes))
+	vertices:
+		for _, v := range *tri {
+			for i, d := range test.dashes {
+				if v.Position.X >= d.X-1e-9 && v.Position.X <= d.Y+1e-9 {
+					covered[i] = true
+					continue vertices
+				}
+			}
+			t.Errorf("offset %v: vertex %v outside of the dashes %v", test.offset, v.Position, test.dashes)
+		}
+		for i, ok := range covered {
+			if !ok {
+				t.Errorf("offset %v: dash %v not drawn", test.offset, test.dashes[i])
+			}
+		}
+	}
+}
+
+func TestAntialias(t *testing.T) {
+	draw := func(antialias float64, shape func(imd *imdraw.IMDraw)) pixel.TrianglesData {
+		imd := imdraw.New(nil)
+		imd.SetMatrix(pixel.IM.Scaled(pixel.ZV, 2))
+		imd.SetAntialias(antialias)
+		shape(imd)
+		tri := &pixel.TrianglesData{}
+		imd.Draw(pixel.NewBatch(tri, nil))
+		return *tri
+	}
+
+	shapes := []struct {
+		name  string
+		shape func(imd *imdraw.IMDraw)
+		edges int
+	}{
+		{"rectangle", func(imd *imdraw.IMDraw) {
+			imd.Push(pixel.V(0, 0), pixel.V(10, 10))
+			imd.Rectangle(0)
+		}, 4},
+		{"circle", func(imd *imdraw.IMDraw) {
+			imd.Push(pixel.V(0, 0))
+			imd.Circle(10, 0)
+		}, 64},
+		{"polygon", func(imd *imdraw.IMDraw) {
+			imd.Push(pixel.V(0, 0), pixel.V(10, 0), pixel.V(10, 10), pixel.V(5, 15), pixel.V(0, 10))
+			imd.Polygon(0)
+		}, 5},
+	}
+
+	for _, s := range shapes {
+		plain := draw(0, s.shape)
+		aa := draw(1, s.shape)
+
+		if len(aa) != len(plain)+6*s.edges {
+			t.Errorf("%s: got %d fringe vertices, want %d", s.name, len(aa)-len(plain), 6*s.edges)
+			continue
+		}
+		for i := range plain {
+			if plain[i] != aa[i] {
+				t.Errorf("%s: shape vertex %d changed from %v to %v", s.name, i, plain[i], aa[i])
+			}
+		}
+		transparent := 0
+		for _, v := range aa[len(plain):] {
+			if v.Color == pixel.Alpha(0) {
+				transparent++
+			}
+		}
+		if transparent != 3*s.edges {
+			t.Errorf("%s: got %d transparent fringe vertices, want %d", s.name, transparent, 3*s.edges)
+		}
+	}
+
+	// the fringe is 1 unit wide after the matrix, so the corners move by 1 unit diagonally
+	aa := draw(1, shapes[0].shape)
+	bounds := pixel.R(0, 0, 0, 0)
+	for _, v := range aa {
+		bounds = bounds.Union(pixel.R(v.Position.X, v.Position.Y, v.Position.X, v.Position.Y))
+	}
+	if want := pixel.R(-1, -1, 21, 21); bounds.Min.To(want.Min).Len() > 1e-9 || bounds.Max.To(want.Max).Len() > 1e-9 {
+		t.Errorf("rectangle: got bounds %v, want %v", bounds, want)
+	}
+}
+
+func TestAntialiasLine(t *testing.T) {
+	imd := imdraw.New(nil)
+	imd.SetAntialias(1)
+	imd.Push(pixel.V(0, 0), pixel.V(10, 0), pixel.V(10, 10))
+	imd.Line(2)
+	tri := &pixel.TrianglesData{}
+	imd.Draw(pixel.NewBatch(tri, nil))
+
+	bounds := pixel.R(0, 0, 0, 0)
+	transparent := 0
+	for _, v := range *tri {
+		bounds = bounds.Union(pixel.R(v.Position.X, v.Position.Y, v.Position.X, v.Position.Y))
+		if v.Color == pixel.Alpha(0) {
+			transparent++
+		}
+	}
+	if transparent == 0 {
+		t.Fatal("no fringe drawn")
+	}
+	if bounds.Max.X < 12-1e-9 || bounds.Min.Y > -2+1e-9 {
+		t.Errorf("fringe not outside of the line: bounds %v", bounds)
+	}
+}
+
+func TestPolyline(t *testing.T) {
+	draw := func(shape imdraw.EndShape, points []pixel.Vec, closed bool) pixel.TrianglesData {
+		imd := imdraw.New(nil)
+		imd.EndShape = shape
+		imd.Polyline(points, 2, closed)
+		tri := &pixel.TrianglesData{}
+		imd.Draw(pixel.NewBatch(tri, nil))
+		return *tri
+	}
+	area := func(tri pixel.TrianglesData) float64 {
+		sum := 0.0
+		for i := 0; i+2 < len(tri); i += 3 {
+			a, b, c := tri[i].Position, tri[i+1].Position, tri[i+2].Position
+			sum += math.Abs(a.To(b).Cross(a.To(c))) / 2
+		}
+		return sum
+	}
+
+	square := []pixel.Vec{pixel.V(0, 0), pixel.V(10, 0), pixel.V(10, 10), pixel.V(0, 10)}
+	tests := []struct {
+		name   string
+		shape  imdraw.EndShape
+		closed bool
+		verts  int
+		area   float64
+	}{
+		// the strip covers exactly the outline without overlaps, 12x12 minus 8x8
+		{"mitered square", imdraw.SharpEndShape, true, 4 * 6, 12*12 - 8*8},
+		{"beveled square", imdraw.NoEndShape, true, 4*6 + 4*3, 12*12 - 8*8 - 4*0.5},
+		// three segments 30 units long in total, minus the bevels or plus the pointed ends
+		{"open beveled", imdraw.NoEndShape, false, 3*6 + 2*3, 30*2 - 2*0.5},
+		{"open mitered", imdraw.SharpEndShape, false, 3*6 + 2*3, 30*2 + 2*1},
+	}
+	for _, test := range tests {
+		tri := draw(test.shape, square, test.closed)
+		if len(tri) != test.verts {
+			t.Errorf("%s: got %d vertices, want %d", test.name, len(tri), test.verts)
+		}
+		if got := area(tri); math.Abs(got-test.area) > 1e-9 {
+			t.Errorf("%s: covers area %v, want %v", test.name, got, test.area)
+		}
+	}
+
+	// the round joins and ends only add area outside of the mitered outline
+	round := area(draw(imdraw.RoundEndShape, square, true))
+	if want := 12*12 - 8*8 - 4*(1-math.Pi/4); math.Abs(round-want) > 0.05 {
+		t.Errorf("rounded square: covers area %v, want about %v", round, want)
+	}
+
+	// duplicate points and a closing point equal to the first are ignored
+	dup := draw(imdraw.SharpEndShape, append([]pixel.Vec{square[0]}, append(square, square[0])...), true)
+	if len(dup) != 4*6 {
+		t.Errorf("square with duplicate points: got %d vertices, want %d", len(dup), 4*6)
+	}
+}
@@ -0,0 +1,193 @@
+package imdraw
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+// polylineMiterLimit is the longest miter of a Polyline join relative to the thickness. Sharper
+// joins are beveled (or rounded).
+const polylineMiterLimit = 4
+
+// polylineEdge is the left and the right side of a Polyline at a point.
+type polylineEdge struct {
+	l, r pixel.Vec
+}
+
+// Polyline draws a polyline of the specified thickness through the points. If closed, the last
+// point is joined to the first one. The Pushed points are not used nor cleared, the points get the
+// current properties (Color, Precision, ...) of the IMDraw, as if they were Pushed now.
+//
+// Unlike Line, which draws each segment as a separate quad with separate joins, Polyline draws a
+// continuous strip of quads sharing the vertices at the joins, so nothing overlaps (except at the
+// inner side of very sharp joins) and there are no seams with translucent colors or antialiasing.
+// It also takes fewer vertices.
+//
+// The EndShape sets both the joins and the ends of an open polyline:
+//   - NoEndShape     - beveled joins, flat ends
+//   - SharpEndShape  - mitered joins (beveled when the miter gets longer than 4 times the
+//                      thickness), pointed ends
+//   - RoundEndShape  - rounded joins and ends
+func (imd *IMDraw) Polyline(points []pixel.Vec, thickness float64, closed bool) {
+	defer imd.fringe(imd.tri.Len())
+
+	pts := make([]pixel.Vec, 0, len(points))
+	for _, p := range points {
+		if len(pts) == 0 || p != pts[len(pts)-1] {
+			pts = append(pts, p)
+		}
+	}
+	if closed && len(pts) > 1 && pts[0] == pts[len(pts)-1] {
+		pts = pts[:len(pts)-1]
+	}
+	if len(pts) < 2 {
+		return
+	}
+	if len(pts) == 2 {
+		closed = false // a loop of two points is a line there and back
+	}
+
+	opts := imd.pointOpts()
+	hw := thickness / 2
+	n := len(pts)
+	normal := func(i int) pixel.Vec {
+		return pts[i].To(pts[(i+1)%n]).Unit().Normal().Scaled(hw)
+	}
+
+	var tris []pixel.Vec
+	in := make([]polylineEdge, n)
+	out := make([]polylineEdge, n)
+	for j, p := range pts {
+		switch {
+		case !closed && j == 0:
+			nrm := normal(0)
+			in[j], out[j] = polylineEdge{p.Add(nrm), p.Sub(nrm)}, polylineEdge{p.Add(nrm), p.Sub(nrm)}
+			tris = polylineCap(tris, p, nrm, opts, true)
+		case !closed && j == n-1:
+			nrm := normal(n - 2)
+			in[j], out[j] = polylineEdge{p.Add(nrm), p.Sub(nrm)}, polylineEdge{p.Add(nrm), p.Sub(nrm)}
+			tris = polylineCap(tris, p, nrm, opts, false)
+		default:
+			in[j], out[j], tris = polylineJoin(tris, p, normal((j+n-1)%n), normal(j), opts)
+		}
+	}
+
+	segments := n - 1
+	if closed {
+		segments = n
+	}
+	for i := 0; i < segments; i++ {
+		a, b := out[i], in[(i+1)%n]
+		tris = append(tris, a.l, a.r, b.r, a.l, b.r, b.l)
+	}
+
+	off := imd.tri.Len()
+	imd.tri.SetLen(off + len(tris))
+	for i, pos := range tris {
+		tri := &(*imd.tri)[off+i]
+		tri.Position = pos
+		tri.Color = opts.col
+		tri.Picture = opts.pic
+		tri.Intensity = opts.in
+	}
+	imd.applyMatrixAndMask(off)
+	imd.batch.Dirty()
+}
+
+// polylineJoin returns the edges ending the segment before the point p and starting the segment
+// after it, whose normals (scaled to the half of the thickness) are n0 and n1, and appends the
+// triangles filling the join between them.
+func polylineJoin(tris []pixel.Vec, p, n0, n1 pixel.Vec, opts point) (in, out polylineEdge, _ []pixel.Vec) {
+	hw := n0.Len()
+
+	// side is 1 if the outer side of the join is on the left, -1 if it's on the right
+	side := 1.0
+	if n0.Cross(n1) > 0 {
+		side = -1
+	}
+	outerIn, outerOut := p.Add(n0.Scaled(side)), p.Add(n1.Scaled(side))
+	edge := func(outer, inner pixel.Vec) polylineEdge {
+		if side > 0 {
+			return polylineEdge{outer, inner}
+		}
+		return polylineEdge{inner, outer}
+	}
+
+	sum := n0.Add(n1)
+	cosHalf := 0.0
+	if sum.Len() > 0 {
+		cosHalf = sum.Unit().Dot(n0) / hw
+	}
+
+	if cosHalf*polylineMiterLimit < 1 {
+		// too sharp for a miter, the segments overlap on the inner side
+		in, out = polylineEdge{p.Add(n0), p.Sub(n0)}, polylineEdge{p.Add(n1), p.Sub(n1)}
+		return in, out, polylineFan(tris, p, p, outerIn, outerOut, opts)
+	}
+
+	miter := sum.Unit().Scaled(hw / cosHalf)
+	if opts.endshape == SharpEndShape || n0 == n1 {
+		e := polylineEdge{p.Add(miter), p.Sub(miter)}
+		return e, e, tris
+	}
+	inner := p.Sub(miter.Scaled(side))
+	return edge(outerIn, inner), edge(outerOut, inner), polylineFan(tris, p, inner, outerIn, outerOut, opts)
+}
+
+// polylineFan appends the triangles of a beveled (or rounded, depending on the EndShape) join
+// between a and b around the point p, fanned out from the vertex from.
+func polylineFan(tris []pixel.Vec, p, from, a, b pixel.Vec, opts point) []pixel.Vec {
+	if opts.endshape != RoundEndShape {
+		return append(tris, from, a, b)
+	}
+	low := p.To(a).Angle()
+	delta := p.To(b).Angle() - low
+	if delta > math.Pi {
+		delta -= 2 * math.Pi
+	} else if delta < -math.Pi {
+		delta += 2 * math.Pi
+	}
+	return polylineArc(tris, p, from, a, b, low, delta, opts.precision)
+}
+
+// polylineArc appends a fan of triangles from the vertex from to the arc around p from a to b,
+// starting at the angle low and turning by delta. The ends of the arc are exactly a and b.
+func polylineArc(tris []pixel.Vec, p, from, a, b pixel.Vec, low, delta float64, precision int) []pixel.Vec {
+	num := int(math.Ceil(math.Abs(delta) / (2 * math.Pi) * float64(precision)))
+	if num < 1 {
+		num = 1
+	}
+	r := p.To(a).Len()
+	prev := a
+	for i := 1; i <= num; i++ {
+		next := b
+		if i < num {
+			next = p.Add(pixel.Unit(low + delta*float64(i)/float64(num)).Scaled(r))
+		}
+		tris = append(tris, from, prev, next)
+		prev = next
+	}
+	return tris
+}
+
+// polylineCap appends the triangles of the end shape of an open Polyline at p, whose normal
+// (scaled to the half of the thickness) is nrm.
+func polylineCap(tris []pixel.Vec, p, nrm pixel.Vec, opts point, start bool) []pixel.Vec {
+	// back points out of the polyline
+	back := nrm.Normal()
+	if !start {
+		back = back.Scaled(-1)
+	}
+	switch opts.endshape {
+	case SharpEndShape:
+		tris = append(tris, p.Add(nrm), p.Sub(nrm), p.Add(back))
+	case RoundEndShape:
+		delta := math.Pi
+		if !start {
+			delta = -math.Pi
+		}
+		tris = polylineArc(tris, p, p, p.Add(nrm), p.Sub(nrm), nrm.Angle(), delta, opts.precision)
+	}
+	return tris
+}
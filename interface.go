@@ -123,6 +123,60 @@ type TargetPicture interface {
 	Draw(TargetTriangles)
 }
 
+// DebugTarget is an optional interface of Targets which can show the structure of the drawing in
+// graphics debuggers, such as RenderDoc or apitrace.
+//
+// Batch groups its draws onto a DebugTarget under its label, see Batch.SetLabel.
+type DebugTarget interface {
+	Target
+
+	// PushDebugGroup starts a named group of draws. Groups can be nested.
+	PushDebugGroup(name string)
+
+	// PopDebugGroup ends the group of draws started by the last PushDebugGroup.
+	PopDebugGroup()
+}
+
+// VolatileTarget specifies Target whose TargetTriangles and TargetPictures may become invalid, for
+// example when the graphics context gets lost and all the video memory is gone.
+//
+// Drawer (and thus Sprite and Batch) remembers the Generation of the Target when it makes the
+// TargetTriangles and TargetPictures. Whenever the Generation changes, they're all made again from
+// the Triangles and Pictures.
+type VolatileTarget interface {
+	Target
+
+	// Generation returns a number which changes whenever everything made by the Target becomes
+	// invalid.
+	Generation() uint64
+}
+
+// VolatilePicture specifies Picture whose content changes over time, such as a frame of a video or
+// a camera feed.
+//
+// Drawer (and thus Sprite and Batch) remembers the Version of the Picture when it makes a
+// TargetPicture from it. Whenever the Version changes, the TargetPicture is updated (see
+// UpdatableTargetPicture) or made again, so the new content gets drawn (e.g. uploaded to the video
+// memory) without calling Dirty.
+type VolatilePicture interface {
+	Picture
+
+	// Version returns a number which changes whenever the content of the Picture changes.
+	Version() uint64
+}
+
+// UpdatableTargetPicture is an optional interface of TargetPictures which can take the new content
+// of a VolatilePicture in place, e.g. by uploading the pixels into the existing texture, instead of
+// being made again with a new one.
+type UpdatableTargetPicture interface {
+	TargetPicture
+
+	// Update replaces the content of the TargetPicture with the current content of the Picture it
+	// was made from. It returns false if it can't, for example because the Bounds of the Picture
+	// changed, and the TargetPicture is made again then.
+	Update(Picture) bool
+}
+
 // PictureColor specifies Picture with Color property, so that every position inside the Picture's
 // Bounds has a color.
 //
@@ -0,0 +1,69 @@
+package pixel
+
+import "sort"
+
+// LayeredBatch is a set of Batches, one per layer, drawn in ascending order of the layers. It
+// makes it easy to keep the depth ordering of objects (e.g. ground tiles, objects and their
+// shadows, UI) without sorting them manually.
+//
+//   lb := pixel.NewLayeredBatch(&pixel.TrianglesData{}, sheet)
+//   for _, obj := range objects {
+//       obj.sprite.Draw(lb.Add(obj.layer), obj.matrix)
+//   }
+//   lb.Draw(win)
+//
+// Objects within one layer are drawn in the order they were drawn onto the layer.
+type LayeredBatch struct {
+	container Triangles
+	pic       Picture
+	layers    map[int]*Batch
+	order     []int
+}
+
+// NewLayeredBatch creates an empty LayeredBatch with the specified Picture. Each layer gets its own
+// copy of the container, see NewBatch.
+func NewLayeredBatch(container Triangles, pic Picture) *LayeredBatch {
+	return &LayeredBatch{
+		container: container,
+		pic:       pic,
+		layers:    make(map[int]*Batch),
+	}
+}
+
+// Add returns the Batch of the given layer, creating it if it doesn't exist yet. Draw onto it to
+// put objects into the layer.
+func (lb *LayeredBatch) Add(layer int) *Batch {
+	b, ok := lb.layers[layer]
+	if !ok {
+		container := lb.container.Copy()
+		container.SetLen(0)
+		b = NewBatch(container, lb.pic)
+		lb.layers[layer] = b
+
+		i := sort.SearchInts(lb.order, layer)
+		lb.order = append(lb.order, 0)
+		copy(lb.order[i+1:], lb.order[i:])
+		lb.order[i] = layer
+	}
+	return b
+}
+
+// Layers returns the layers currently in the LayeredBatch in ascending order.
+func (lb *LayeredBatch) Layers() []int {
+	return append([]int(nil), lb.order...)
+}
+
+// Clear removes all objects from all layers of the LayeredBatch. The layers are kept, so their
+// Batches can be reused.
+func (lb *LayeredBatch) Clear() {
+	for _, b := range lb.layers {
+		b.Clear()
+	}
+}
+
+// Draw draws all layers onto another Target in ascending order.
+func (lb *LayeredBatch) Draw(t Target) {
+	for _, layer := range lb.order {
+		lb.layers[layer].Draw(t)
+	}
+}
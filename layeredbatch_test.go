@@ -0,0 +1,43 @@
+package pixel_test
+
+import (
+	"image"
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+func TestLayeredBatch(t *testing.T) {
+	pic := pixel.PictureDataFromImage(image.NewRGBA(image.Rect(0, 0, 8, 8)))
+	sprite := pixel.NewSprite(pic, pic.Bounds())
+	lb := pixel.NewLayeredBatch(&pixel.TrianglesData{}, pic)
+
+	for _, layer := range []int{3, -1, 3, 0, -1} {
+		sprite.Draw(lb.Add(layer), pixel.IM.Moved(pixel.V(float64(layer), 0)))
+	}
+
+	if got, want := lb.Layers(), []int{-1, 0, 3}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("Layers() = %v, want %v", got, want)
+	}
+
+	tri := &pixel.TrianglesData{}
+	lb.Draw(recordingTarget{pixel.NewBatch(tri, nil)})
+
+	wantX := []float64{-1, -1, 0, 3, 3}
+	if tri.Len() != len(wantX)*6 {
+		t.Fatalf("drawn %d vertices, want %d", tri.Len(), len(wantX)*6)
+	}
+	for i, x := range wantX {
+		center := (*tri)[i*6].Position.Add((*tri)[i*6+2].Position).Scaled(0.5)
+		if center.X != x {
+			t.Errorf("sprite %d centered at %v, want X = %v", i, center, x)
+		}
+	}
+
+	lb.Clear()
+	tri.SetLen(0)
+	lb.Draw(recordingTarget{pixel.NewBatch(tri, nil)})
+	if tri.Len() != 0 {
+		t.Errorf("drawn %d vertices after Clear, want 0", tri.Len())
+	}
+}
@@ -1,6 +1,8 @@
 package pixel_test
 
 import (
+	"encoding/json"
+	"math"
 	"math/rand"
 	"testing"
 
@@ -61,3 +63,182 @@ func BenchmarkMatrix(b *testing.B) {
 		}
 	})
 }
+
+func TestMatrixJSON(t *testing.T) {
+	type config struct {
+		Name   string
+		Camera pixel.Matrix
+		Pos    pixel.Vec
+		Area   pixel.Rect
+	}
+
+	in := config{
+		Name:   "level1",
+		Camera: pixel.IM.Rotated(pixel.ZV, 0.5).Scaled(pixel.ZV, 2).Moved(pixel.V(10, -3.25)),
+		Pos:    pixel.V(1, 2),
+		Area:   pixel.R(0, 0, 640, 480),
+	}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var flat struct{ Camera []float64 }
+	if err := json.Unmarshal(data, &flat); err != nil {
+		t.Fatal(err)
+	}
+	if len(flat.Camera) != 6 {
+		t.Errorf("matrix encoded as %s, want a flat array of 6 numbers", data)
+	}
+
+	var out config
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out != in {
+		t.Errorf("round-trip got %+v, want %+v", out, in)
+	}
+
+	var m pixel.Matrix
+	if err := json.Unmarshal([]byte("[1, 0, 0, 1]"), &m); err == nil {
+		t.Errorf("expected an error for a matrix with 4 elements")
+	}
+}
+
+func TestMatrixProjectSlice(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var m pixel.Matrix
+	for i := range m {
+		m[i] = rng.NormFloat64() * 100
+	}
+	src := make([]pixel.Vec, 1000)
+	td := pixel.MakeTrianglesData(len(src))
+	for i := range src {
+		src[i] = pixel.V(rng.NormFloat64()*1000, rng.NormFloat64()*1000)
+		(*td)[i].Position = src[i]
+	}
+
+	dst := make([]pixel.Vec, len(src))
+	m.ProjectSlice(dst, src)
+	m.ProjectTrianglesData(td)
+	for i := range src {
+		want := m.Project(src[i])
+		if dst[i] != want {
+			t.Fatalf("ProjectSlice: vector %d projected to %v, want exactly %v", i, dst[i], want)
+		}
+		if got := td.Position(i); got != want {
+			t.Fatalf("ProjectTrianglesData: vector %d projected to %v, want exactly %v", i, got, want)
+		}
+	}
+
+	// in place
+	m.ProjectSlice(src, src)
+	for i := range src {
+		if src[i] != dst[i] {
+			t.Fatalf("ProjectSlice in place: vector %d projected to %v, want exactly %v", i, src[i], dst[i])
+		}
+	}
+}
+
+func BenchmarkMatrixProjectSlice(b *testing.B) {
+	m := pixel.IM.Rotated(pixel.ZV, 0.3).Scaled(pixel.ZV, 1.5).Moved(pixel.V(12, -7))
+	src := make([]pixel.Vec, 100000)
+	for i := range src {
+		src[i] = pixel.V(float64(i), float64(-i))
+	}
+	dst := make([]pixel.Vec, len(src))
+	td := pixel.MakeTrianglesData(len(src))
+
+	b.Run("Project", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for j := range src {
+				dst[j] = m.Project(src[j])
+			}
+		}
+	})
+	b.Run("ProjectSlice", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			m.ProjectSlice(dst, src)
+		}
+	})
+	b.Run("ProjectTrianglesData", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			m.ProjectTrianglesData(td)
+		}
+	})
+}
+
+func TestMatrixProjectRect(t *testing.T) {
+	eq := func(a, b pixel.Rect) bool {
+		return math.Abs(a.Min.X-b.Min.X) < 1e-9 && math.Abs(a.Min.Y-b.Min.Y) < 1e-9 &&
+			math.Abs(a.Max.X-b.Max.X) < 1e-9 && math.Abs(a.Max.Y-b.Max.Y) < 1e-9
+	}
+
+	r := pixel.R(-1, -1, 1, 1)
+	h := math.Sqrt2
+	tests := []struct {
+		m    pixel.Matrix
+		want pixel.Rect
+	}{
+		{pixel.IM, r},
+		{pixel.IM.Moved(pixel.V(3, 4)), pixel.R(2, 3, 4, 5)},
+		{pixel.IM.ScaledXY(pixel.ZV, pixel.V(-2, 3)), pixel.R(-2, -3, 2, 3)},
+		{pixel.IM.Rotated(pixel.ZV, math.Pi/4), pixel.R(-h, -h, h, h)},
+		{pixel.IM.Rotated(pixel.ZV, math.Pi/4).Moved(pixel.V(10, 0)), pixel.R(10-h, -h, 10+h, h)},
+	}
+	for _, tt := range tests {
+		if got := tt.m.ProjectRect(r); !eq(got, tt.want) {
+			t.Errorf("%v.ProjectRect(%v) = %v, want %v", tt.m, r, got, tt.want)
+		}
+	}
+}
+
+func TestMatrixShearedXY(t *testing.T) {
+	square := []pixel.Vec{pixel.V(0, 0), pixel.V(1, 0), pixel.V(1, 1), pixel.V(0, 1)}
+	for _, tt := range []struct {
+		name string
+		m    pixel.Matrix
+		want []pixel.Vec
+	}{
+		{"horizontal", pixel.IM.ShearedXY(pixel.ZV, pixel.V(0.5, 0)),
+			[]pixel.Vec{pixel.V(0, 0), pixel.V(1, 0), pixel.V(1.5, 1), pixel.V(0.5, 1)}},
+		{"vertical", pixel.IM.ShearedXY(pixel.ZV, pixel.V(0, -2)),
+			[]pixel.Vec{pixel.V(0, 0), pixel.V(1, -2), pixel.V(1, -1), pixel.V(0, 1)}},
+		{"around the center", pixel.IM.ShearedXY(pixel.V(0.5, 0.5), pixel.V(1, 0)),
+			[]pixel.Vec{pixel.V(-0.5, 0), pixel.V(0.5, 0), pixel.V(1.5, 1), pixel.V(0.5, 1)}},
+		{"chained", pixel.IM.Scaled(pixel.ZV, 2).ShearedXY(pixel.ZV, pixel.V(0.5, 0)).Moved(pixel.V(10, 20)),
+			[]pixel.Vec{pixel.V(10, 20), pixel.V(12, 20), pixel.V(13, 22), pixel.V(11, 22)}},
+	} {
+		for i, v := range square {
+			if got := tt.m.Project(v); !got.Eq(tt.want[i]) {
+				t.Errorf("%s: Project(%v) = %v, want %v", tt.name, v, got, tt.want[i])
+			}
+			if got := tt.m.Unproject(tt.want[i]); !got.Eq(v) {
+				t.Errorf("%s: Unproject(%v) = %v, want %v", tt.name, tt.want[i], got, v)
+			}
+		}
+	}
+}
+
+func TestMatrixUnproject(t *testing.T) {
+	matrices := []pixel.Matrix{
+		pixel.IM,
+		pixel.IM.Moved(pixel.V(-20, 35.5)),
+		pixel.IM.Rotated(pixel.V(3, 4), 1.2),
+		pixel.IM.Scaled(pixel.V(-1, 2), 0.25),
+		pixel.IM.ScaledXY(pixel.ZV, pixel.V(-3, 0.5)),
+		pixel.IM.Rotated(pixel.ZV, 0.5).ScaledXY(pixel.V(10, 10), pixel.V(2, -7)).Moved(pixel.V(100, -3)).Rotated(pixel.V(1, 1), -2),
+	}
+	for _, m := range matrices {
+		for _, v := range []pixel.Vec{pixel.ZV, pixel.V(1, 0), pixel.V(-13.5, 1e3), pixel.V(0.001, -0.002)} {
+			if got := m.Unproject(m.Project(v)); !got.EqEpsilon(v, 1e-9*(1+v.Len())) {
+				t.Errorf("%v.Unproject(Project(%v)) = %v", m, v, got)
+			}
+		}
+	}
+
+	singular := pixel.IM.ScaledXY(pixel.ZV, pixel.V(2, 0))
+	if got := singular.Unproject(pixel.V(1, 1)); !math.IsNaN(got.X) || !math.IsNaN(got.Y) {
+		t.Errorf("Unproject with a singular matrix = %v, want NaNs", got)
+	}
+}
@@ -0,0 +1,160 @@
+package pixel
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"unsafe"
+)
+
+// MemoryUsage is the memory used by a single object, see MemoryStats.
+type MemoryUsage struct {
+	// Category is the kind of the object, e.g. "Batch" or "texture".
+	Category string
+
+	// Name identifies the object, e.g. its label or its size.
+	Name string
+
+	Bytes int64
+}
+
+// MemoryStats returns the memory held by the Batches (the TrianglesData containers) and
+// DynamicAtlases which are currently alive, one MemoryUsage per object, sorted by the Bytes from
+// the largest. Use WriteMemoryStats to print them as a table. See pixelgl.MemoryStats for the video
+// memory.
+//
+// The objects are removed from the stats after they are garbage collected, so call runtime.GC
+// before MemoryStats to not count the unreachable ones. The memory of the Triangles and Pictures
+// owned by the user (other than TrianglesData containers of Batches) is not counted.
+func MemoryStats() []MemoryUsage {
+	return memoryStats.usages()
+}
+
+// WriteMemoryStats writes the usages (e.g. from MemoryStats) as a table with a row per object,
+// sorted by the Bytes from the largest, followed by the totals of the categories.
+func WriteMemoryStats(w io.Writer, usages []MemoryUsage) error {
+	usages = append([]MemoryUsage(nil), usages...)
+	sortMemoryUsages(usages)
+
+	type total struct {
+		category string
+		objects  int
+		bytes    int64
+	}
+	var totals []*total
+	byCategory := make(map[string]*total)
+	for _, u := range usages {
+		t, ok := byCategory[u.Category]
+		if !ok {
+			t = &total{category: u.Category}
+			byCategory[u.Category] = t
+			totals = append(totals, t)
+		}
+		t.objects++
+		t.bytes += u.Bytes
+	}
+	sort.SliceStable(totals, func(i, j int) bool { return totals[i].bytes > totals[j].bytes })
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "CATEGORY\tNAME\tBYTES\t\n")
+	for _, u := range usages {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t\n", u.Category, u.Name, u.Bytes)
+	}
+	fmt.Fprintf(tw, "\t\t\t\n")
+	fmt.Fprintf(tw, "TOTAL\tOBJECTS\tBYTES\t\n")
+	var all int64
+	for _, t := range totals {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t\n", t.category, t.objects, t.bytes)
+		all += t.bytes
+	}
+	fmt.Fprintf(tw, "all\t%d\t%d\t\n", len(usages), all)
+	return tw.Flush()
+}
+
+func sortMemoryUsages(usages []MemoryUsage) {
+	sort.SliceStable(usages, func(i, j int) bool {
+		if usages[i].Bytes != usages[j].Bytes {
+			return usages[i].Bytes > usages[j].Bytes
+		}
+		if usages[i].Category != usages[j].Category {
+			return usages[i].Category < usages[j].Category
+		}
+		return usages[i].Name < usages[j].Name
+	})
+}
+
+// memoryRegistry holds the MemoryUsages of the live objects. It doesn't reference the objects, so
+// that they can be garbage collected.
+type memoryRegistry struct {
+	mu     sync.Mutex
+	nextID uint64
+	byID   map[uint64]*MemoryUsage
+}
+
+var memoryStats memoryRegistry
+
+func (mr *memoryRegistry) usages() []MemoryUsage {
+	mr.mu.Lock()
+	usages := make([]MemoryUsage, 0, len(mr.byID))
+	for _, u := range mr.byID {
+		usages = append(usages, MemoryUsage{
+			Category: u.Category,
+			Name:     u.Name,
+			Bytes:    atomic.LoadInt64(&u.Bytes),
+		})
+	}
+	mr.mu.Unlock()
+	sortMemoryUsages(usages)
+	return usages
+}
+
+// memoryAccount is the entry of an object in the memoryRegistry. It's owned by the object and
+// removed from the registry when it's garbage collected along with the object.
+type memoryAccount struct {
+	id    uint64
+	usage *MemoryUsage
+}
+
+func newMemoryAccount(category, name string) *memoryAccount {
+	memoryStats.mu.Lock()
+	defer memoryStats.mu.Unlock()
+	if memoryStats.byID == nil {
+		memoryStats.byID = make(map[uint64]*MemoryUsage)
+	}
+	ma := &memoryAccount{
+		id:    memoryStats.nextID,
+		usage: &MemoryUsage{Category: category, Name: name},
+	}
+	memoryStats.nextID++
+	memoryStats.byID[ma.id] = ma.usage
+	runtime.SetFinalizer(ma, func(ma *memoryAccount) {
+		memoryStats.mu.Lock()
+		delete(memoryStats.byID, ma.id)
+		memoryStats.mu.Unlock()
+	})
+	return ma
+}
+
+func (ma *memoryAccount) setBytes(bytes int64) {
+	atomic.StoreInt64(&ma.usage.Bytes, bytes)
+}
+
+func (ma *memoryAccount) setName(name string) {
+	memoryStats.mu.Lock()
+	ma.usage.Name = name
+	memoryStats.mu.Unlock()
+}
+
+// trianglesDataBytes returns the memory held by the TrianglesData, or 0 if t is another type of
+// Triangles.
+func trianglesDataBytes(t Triangles) int64 {
+	td, ok := t.(*TrianglesData)
+	if !ok {
+		return 0
+	}
+	return int64(cap(*td)) * int64(unsafe.Sizeof((*td)[0]))
+}
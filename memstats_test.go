@@ -0,0 +1,77 @@
+package pixel_test
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/faiface/pixel"
+)
+
+// memoryOf returns the number of objects and the bytes in the MemoryStats with the name prefix.
+func memoryOf(prefix string) (objects int, bytes int64) {
+	for _, u := range pixel.MemoryStats() {
+		if strings.HasPrefix(u.Name, prefix) {
+			objects++
+			bytes += u.Bytes
+		}
+	}
+	return objects, bytes
+}
+
+func TestMemoryStats(t *testing.T) {
+	const vertexSize = 9 * 8 // position, color, picture and intensity
+
+	func() {
+		var batches []*pixel.Batch
+		for i := 0; i < 3; i++ {
+			b := pixel.NewBatch(pixel.MakeTrianglesData(0), nil)
+			b.SetLabel("memstats-test")
+			b.MakeTriangles(pixel.MakeTrianglesData(60)).Draw()
+			batches = append(batches, b)
+		}
+
+		objects, size := memoryOf("memstats-test")
+		if objects != 3 {
+			t.Errorf("got %d Batches, want 3", objects)
+		}
+		if size < 3*60*vertexSize {
+			t.Errorf("got %d bytes for the Batches, want at least %d", size, 3*60*vertexSize)
+		}
+
+		atlas := pixel.NewDynamicAtlas(37, 41)
+		atlas.Insert(pixel.MakePictureData(pixel.R(0, 0, 10, 10)))
+		atlas.Commit()
+		if objects, size := memoryOf("37x41 "); objects != 1 || size != (37*41+10*10)*4 {
+			t.Errorf("got %d DynamicAtlases with %d bytes, want 1 with %d", objects, size, (37*41+10*10)*4)
+		}
+		runtime.KeepAlive(atlas)
+
+		var buf bytes.Buffer
+		if err := pixel.WriteMemoryStats(&buf, pixel.MemoryStats()); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buf.String(), "memstats-test") || !strings.Contains(buf.String(), "TOTAL") {
+			t.Errorf("the table is missing the Batches:\n%s", buf.String())
+		}
+
+		runtime.KeepAlive(batches)
+	}()
+
+	// the objects are unreachable now, the stats must return to the baseline
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		runtime.GC()
+		objects, size := memoryOf("memstats-test")
+		atlases, atlasSize := memoryOf("37x41 ")
+		if objects+atlases == 0 && size+atlasSize == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d objects with %d bytes still in the stats after garbage collection", objects+atlases, size+atlasSize)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,158 @@
+package pixel
+
+import (
+	"image/color"
+	"math"
+)
+
+// DrawNineSlice draws the Picture onto the Target stretched to fill the dst rectangle using
+// 9-slice scaling.
+//
+// The center rectangle is specified in the Picture's coordinates and splits the Picture into nine
+// parts: four corners, four edges and the center. The corners are drawn unscaled, the edges are
+// stretched along one axis and the center is stretched along both axes, so borders of resizable UI
+// panels keep their look regardless of the panel's size:
+//
+//   pixel.DrawNineSlice(win, panelPic, pixel.R(8, 8, 56, 56), pixel.R(100, 100, 400, 250))
+//
+// If dst is too small to fit the corners, the corners are scaled down to fit.
+//
+// DrawNineSlice creates the geometry and asks the Target for a new TargetPicture on every call.
+// That is cheap when drawing onto a Batch, which is what the one-off functions are meant for. To
+// draw a panel directly onto a Window or a Canvas each frame, use a NineSlice, which keeps them.
+func DrawNineSlice(t Target, pic Picture, center Rect, dst Rect) {
+	DrawNineSliceFrame(t, pic, pic.Bounds(), center, dst)
+}
+
+// DrawNineSliceFrame is the same as DrawNineSlice, but uses only the frame of the Picture, so that
+// many 9-slice images (such as the parts of a UI skin) can share one Picture. The center rectangle
+// must be within the frame.
+func DrawNineSliceFrame(t Target, pic Picture, frame, center, dst Rect) {
+	td := MakeTrianglesData(9 * 6)
+	nineSliceData(td, frame, center, dst)
+	d := Drawer{Triangles: td, Picture: pic}
+	d.Draw(t)
+}
+
+// NineSlice is a reusable 9-slice image, see DrawNineSlice. It's to DrawNineSliceFrame what Sprite
+// is to DrawPicture: the Targets it's drawn onto make the Triangles and the Picture only once and
+// the geometry is recalculated only when the destination rectangle or the color mask change.
+//
+//   panel := pixel.NewNineSlice(skin, pixel.R(0, 0, 64, 64), pixel.R(8, 8, 56, 56))
+//   panel.Draw(win, pixel.R(100, 100, 400, 250))
+//
+// Like Sprite, NineSlice caches the results of MakePicture for each Picture it's set to.
+type NineSlice struct {
+	tri    *TrianglesData
+	frame  Rect
+	center Rect
+	dst    Rect
+	mask   RGBA
+	d      Drawer
+}
+
+// NewNineSlice creates a NineSlice of the frame of the Picture split by the center rectangle, see
+// DrawNineSliceFrame.
+func NewNineSlice(pic Picture, frame, center Rect) *NineSlice {
+	tri := MakeTrianglesData(9 * 6)
+	ns := &NineSlice{
+		tri:  tri,
+		mask: Alpha(1),
+		d:    Drawer{Triangles: tri},
+	}
+	ns.Set(pic, frame, center)
+	return ns
+}
+
+// Set sets a new Picture, frame and center rectangle of the NineSlice.
+func (ns *NineSlice) Set(pic Picture, frame, center Rect) {
+	ns.d.Picture = pic
+	if frame != ns.frame || center != ns.center {
+		ns.frame, ns.center = frame, center
+		nineSliceData(ns.tri, ns.frame, ns.center, ns.dst)
+		ns.d.Dirty()
+	}
+}
+
+// Picture returns the Picture of the NineSlice.
+func (ns *NineSlice) Picture() Picture {
+	return ns.d.Picture
+}
+
+// Frame returns the frame of the Picture used by the NineSlice.
+func (ns *NineSlice) Frame() Rect {
+	return ns.frame
+}
+
+// Center returns the center rectangle of the NineSlice.
+func (ns *NineSlice) Center() Rect {
+	return ns.center
+}
+
+// Draw draws the NineSlice onto the Target stretched to fill the dst rectangle.
+//
+// This method is equivalent to calling DrawColorMask with nil color mask.
+func (ns *NineSlice) Draw(t Target, dst Rect) {
+	ns.DrawColorMask(t, dst, nil)
+}
+
+// DrawColorMask draws the NineSlice onto the Target stretched to fill the dst rectangle, with all
+// of its color multiplied by the given mask.
+//
+// If the mask is nil, a fully opaque white mask will be used, which causes no effect.
+func (ns *NineSlice) DrawColorMask(t Target, dst Rect, mask color.Color) {
+	if mask == nil {
+		mask = Alpha(1)
+	}
+	rgba := ToRGBA(mask)
+	if dst != ns.dst {
+		ns.dst = dst
+		nineSliceData(ns.tri, ns.frame, ns.center, ns.dst)
+		ns.d.Dirty()
+	}
+	if rgba != ns.mask {
+		ns.mask = rgba
+		for i := range *ns.tri {
+			(*ns.tri)[i].Color = rgba
+		}
+		ns.d.Dirty()
+	}
+	ns.d.Draw(t)
+}
+
+// nineSliceData fills td (which must be of length 54) with the 9-slice geometry of the src frame.
+func nineSliceData(td *TrianglesData, src, center, dst Rect) {
+	sx := [4]float64{src.Min.X, center.Min.X, center.Max.X, src.Max.X}
+	sy := [4]float64{src.Min.Y, center.Min.Y, center.Max.Y, src.Max.Y}
+	dx := nineSliceSplit(sx, dst.Min.X, dst.Max.X)
+	dy := nineSliceSplit(sy, dst.Min.Y, dst.Max.Y)
+
+	k := 0
+	for j := 0; j < 3; j++ {
+		for i := 0; i < 3; i++ {
+			pos := [...]Vec{
+				V(dx[i], dy[j]), V(dx[i+1], dy[j]), V(dx[i+1], dy[j+1]), V(dx[i], dy[j+1]),
+			}
+			pic := [...]Vec{
+				V(sx[i], sy[j]), V(sx[i+1], sy[j]), V(sx[i+1], sy[j+1]), V(sx[i], sy[j+1]),
+			}
+			for _, v := range [...]int{0, 1, 2, 0, 2, 3} {
+				(*td)[k].Position = pos[v]
+				(*td)[k].Picture = pic[v]
+				(*td)[k].Intensity = 1
+				k++
+			}
+		}
+	}
+}
+
+// nineSliceSplit maps the source split coordinates onto the [min, max] destination interval,
+// keeping the size of the borders and scaling them down if they don't fit.
+func nineSliceSplit(s [4]float64, min, max float64) [4]float64 {
+	low, high := s[1]-s[0], s[3]-s[2]
+	scale := 1.0
+	if low+high > 0 {
+		scale = math.Min(1, (max-min)/(low+high))
+	}
+	return [4]float64{min, min + low*scale, max - high*scale, max}
+}
@@ -0,0 +1,86 @@
+package pixel_test
+
+import (
+	"image"
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+func TestDrawNineSlice(t *testing.T) {
+	pic := pixel.PictureDataFromImage(image.NewRGBA(image.Rect(0, 0, 30, 30)))
+	tri := &pixel.TrianglesData{}
+	batch := pixel.NewBatch(tri, pic)
+
+	pixel.DrawNineSlice(batch, pic, pixel.R(10, 10, 20, 20), pixel.R(100, 100, 200, 150))
+
+	if tri.Len() != 9*6 {
+		t.Fatalf("got %d vertices, want %d", tri.Len(), 9*6)
+	}
+
+	// bottom-left corner keeps its size
+	for i := 0; i < 6; i++ {
+		pos := tri.Position(i)
+		if pos.X != 100 && pos.X != 110 || pos.Y != 100 && pos.Y != 110 {
+			t.Errorf("corner vertex %d at %v, want within Rect(100, 100, 110, 110)", i, pos)
+		}
+	}
+
+	// center is stretched to fill the rest
+	for i := 4 * 6; i < 5*6; i++ {
+		pos := tri.Position(i)
+		if pos.X != 110 && pos.X != 190 || pos.Y != 110 && pos.Y != 140 {
+			t.Errorf("center vertex %d at %v, want within Rect(110, 110, 190, 140)", i, pos)
+		}
+		pic, _ := tri.Picture(i)
+		if pic.X != 10 && pic.X != 20 || pic.Y != 10 && pic.Y != 20 {
+			t.Errorf("center vertex %d picture %v, want within Rect(10, 10, 20, 20)", i, pic)
+		}
+	}
+}
+
+func TestDrawNineSliceSmall(t *testing.T) {
+	pic := pixel.PictureDataFromImage(image.NewRGBA(image.Rect(0, 0, 30, 30)))
+	tri := &pixel.TrianglesData{}
+	batch := pixel.NewBatch(tri, pic)
+
+	// too small to fit the 10 unit borders, they get halved
+	pixel.DrawNineSlice(batch, pic, pixel.R(10, 10, 20, 20), pixel.R(0, 0, 10, 10))
+
+	for i := 0; i < tri.Len(); i++ {
+		pos := tri.Position(i)
+		for _, c := range []float64{pos.X, pos.Y} {
+			if c != 0 && c != 5 && c != 10 {
+				t.Fatalf("vertex %d at %v, want coordinates in {0, 5, 10}", i, pos)
+			}
+		}
+	}
+}
+
+func TestNineSlice(t *testing.T) {
+	pic := pixel.PictureDataFromImage(image.NewRGBA(image.Rect(0, 0, 30, 30)))
+	ns := pixel.NewNineSlice(pic, pic.Bounds(), pixel.R(10, 10, 20, 20))
+	target := &nopTarget{}
+
+	for i := 0; i < 3; i++ {
+		ns.Draw(target, pixel.R(100, 100, 200, 150))
+	}
+	if target.pics != 1 {
+		t.Errorf("made %d pictures, want 1", target.pics)
+	}
+	if pos := target.tris.Position(2); pos != pixel.V(110, 110) {
+		t.Errorf("corner vertex at %v, want (110, 110)", pos)
+	}
+
+	// a new rectangle and mask update the triangles already made by the Target
+	ns.DrawColorMask(target, pixel.R(0, 0, 50, 50), pixel.RGB(1, 0, 0))
+	if pos := target.tris.Position(2); pos != pixel.V(10, 10) {
+		t.Errorf("corner vertex at %v after moving, want (10, 10)", pos)
+	}
+	if c := target.tris.Color(0); c != pixel.RGB(1, 0, 0) {
+		t.Errorf("vertex color %v, want %v", c, pixel.RGB(1, 0, 0))
+	}
+	if target.pics != 1 {
+		t.Errorf("made %d pictures, want 1", target.pics)
+	}
+}
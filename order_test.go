@@ -0,0 +1,130 @@
+package pixel_test
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"image"
+	"math"
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+// checksum returns a hash of all vertex properties of the TrianglesData.
+func checksum(td *pixel.TrianglesData) uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	for _, v := range *td {
+		for _, f := range []float64{
+			v.Position.X, v.Position.Y,
+			v.Color.R, v.Color.G, v.Color.B, v.Color.A,
+			v.Picture.X, v.Picture.Y, v.Intensity,
+		} {
+			binary.LittleEndian.PutUint64(buf[:], math.Float64bits(f))
+			h.Write(buf[:])
+		}
+	}
+	return h.Sum64()
+}
+
+func TestBatchAppendOrder(t *testing.T) {
+	pic := pixel.PictureDataFromImage(image.NewRGBA(image.Rect(0, 0, 8, 8)))
+	sprite := pixel.NewSprite(pic, pic.Bounds())
+	tri := &pixel.TrianglesData{}
+	batch := pixel.NewBatch(tri, pic)
+
+	for i := 0; i < 50; i++ {
+		sprite.Draw(batch, pixel.IM.Moved(pixel.V(float64(i*10), 0)))
+	}
+
+	for i := 0; i < 50; i++ {
+		center := pixel.ZV
+		for _, v := range (*tri)[i*6 : i*6+6] {
+			center = center.Add(v.Position.Scaled(1.0 / 6))
+		}
+		if want := pixel.V(float64(i*10), 0); center.Sub(want).Len() > 1e-9 {
+			t.Fatalf("sprite %d centered at %v, want %v", i, center, want)
+		}
+	}
+}
+
+func TestDrawerTargetOrder(t *testing.T) {
+	tri := pixel.MakeTrianglesData(6)
+	for i := range *tri {
+		(*tri)[i].Position = pixel.V(float64(i), 0)
+	}
+
+	a1, b1 := &nopTarget{}, &nopTarget{}
+	a2, b2 := &nopTarget{}, &nopTarget{}
+	d1 := pixel.Drawer{Triangles: tri}
+	d2 := pixel.Drawer{Triangles: tri}
+
+	d1.Draw(a1)
+	d1.Draw(b1)
+	d2.Draw(b2)
+	d2.Draw(a2)
+
+	(*tri)[3].Position = pixel.V(-1, -1)
+	d1.Dirty()
+	d2.Dirty()
+
+	d1.Draw(b1)
+	d1.Draw(a1)
+	d2.Draw(a2)
+	d2.Draw(b2)
+
+	want := checksum(tri)
+	for i, target := range []*nopTarget{a1, b1, a2, b2} {
+		if got := checksum(target.tris); got != want {
+			t.Errorf("target %d has checksum %x, want %x", i, got, want)
+		}
+	}
+}
+
+func TestBatchDeterministic(t *testing.T) {
+	pics := make([]*pixel.PictureData, 3)
+	for i := range pics {
+		pics[i] = pixel.PictureDataFromImage(image.NewRGBA(image.Rect(0, 0, 8*(i+1), 8)))
+	}
+
+	scene := func() uint64 {
+		ab := pixel.NewAutoBatcher()
+		for i := 0; i < 30; i++ {
+			pic := pics[i%len(pics)]
+			sprite := pixel.NewSprite(pic, pic.Bounds())
+			ab.Draw(sprite, pixel.IM.Rotated(pixel.ZV, float64(i)).Moved(pixel.V(float64(i), 0)), pixel.Alpha(0.5))
+		}
+		tri := &pixel.TrianglesData{}
+		target := pixel.NewBatch(tri, nil)
+		ab.Flush(recordingTarget{target})
+		return checksum(tri)
+	}
+
+	want := scene()
+	for i := 0; i < 100; i++ {
+		if got := scene(); got != want {
+			t.Fatalf("scene %d has checksum %x, want %x", i, got, want)
+		}
+	}
+}
+
+// recordingTarget records all triangles drawn onto it into a Batch, ignoring the Pictures.
+type recordingTarget struct {
+	batch *pixel.Batch
+}
+
+func (rt recordingTarget) MakeTriangles(t pixel.Triangles) pixel.TargetTriangles {
+	return rt.batch.MakeTriangles(t)
+}
+
+func (rt recordingTarget) MakePicture(p pixel.Picture) pixel.TargetPicture {
+	return recordingPicture{p}
+}
+
+type recordingPicture struct {
+	pixel.Picture
+}
+
+func (recordingPicture) Draw(t pixel.TargetTriangles) {
+	t.Draw()
+}
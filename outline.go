@@ -0,0 +1,158 @@
+package pixel
+
+import "math"
+
+// TraceOutline traces the outline of the opaque region within the frame of the PictureData and
+// returns one ring of vertices for each boundary, e.g. for selection highlights or for
+// approximating a sprite for collisions.
+//
+// A pixel is opaque if its alpha is above alphaThreshold (in the range [0, 1]). The outlines are
+// traced with marching squares through the pixel centers, so they run along the pixel edges and cut
+// the corners diagonally. Diagonally touching pixels are connected. Then they are simplified with
+// the Douglas-Peucker algorithm, keeping them within simplifyTolerance of the traced outline. A
+// tolerance of 0 only removes the vertices on straight lines.
+//
+// Each connected opaque region gets a ring in counter-clockwise order, each hole in it a separate
+// ring in clockwise order. The vertices are in the coordinates of the PictureData, the pixels
+// outside of the frame are treated as transparent, so the outlines of regions touching the frame
+// are closed along it. A fully transparent frame has no outlines.
+func TraceOutline(pd *PictureData, frame Rect, alphaThreshold, simplifyTolerance float64) [][]Vec {
+	frame = frame.Norm().Intersect(pd.Rect)
+	x0, y0 := math.Floor(frame.Min.X), math.Floor(frame.Min.Y)
+	w := int(math.Ceil(frame.Max.X) - x0)
+	h := int(math.Ceil(frame.Max.Y) - y0)
+	if w <= 0 || h <= 0 {
+		return nil
+	}
+
+	// samples with a transparent border, sample (i, j) is the center of pixel (i-1, j-1)
+	sw, sh := w+2, h+2
+	opaque := make([]bool, sw*sh)
+	threshold := uint8(Clamp(math.Floor(alphaThreshold*255), 0, 255))
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			at := V(x0+float64(i)+0.5, y0+float64(j)+0.5)
+			if frame.Contains(at) && pd.Pix[pd.Index(at)].A > threshold {
+				opaque[(j+1)*sw+i+1] = true
+			}
+		}
+	}
+
+	// The segments are keyed by the edge midpoints in doubled sample coordinates. Each segment
+	// has the opaque region on its left, so the outer boundaries come out counter-clockwise and
+	// the holes clockwise. Every midpoint starts exactly one segment and ends exactly one.
+	type key [2]int
+	next := make(map[key]key)
+	var starts []key
+
+	for j := 0; j < sh-1; j++ {
+		for i := 0; i < sw-1; i++ {
+			// corners and edges in counter-clockwise order, edge k goes from corner k to k+1
+			corners := [4]bool{
+				opaque[j*sw+i],
+				opaque[j*sw+i+1],
+				opaque[(j+1)*sw+i+1],
+				opaque[(j+1)*sw+i],
+			}
+			edges := [4]key{
+				{2*i + 1, 2 * j},
+				{2*i + 2, 2*j + 1},
+				{2*i + 1, 2*j + 2},
+				{2 * i, 2*j + 1},
+			}
+			// a segment goes from an edge exiting the opaque region to the next edge entering it
+			for k := 0; k < 4; k++ {
+				if !corners[k] || corners[(k+1)%4] {
+					continue
+				}
+				for l := k + 1; l < k+4; l++ {
+					if !corners[l%4] && corners[(l+1)%4] {
+						next[edges[k]] = edges[l%4]
+						starts = append(starts, edges[k])
+						break
+					}
+				}
+			}
+		}
+	}
+
+	var rings [][]Vec
+	for _, start := range starts {
+		if _, ok := next[start]; !ok {
+			continue // already traced
+		}
+		var ring []Vec
+		for k := start; ; {
+			ring = append(ring, V(x0+float64(k[0])/2-0.5, y0+float64(k[1])/2-0.5))
+			n := next[k]
+			delete(next, k)
+			if n == start {
+				break
+			}
+			k = n
+		}
+		if ring = simplifyRing(ring, simplifyTolerance); len(ring) >= 3 {
+			rings = append(rings, ring)
+		}
+	}
+	return rings
+}
+
+// simplifyRing simplifies a closed ring with the Douglas-Peucker algorithm.
+func simplifyRing(ring []Vec, tolerance float64) []Vec {
+	if len(ring) < 3 {
+		return ring
+	}
+	// split the ring at its first vertex and the vertex farthest from it
+	far, farDist := 0, -1.0
+	for i := range ring {
+		if d := ring[0].To(ring[i]).Len(); d > farDist {
+			far, farDist = i, d
+		}
+	}
+	closed := append(append([]Vec(nil), ring...), ring[0])
+
+	keep := make([]bool, len(closed))
+	keep[0], keep[far], keep[len(closed)-1] = true, true, true
+	douglasPeucker(closed, 0, far, tolerance, keep)
+	douglasPeucker(closed, far, len(closed)-1, tolerance, keep)
+
+	var simplified []Vec
+	for i := 0; i < len(closed)-1; i++ {
+		if keep[i] {
+			simplified = append(simplified, closed[i])
+		}
+	}
+
+	// the first vertex is always kept, but it may lie on a straight line
+	if n := len(simplified); n >= 4 && segmentDistance(simplified[0], simplified[n-1], simplified[1]) <= tolerance {
+		simplified = simplified[1:]
+	}
+	return simplified
+}
+
+// douglasPeucker marks the vertices of points between i and j to keep.
+func douglasPeucker(points []Vec, i, j int, tolerance float64, keep []bool) {
+	far, farDist := -1, tolerance
+	for k := i + 1; k < j; k++ {
+		if d := segmentDistance(points[k], points[i], points[j]); d > farDist {
+			far, farDist = k, d
+		}
+	}
+	if far < 0 {
+		return
+	}
+	keep[far] = true
+	douglasPeucker(points, i, far, tolerance, keep)
+	douglasPeucker(points, far, j, tolerance, keep)
+}
+
+// segmentDistance returns the distance of the point u from the line segment between a and b.
+func segmentDistance(u, a, b Vec) float64 {
+	ab := a.To(b)
+	t := 0.0
+	if l := ab.Dot(ab); l > 0 {
+		t = Clamp(a.To(u).Dot(ab)/l, 0, 1)
+	}
+	return a.Add(ab.Scaled(t)).To(u).Len()
+}
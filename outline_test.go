@@ -0,0 +1,88 @@
+package pixel_test
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+// fillPicture sets the pixels of the PictureData for which f returns true to opaque white.
+func fillPicture(pd *pixel.PictureData, f func(x, y float64) bool) {
+	for i := range pd.Pix {
+		x := pd.Rect.Min.X + float64(i%pd.Stride) + 0.5
+		y := pd.Rect.Min.Y + float64(i/pd.Stride) + 0.5
+		if f(x, y) {
+			pd.Pix[i] = color.RGBA{255, 255, 255, 255}
+		}
+	}
+}
+
+func ringArea(ring []pixel.Vec) float64 {
+	area := 0.0
+	for i := range ring {
+		area += ring[i].Cross(ring[(i+1)%len(ring)])
+	}
+	return area / 2
+}
+
+func TestTraceOutline(t *testing.T) {
+	square := func(x, y float64) bool { return x > 5 && x < 15 && y > 5 && y < 15 }
+	hole := func(x, y float64) bool { return x > 8 && x < 12 && y > 8 && y < 12 }
+
+	for _, tt := range []struct {
+		name      string
+		f         func(x, y float64) bool
+		frame     pixel.Rect
+		tolerance float64
+		vertices  []int
+		areas     []float64
+	}{
+		// the corners are cut diagonally through the pixel centers, removing 4 * 1/8 pixels
+		{"square", square, pixel.R(0, 0, 20, 20), 0, []int{8}, []float64{99.5}},
+		{"square with a hole", func(x, y float64) bool { return square(x, y) && !hole(x, y) },
+			pixel.R(0, 0, 20, 20), 0, []int{8, 8}, []float64{99.5, -15.5}},
+		{"touching the edges", func(x, y float64) bool { return true }, pixel.R(0, 0, 20, 20), 0, []int{8}, []float64{399.5}},
+		{"clipped by the frame", square, pixel.R(10, 0, 20, 10), 0, []int{8}, []float64{24.5}},
+		{"transparent", func(x, y float64) bool { return false }, pixel.R(0, 0, 20, 20), 0, nil, nil},
+		{"transparent frame", square, pixel.R(0, 0, 5, 5), 0, nil, nil},
+	} {
+		pd := pixel.MakePictureData(pixel.R(0, 0, 20, 20))
+		fillPicture(pd, tt.f)
+		rings := pixel.TraceOutline(pd, tt.frame, 0.5, tt.tolerance)
+
+		if len(rings) != len(tt.vertices) {
+			t.Errorf("%s: got %d rings, want %d", tt.name, len(rings), len(tt.vertices))
+			continue
+		}
+		for i, ring := range rings {
+			if len(ring) != tt.vertices[i] {
+				t.Errorf("%s: ring %d has %d vertices, want %d: %v", tt.name, i, len(ring), tt.vertices[i], ring)
+			}
+			if got := ringArea(ring); math.Abs(got-tt.areas[i]) > 1e-9 {
+				t.Errorf("%s: ring %d has area %v, want %v", tt.name, i, got, tt.areas[i])
+			}
+		}
+	}
+}
+
+func TestTraceOutlineSimplify(t *testing.T) {
+	const r = 20
+	pd := pixel.MakePictureData(pixel.R(-30, -30, 30, 30))
+	fillPicture(pd, func(x, y float64) bool { return math.Hypot(x, y) < r })
+
+	exact := pixel.TraceOutline(pd, pd.Rect, 0.5, 0)
+	simple := pixel.TraceOutline(pd, pd.Rect, 0.5, 1)
+	if len(exact) != 1 || len(simple) != 1 {
+		t.Fatalf("got %d and %d rings, want 1", len(exact), len(simple))
+	}
+	if len(simple[0]) >= len(exact[0])/2 {
+		t.Errorf("simplified to %d vertices from %d, want less than half", len(simple[0]), len(exact[0]))
+	}
+	for _, ring := range [][]pixel.Vec{exact[0], simple[0]} {
+		if area := ringArea(ring); math.Abs(area-math.Pi*r*r) > 0.03*math.Pi*r*r {
+			t.Errorf("%d vertices: area %v, want within 3%% of %v", len(ring), area, math.Pi*r*r)
+		}
+	}
+}
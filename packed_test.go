@@ -0,0 +1,114 @@
+package pixel_test
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/faiface/pixel"
+)
+
+func TestPackRGBA(t *testing.T) {
+	// every 8-bit value survives packing and unpacking exactly, in every component
+	for k := 0; k < 256; k++ {
+		v := float64(k) / 255
+		for i, c := range []pixel.RGBA{{R: v}, {G: v}, {B: v}, {A: v}} {
+			if got := pixel.PackRGBA(c).Unpack(); got != c {
+				t.Fatalf("component %d value %d: got %v, want %v", i, k, got, c)
+			}
+		}
+	}
+
+	for _, tt := range []struct {
+		c    pixel.RGBA
+		want pixel.RGBA8
+	}{
+		{pixel.RGBA{}, 0},
+		{pixel.Alpha(1), 0xffffffff},
+		{pixel.RGBA{R: 1, G: 0.5, B: 0.25, A: 1}, 0xff4080ff},
+		{pixel.RGBA{R: 2, G: -1, B: 0.499 / 255, A: 0.501 / 255}, 0x010000ff},
+	} {
+		if got := pixel.PackRGBA(tt.c); got != tt.want {
+			t.Errorf("PackRGBA(%v) = %#08x, want %#08x", tt.c, got, tt.want)
+		}
+	}
+}
+
+func TestPackedTrianglesData(t *testing.T) {
+	td := pixel.MakeTrianglesData(3)
+	for i := range *td {
+		(*td)[i].Position = pixel.V(float64(i), 1)
+		(*td)[i].Color = pixel.RGBA{R: float64(i) / 255, G: 0.2, B: 1, A: 1}
+		(*td)[i].Picture = pixel.V(2, float64(i))
+		(*td)[i].Intensity = 0.5
+	}
+
+	ptd := pixel.MakePackedTrianglesData(3)
+	ptd.Update(td)
+	cp := ptd.Copy().(*pixel.PackedTrianglesData)
+	for i := range *td {
+		want := (*td)[i]
+		if got := cp.Position(i); got != want.Position {
+			t.Errorf("vertex %d: position %v, want %v", i, got, want.Position)
+		}
+		if got := cp.Color(i); got != pixel.PackRGBA(want.Color).Unpack() || got.R != want.Color.R {
+			t.Errorf("vertex %d: color %v, want %v", i, got, want.Color)
+		}
+		if pic, intensity := cp.Picture(i); pic != want.Picture || intensity != want.Intensity {
+			t.Errorf("vertex %d: picture %v %v, want %v %v", i, pic, intensity, want.Picture, want.Intensity)
+		}
+	}
+
+	// back to TrianglesData through the generic path
+	back := pixel.MakeTrianglesData(3)
+	back.Update(cp)
+	if (*back)[2].Color.R != 2.0/255 {
+		t.Errorf("converted back to %v", (*back)[2].Color)
+	}
+
+	cp.SetColor(0, pixel.RGB(1, 0, 0))
+	if (*cp)[0].Color != 0xff0000ff || (*ptd)[0].Color == (*cp)[0].Color {
+		t.Errorf("SetColor: got %#08x in the copy, %#08x in the original", (*cp)[0].Color, (*ptd)[0].Color)
+	}
+}
+
+func BenchmarkTrianglesUpdate(b *testing.B) {
+	const n = 200000
+	src := pixel.MakeTrianglesData(n)
+	b.Run("TrianglesData", func(b *testing.B) {
+		dst := pixel.MakeTrianglesData(n)
+		b.SetBytes(n * int64(unsafe.Sizeof((*dst)[0])))
+		for i := 0; i < b.N; i++ {
+			dst.Update(src)
+		}
+	})
+	b.Run("PackedTrianglesData", func(b *testing.B) {
+		packed := pixel.MakePackedTrianglesData(n)
+		packed.Update(src)
+		dst := pixel.MakePackedTrianglesData(n)
+		b.SetBytes(n * int64(unsafe.Sizeof((*dst)[0])))
+		for i := 0; i < b.N; i++ {
+			dst.Update(packed)
+		}
+	})
+}
+
+func BenchmarkBatchDrawPacked(b *testing.B) {
+	tri := pixel.MakeTrianglesData(600)
+	packed := pixel.MakePackedTrianglesData(600)
+	for _, bc := range []struct {
+		name      string
+		tri, cont pixel.Triangles
+	}{
+		{"TrianglesData", tri, &pixel.TrianglesData{}},
+		{"PackedTrianglesData", packed, &pixel.PackedTrianglesData{}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			batch := pixel.NewBatch(bc.cont, nil)
+			d := pixel.Drawer{Triangles: bc.tri}
+			for i := 0; i < b.N; i++ {
+				batch.Clear()
+				d.Draw(batch)
+			}
+		})
+	}
+}
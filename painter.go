@@ -0,0 +1,204 @@
+package pixel
+
+import (
+	"image/color"
+	"math"
+)
+
+// Painter paints with soft round brushes, e.g. into the splat maps of a terrain editor.
+//
+// A Painter either paints into a PictureData on the CPU (see NewPainter), or draws the brush onto
+// a Target, such as a Canvas, on the GPU (see NewTargetPainter). Both produce the same brush
+// dabs, so the CPU mode can be used in headless tests and the GPU mode live. For the closest match,
+// enable smoothing on the Target (e.g. Canvas.SetSmooth), because the GPU mode draws the brush
+// from a texture.
+//
+// Connect the positions of the mouse with Stroke, so that fast strokes don't leave gaps:
+//
+//   if win.JustPressed(pixelgl.MouseButtonLeft) {
+//       painter.Paint(mouse, 12, 0.5, colornames.Green, pixel.ComposeOver)
+//   } else if win.Pressed(pixelgl.MouseButtonLeft) {
+//       painter.Stroke(lastMouse, mouse, 12, 0.5, colornames.Green, pixel.ComposeOver)
+//   }
+//   lastMouse = mouse
+//
+// In the CPU mode, Painter is a VolatilePicture, so drawing it (e.g. with a Sprite) always shows
+// the current painting.
+type Painter struct {
+	// Spacing is the distance between the dabs of a Stroke as a fraction of the brush radius.
+	// Zero means the default of 0.25.
+	Spacing float64
+
+	pd      *PictureData
+	version uint64
+	dirty   Rect
+	isDirty bool
+
+	target  Target
+	brushes map[float64]*Sprite
+
+	carry float64 // distance traveled by the current stroke since the last dab
+}
+
+// brushTextureRadius is the radius in pixels of the brush textures used in the GPU mode.
+const brushTextureRadius = 64
+
+// hardnessSteps is the number of distinct brush hardnesses, the hardness is rounded to them, so
+// that the GPU mode can cache a texture for each of them.
+const hardnessSteps = 32
+
+var _ VolatilePicture = (*Painter)(nil)
+
+// NewPainter creates a Painter painting into the PictureData on the CPU.
+func NewPainter(pd *PictureData) *Painter {
+	return &Painter{pd: pd}
+}
+
+// NewTargetPainter creates a Painter drawing onto the Target, e.g. a Canvas. The compose method is
+// only respected if the Target is a ComposeTarget. The compose method of the Target is changed by
+// painting.
+func NewTargetPainter(t Target) *Painter {
+	return &Painter{target: t, brushes: make(map[float64]*Sprite)}
+}
+
+// Paint paints a single dab of a round brush centered at the center.
+//
+// The brush is fully opaque within radius*hardness from the center and smoothly fades out towards
+// the radius. The brush color is composed with the painted colors using the compose method.
+func (p *Painter) Paint(center Vec, radius, hardness float64, c color.Color, cmp ComposeMethod) {
+	p.carry = 0
+	p.dab(center, radius, hardness, ToRGBA(c), cmp)
+}
+
+// Stroke paints dabs along the segment between from and to, spaced by Spacing. The from point is
+// assumed to be painted already (by Paint or the previous Stroke), so the dabs continue evenly
+// from the previous ones.
+func (p *Painter) Stroke(from, to Vec, radius, hardness float64, c color.Color, cmp ComposeMethod) {
+	spacing := p.Spacing
+	if spacing <= 0 {
+		spacing = 0.25
+	}
+	step := math.Max(spacing*radius, 0.5)
+
+	col := ToRGBA(c)
+	length := from.To(to).Len()
+	dist := step - p.carry
+	for ; dist <= length; dist += step {
+		p.dab(Lerp(from, to, dist/length), radius, hardness, col, cmp)
+	}
+	p.carry = length - (dist - step)
+}
+
+func (p *Painter) dab(center Vec, radius, hardness float64, c RGBA, cmp ComposeMethod) {
+	hardness = math.Floor(Clamp(hardness, 0, 1)*hardnessSteps+0.5) / hardnessSteps
+	if p.target != nil {
+		p.drawDab(center, radius, hardness, c, cmp)
+		return
+	}
+
+	area := R(center.X-radius, center.Y-radius, center.X+radius, center.Y+radius).Intersect(p.pd.Rect)
+	if area.Area() == 0 {
+		return
+	}
+	x0, y0 := math.Floor(area.Min.X), math.Floor(area.Min.Y)
+	x1, y1 := math.Ceil(area.Max.X), math.Ceil(area.Max.Y)
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			at := V(x+0.5, y+0.5)
+			cov := brushCoverage(at.To(center).Len()/radius, hardness)
+			if cov == 0 || !p.pd.Rect.Contains(at) {
+				continue
+			}
+			i := p.pd.Index(at)
+			dst := ToRGBA(p.pd.Pix[i])
+			p.pd.Pix[i] = toColorRGBA(cmp.Compose(c.Scaled(cov), dst))
+		}
+	}
+
+	dirty := R(x0, y0, x1, y1)
+	if p.isDirty {
+		dirty = p.dirty.Union(dirty)
+	}
+	p.dirty, p.isDirty = dirty, true
+	p.version++
+}
+
+func (p *Painter) drawDab(center Vec, radius, hardness float64, c RGBA, cmp ComposeMethod) {
+	brush := p.brushes[hardness]
+	if brush == nil {
+		pd := MakePictureData(R(0, 0, 2*brushTextureRadius, 2*brushTextureRadius))
+		for i := range pd.Pix {
+			at := V(float64(i%pd.Stride)+0.5, float64(i/pd.Stride)+0.5)
+			cov := brushCoverage(at.To(pd.Rect.Center()).Len()/brushTextureRadius, hardness)
+			pd.Pix[i] = toColorRGBA(Alpha(cov))
+		}
+		brush = NewSprite(pd, pd.Bounds())
+		p.brushes[hardness] = brush
+	}
+
+	if ct, ok := p.target.(ComposeTarget); ok {
+		ct.SetComposeMethod(cmp)
+	}
+	brush.DrawColorMask(p.target, IM.Scaled(ZV, radius/brushTextureRadius).Moved(center), c)
+}
+
+// brushCoverage returns the coverage of a brush at the distance from its center relative to its
+// radius.
+func brushCoverage(d, hardness float64) float64 {
+	if d >= 1 {
+		return 0
+	}
+	if d <= hardness {
+		return 1
+	}
+	t := (d - hardness) / (1 - hardness)
+	return 1 - t*t*(3-2*t)
+}
+
+func toColorRGBA(c RGBA) color.RGBA {
+	return color.RGBA{
+		R: uint8(math.Floor(Clamp(c.R, 0, 1)*255 + 0.5)),
+		G: uint8(math.Floor(Clamp(c.G, 0, 1)*255 + 0.5)),
+		B: uint8(math.Floor(Clamp(c.B, 0, 1)*255 + 0.5)),
+		A: uint8(math.Floor(Clamp(c.A, 0, 1)*255 + 0.5)),
+	}
+}
+
+// DirtyRect returns the rectangle covering all pixels painted in the CPU mode since the last
+// ClearDirty, e.g. to upload only that part of the PictureData. If nothing was painted, ok is
+// false.
+func (p *Painter) DirtyRect() (r Rect, ok bool) {
+	return p.dirty, p.isDirty
+}
+
+// ClearDirty resets the dirty rectangle, see DirtyRect.
+func (p *Painter) ClearDirty() {
+	p.dirty, p.isDirty = Rect{}, false
+}
+
+// PictureData returns the PictureData painted into in the CPU mode, nil in the GPU mode.
+func (p *Painter) PictureData() *PictureData {
+	return p.pd
+}
+
+// Bounds returns the bounds of the painted PictureData. In the GPU mode, it returns a zero Rect.
+func (p *Painter) Bounds() Rect {
+	if p.pd == nil {
+		return Rect{}
+	}
+	return p.pd.Bounds()
+}
+
+// Color returns the color of the painted PictureData at the given position. In the GPU mode, it
+// returns a transparent color.
+func (p *Painter) Color(at Vec) RGBA {
+	if p.pd == nil {
+		return Alpha(0)
+	}
+	return p.pd.Color(at)
+}
+
+// Version returns a number which changes whenever something is painted in the CPU mode.
+func (p *Painter) Version() uint64 {
+	return p.version
+}
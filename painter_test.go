@@ -0,0 +1,115 @@
+package pixel_test
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+func TestPainterPaint(t *testing.T) {
+	pd := pixel.MakePictureData(pixel.R(0, 0, 64, 64))
+	p := pixel.NewPainter(pd)
+
+	if _, ok := p.DirtyRect(); ok {
+		t.Errorf("DirtyRect: dirty before painting")
+	}
+
+	p.Paint(pixel.V(32, 32), 10, 0.5, color.RGBA{255, 0, 0, 255}, pixel.ComposeOver)
+	for _, tt := range []struct {
+		at   pixel.Vec
+		want color.RGBA
+	}{
+		{pixel.V(32, 32), color.RGBA{255, 0, 0, 255}},
+		{pixel.V(35, 33), color.RGBA{255, 0, 0, 255}},
+		{pixel.V(32, 43), color.RGBA{}},
+		{pixel.V(0, 0), color.RGBA{}},
+	} {
+		if got := pd.Pix[pd.Index(tt.at)]; got != tt.want {
+			t.Errorf("at %v: got %v, want %v", tt.at, got, tt.want)
+		}
+	}
+	if got := pd.Pix[pd.Index(pixel.V(40, 32))]; got.A == 0 || got.A == 255 || got.R != got.A {
+		t.Errorf("falloff: got %v, want partially covered premultiplied red", got)
+	}
+
+	if got, ok := p.DirtyRect(); !ok || got != pixel.R(22, 22, 42, 42) {
+		t.Errorf("DirtyRect: got %v %v, want %v", got, ok, pixel.R(22, 22, 42, 42))
+	}
+	version := p.Version()
+	p.ClearDirty()
+	p.Paint(pixel.V(0, 0), 4, 1, color.RGBA{0, 0, 255, 255}, pixel.ComposeOver)
+	if got, ok := p.DirtyRect(); !ok || got != pixel.R(0, 0, 4, 4) {
+		t.Errorf("DirtyRect after ClearDirty: got %v %v, want %v", got, ok, pixel.R(0, 0, 4, 4))
+	}
+	if p.Version() == version {
+		t.Errorf("Version didn't change by painting")
+	}
+}
+
+func TestPainterStroke(t *testing.T) {
+	pd := pixel.MakePictureData(pixel.R(0, 0, 100, 20))
+	p := pixel.NewPainter(pd)
+
+	// a fast stroke, sampled only at a few points, must not leave gaps
+	white := color.RGBA{255, 255, 255, 255}
+	p.Paint(pixel.V(5, 10), 3, 0.5, white, pixel.ComposeOver)
+	p.Stroke(pixel.V(5, 10), pixel.V(60, 10), 3, 0.5, white, pixel.ComposeOver)
+	p.Stroke(pixel.V(60, 10), pixel.V(95, 10), 3, 0.5, white, pixel.ComposeOver)
+
+	for x := 5.0; x < 95; x++ {
+		if got := pd.Pix[pd.Index(pixel.V(x, 10))]; got != white {
+			t.Fatalf("gap at x=%v: got %v", x, got)
+		}
+	}
+}
+
+// composeTarget records the compose methods and the Pictures drawn onto it.
+type composeTarget struct {
+	nopTarget
+	pic  pixel.Picture
+	cmps []pixel.ComposeMethod
+}
+
+func (ct *composeTarget) MakePicture(p pixel.Picture) pixel.TargetPicture {
+	ct.pic = p
+	return ct.nopTarget.MakePicture(p)
+}
+
+func (ct *composeTarget) SetMatrix(pixel.Matrix)                   {}
+func (ct *composeTarget) SetColorMask(color.Color)                 {}
+func (ct *composeTarget) SetComposeMethod(cmp pixel.ComposeMethod) { ct.cmps = append(ct.cmps, cmp) }
+
+func TestPainterTargetMatchesCPU(t *testing.T) {
+	target := &composeTarget{}
+	p := pixel.NewTargetPainter(target)
+	p.Paint(pixel.V(100, 50), 20, 0.3, color.RGBA{0, 255, 0, 255}, pixel.ComposeXor)
+
+	if len(target.cmps) != 1 || target.cmps[0] != pixel.ComposeXor {
+		t.Errorf("compose methods: got %v, want [ComposeXor]", target.cmps)
+	}
+	quad := pixel.R(target.tris.Position(0).X, target.tris.Position(0).Y, target.tris.Position(0).X, target.tris.Position(0).Y)
+	for i := 0; i < target.tris.Len(); i++ {
+		pos := target.tris.Position(i)
+		quad = quad.Union(pixel.R(pos.X, pos.Y, pos.X, pos.Y))
+		if c := target.tris.Color(i); c != pixel.RGB(0, 1, 0) {
+			t.Errorf("vertex %d: color mask %v, want the brush color", i, c)
+		}
+	}
+	if want := pixel.R(80, 30, 120, 70); quad != want {
+		t.Errorf("brush quad %v, want %v", quad, want)
+	}
+
+	// painting white onto a transparent PictureData of the brush texture's size gives the texture
+	brush, ok := target.pic.(*pixel.PictureData)
+	if !ok {
+		t.Fatalf("brush picture is %T, want *pixel.PictureData", target.pic)
+	}
+	pd := pixel.MakePictureData(brush.Rect)
+	pixel.NewPainter(pd).Paint(brush.Rect.Center(), brush.Rect.W()/2, 0.3, color.White, pixel.ComposeOver)
+	for i := range pd.Pix {
+		if pd.Pix[i] != brush.Pix[i] {
+			t.Fatalf("pixel %d: CPU %v, GPU brush %v", i, pd.Pix[i], brush.Pix[i])
+		}
+	}
+}
@@ -5,8 +5,8 @@ import (
 	"image/color"
 
 	"github.com/faiface/glhf"
-	"github.com/faiface/mainthread"
 	"github.com/faiface/pixel"
+	"github.com/go-gl/gl/v3.3-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/pkg/errors"
 )
@@ -25,9 +25,19 @@ type Canvas struct {
 	smooth bool
 
 	sprite *pixel.Sprite
+
+	// additional color attachments, see NewCanvasMRT
+	attachments        []*glhf.Texture
+	attachmentsTracker *resourceTracker
+
+	label string
+	drawn map[VersionedDrawable]drawnVersions
 }
 
-var _ pixel.ComposeTarget = (*Canvas)(nil)
+var (
+	_ pixel.ComposeTarget          = (*Canvas)(nil)
+	_ pixel.UpdatableTargetPicture = (*canvasPicture)(nil)
+)
 
 // NewCanvas creates a new empty, fully transparent Canvas with given bounds.
 func NewCanvas(bounds pixel.Rect) *Canvas {
@@ -40,9 +50,42 @@ func NewCanvas(bounds pixel.Rect) *Canvas {
 	baseShader(c)
 	c.SetBounds(bounds)
 	c.shader.update()
+
+	_, _, w, h := intBounds(bounds)
+	c.SetLabel(autoLabel("Canvas", w, h))
 	return c
 }
 
+// SetLabel sets the name of the Canvas shown by graphics debuggers (such as RenderDoc or apitrace)
+// for its framebuffer and texture, and for the group of the draws onto it. By default, Canvases
+// are labeled by their size and a number, e.g. "Canvas 512x512 #3".
+//
+// If the KHR_debug extension is not available, the label is not shown anywhere.
+func (c *Canvas) SetLabel(label string) {
+	c.label = label
+	frame := c.gf.Frame()
+	callNonBlock(func() {
+		labelObject(gl.FRAMEBUFFER, frame.ID(), label)
+		labelObject(gl.TEXTURE, frame.Texture().ID(), label)
+	})
+}
+
+// Label returns the label of the Canvas, see SetLabel.
+func (c *Canvas) Label() string {
+	return c.label
+}
+
+// PushDebugGroup starts a named group of OpenGL commands, see the package function
+// PushDebugGroup.
+func (c *Canvas) PushDebugGroup(name string) {
+	PushDebugGroup(name)
+}
+
+// PopDebugGroup ends the group of OpenGL commands started by the last PushDebugGroup.
+func (c *Canvas) PopDebugGroup() {
+	PopDebugGroup()
+}
+
 // SetUniform will update the named uniform with the value of any supported underlying
 // attribute variable. If the uniform already exists, including defaults, they will be reassigned
 // to the new value. The value can be a pointer.
@@ -50,6 +93,30 @@ func (c *Canvas) SetUniform(name string, value interface{}) {
 	c.shader.setUniform(name, value)
 }
 
+// SetHDRVertexColors sets whether the vertex colors of Triangles drawn onto this Canvas should be
+// stored as four floats, instead of being packed into 8 bits per component, which is the default.
+//
+// Packed colors take a quarter of the memory and upload bandwidth, but are clamped to [0, 1] and
+// rounded to multiples of 1/255. Only enable HDR vertex colors if you need more precision than
+// that, or colors outside of [0, 1] in a custom fragment shader.
+//
+// Call this method before drawing anything onto the Canvas. Triangles made by this Canvas before
+// the change are not valid anymore.
+func (c *Canvas) SetHDRVertexColors(hdr bool) {
+	if hdr {
+		c.shader.vf, c.shader.vs = hdrCanvasVertexFormat, hdrCanvasVertexShader
+	} else {
+		c.shader.vf, c.shader.vs = defaultCanvasVertexFormat, baseCanvasVertexShader
+	}
+	c.shader.update()
+}
+
+// HDRVertexColors returns whether the vertex colors of Triangles drawn onto this Canvas are
+// stored as four floats.
+func (c *Canvas) HDRVertexColors() bool {
+	return c.shader.vf[canvasColor].Type == glhf.Vec4
+}
+
 // SetFragmentShader allows you to set a new fragment shader on the underlying
 // framebuffer. Argument "src" is the GLSL source, not a filename.
 func (c *Canvas) SetFragmentShader(src string) {
@@ -84,7 +151,7 @@ func (c *Canvas) MakePicture(p pixel.Picture) pixel.TargetPicture {
 		}
 	}
 	return &canvasPicture{
-		GLPicture: NewGLPicture(p),
+		GLPicture: newBudgetedGLPicture(p),
 		dst:       c,
 	}
 }
@@ -123,7 +190,14 @@ func (c *Canvas) SetComposeMethod(cmp pixel.ComposeMethod) {
 
 // SetBounds resizes the Canvas to the new bounds. Old content will be preserved.
 func (c *Canvas) SetBounds(bounds pixel.Rect) {
+	frame := c.gf.frame
 	c.gf.SetBounds(bounds)
+	if c.gf.frame != frame {
+		c.attach()
+		if c.label != "" {
+			c.SetLabel(c.label)
+		}
+	}
 	if c.sprite == nil {
 		c.sprite = pixel.NewSprite(nil, pixel.Rect{})
 	}
@@ -148,13 +222,13 @@ func (c *Canvas) Smooth() bool {
 	return c.smooth
 }
 
-// must be manually called inside mainthread
+// must be manually called on the main thread
 func (c *Canvas) setGlhfBounds() {
-	_, _, bw, bh := intBounds(c.gf.Bounds())
-	glhf.Bounds(0, 0, bw, bh)
+	tex := c.gf.Texture()
+	glhf.Bounds(0, 0, tex.Width(), tex.Height())
 }
 
-// must be manually called inside mainthread
+// must be manually called on the main thread
 func setBlendFunc(cmp pixel.ComposeMethod) {
 	switch cmp {
 	case pixel.ComposeOver:
@@ -198,7 +272,7 @@ func (c *Canvas) Clear(color color.Color) {
 		A: float64(c.col[3]),
 	})
 
-	mainthread.CallNonBlock(func() {
+	callNonBlock(func() {
 		c.setGlhfBounds()
 		c.gf.Frame().Begin()
 		glhf.Clear(
@@ -233,7 +307,7 @@ func (c *Canvas) Frame() *glhf.Frame {
 func (c *Canvas) SetPixels(pixels []uint8) {
 	c.gf.Dirty()
 
-	mainthread.Call(func() {
+	call(func() {
 		tex := c.Texture()
 		tex.Begin()
 		tex.SetPixels(0, 0, tex.Width(), tex.Height(), pixels)
@@ -245,7 +319,7 @@ func (c *Canvas) SetPixels(pixels []uint8) {
 func (c *Canvas) Pixels() []uint8 {
 	var pixels []uint8
 
-	mainthread.Call(func() {
+	call(func() {
 		tex := c.Texture()
 		tex.Begin()
 		pixels = tex.Pixels(0, 0, tex.Width(), tex.Height())
@@ -255,6 +329,64 @@ func (c *Canvas) Pixels() []uint8 {
 	return pixels
 }
 
+// PixelFormat is the order of the color channels in the pixels returned by Canvas.ReadPixels.
+type PixelFormat int
+
+// Here are the supported pixel formats, the channels are always one byte each and the colors are
+// alpha-premultiplied.
+const (
+	// PixelFormatRGBA is read with glReadPixels/glGetTexImage format GL_RGBA.
+	PixelFormatRGBA PixelFormat = iota
+
+	// PixelFormatBGRA is read with format GL_BGRA, which is what many image libraries and
+	// operating system APIs (such as Windows DIBs and Cairo on little-endian machines) expect.
+	PixelFormatBGRA
+)
+
+// ReadPixels returns the content of the Canvas in the given channel order. The pixels are
+// alpha-premultiplied and tightly packed, 4 bytes each.
+//
+// Without flip, the rows go from the bottom of the Canvas to the top, like with Pixels, which is
+// the order of OpenGL. With flip, the rows go from the top to the bottom, like in image.RGBA and
+// most other image libraries.
+//
+// The format is passed to glGetTexImage with the type GL_UNSIGNED_BYTE, so the conversion is done
+// by the driver.
+func (c *Canvas) ReadPixels(format PixelFormat, flip bool) []uint8 {
+	glFormat := uint32(gl.RGBA)
+	switch format {
+	case PixelFormatRGBA:
+	case PixelFormatBGRA:
+		glFormat = gl.BGRA
+	default:
+		panic(errors.New("Canvas: invalid pixel format"))
+	}
+
+	var pixels []uint8
+	call(func() {
+		tex := c.Texture()
+		pixels = make([]uint8, 4*tex.Width()*tex.Height())
+		if len(pixels) == 0 {
+			return
+		}
+		tex.Begin()
+		gl.GetTexImage(gl.TEXTURE_2D, 0, glFormat, gl.UNSIGNED_BYTE, gl.Ptr(pixels))
+		tex.End()
+	})
+
+	if flip {
+		stride := 4 * c.Texture().Width()
+		tmp := make([]uint8, stride)
+		for i, j := 0, len(pixels)/stride-1; i < j; i, j = i+1, j-1 {
+			copy(tmp, pixels[i*stride:(i+1)*stride])
+			copy(pixels[i*stride:(i+1)*stride], pixels[j*stride:(j+1)*stride])
+			copy(pixels[j*stride:(j+1)*stride], tmp)
+		}
+	}
+
+	return pixels
+}
+
 // Draw draws the content of the Canvas onto another Target, transformed by the given Matrix, just
 // like if it was a Sprite containing the whole Canvas.
 func (c *Canvas) Draw(t pixel.Target, matrix pixel.Matrix) {
@@ -274,7 +406,9 @@ type canvasTriangles struct {
 	dst *Canvas
 }
 
-func (ct *canvasTriangles) draw(tex *glhf.Texture, bounds pixel.Rect) {
+// draw draws the triangles with the texture, or with the texture of the budgeted glPicture if it's
+// not nil, see SetTextureBudget.
+func (ct *canvasTriangles) draw(tex *glhf.Texture, budgeted *glPicture, bounds pixel.Rect) {
 	ct.dst.gf.Dirty()
 
 	// save the current state vars to avoid race condition
@@ -282,8 +416,17 @@ func (ct *canvasTriangles) draw(tex *glhf.Texture, bounds pixel.Rect) {
 	smt := ct.dst.smooth
 	mat := ct.dst.mat
 	col := ct.dst.col
+	label := ct.dst.label
+	down := CurrentYAxis() == YAxisDown
+
+	callNonBlock(func() {
+		if budgeted != nil {
+			tex = budgeted.budgetTexture()
+		}
+
+		pushDebugGroup(label)
+		defer popDebugGroup()
 
-	mainthread.CallNonBlock(func() {
 		ct.dst.setGlhfBounds()
 		setBlendFunc(cmp)
 
@@ -311,6 +454,15 @@ func (ct *canvasTriangles) draw(tex *glhf.Texture, bounds pixel.Rect) {
 			float32(bh),
 		}
 
+		// with the y axis down, both the positions and the picture coordinates are measured
+		// from the top edge
+		if down {
+			ct.dst.shader.uniformDefaults.bounds[1] = float32(dstBounds.Max.Y)
+			ct.dst.shader.uniformDefaults.bounds[3] *= -1
+			ct.dst.shader.uniformDefaults.texbounds[1] = float32(by + bh)
+			ct.dst.shader.uniformDefaults.texbounds[3] *= -1
+		}
+
 		for loc, u := range ct.dst.shader.uniforms {
 			ct.dst.shader.s.SetUniformAttr(loc, u.Value())
 		}
@@ -339,7 +491,7 @@ func (ct *canvasTriangles) draw(tex *glhf.Texture, bounds pixel.Rect) {
 }
 
 func (ct *canvasTriangles) Draw() {
-	ct.draw(nil, pixel.Rect{})
+	ct.draw(nil, nil, pixel.Rect{})
 }
 
 type canvasPicture struct {
@@ -347,12 +499,30 @@ type canvasPicture struct {
 	dst *Canvas
 }
 
+// Update uploads the new content of the Picture into the existing texture, if the canvasPicture was
+// made from a plain Picture of the same bounds.
+func (cp *canvasPicture) Update(p pixel.Picture) bool {
+	gp, ok := cp.GLPicture.(*glPicture)
+	if !ok || p.Bounds() != gp.bounds {
+		return false
+	}
+	pixels := glPixels(p)
+	call(func() {
+		gp.update(pixels)
+	})
+	return true
+}
+
 func (cp *canvasPicture) Draw(t pixel.TargetTriangles) {
 	ct := t.(*canvasTriangles)
 	if cp.dst != ct.dst {
 		panic(fmt.Errorf("(%T).Draw: TargetTriangles generated by different Canvas", cp))
 	}
-	ct.draw(cp.GLPicture.Texture(), cp.GLPicture.Bounds())
+	if gp, ok := cp.GLPicture.(*glPicture); ok && gp.budget != nil {
+		ct.draw(nil, gp, gp.bounds)
+		return
+	}
+	ct.draw(cp.GLPicture.Texture(), nil, cp.GLPicture.Bounds())
 }
 
 const (
@@ -362,7 +532,16 @@ const (
 	canvasIntensity
 )
 
+// defaultCanvasVertexFormat has the space of one float for the packed color, which is read as four
+// normalized unsigned bytes, see GLTriangles.setColorPointer.
 var defaultCanvasVertexFormat = glhf.AttrFormat{
+	canvasPosition:  {Name: "aPosition", Type: glhf.Vec2},
+	canvasColor:     {Name: "aColor", Type: glhf.Float},
+	canvasTexCoords: {Name: "aTexCoords", Type: glhf.Vec2},
+	canvasIntensity: {Name: "aIntensity", Type: glhf.Float},
+}
+
+var hdrCanvasVertexFormat = glhf.AttrFormat{
 	canvasPosition:  {Name: "aPosition", Type: glhf.Vec2},
 	canvasColor:     {Name: "aColor", Type: glhf.Vec4},
 	canvasTexCoords: {Name: "aTexCoords", Type: glhf.Vec2},
@@ -0,0 +1,134 @@
+package pixelgl
+
+import (
+	"github.com/faiface/pixel"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/pkg/errors"
+)
+
+var _ pixel.VolatileTarget = (*Window)(nil)
+
+// resetStatus is glGetGraphicsResetStatus of the robustness extension available in the current
+// OpenGL context, nil if there's none.
+//
+// Note: must only be accessed inside the main thread.
+var resetStatus func() uint32
+
+// initRobustness checks which robustness extension is available in the current OpenGL context, if
+// any. Without one, the loss of the context can't be detected.
+//
+// Note: must be called inside the main thread.
+func initRobustness() {
+	var major, minor int32
+	gl.GetIntegerv(gl.MAJOR_VERSION, &major)
+	gl.GetIntegerv(gl.MINOR_VERSION, &minor)
+	khr, arb := major > 4 || major == 4 && minor >= 5, false
+
+	var n int32
+	gl.GetIntegerv(gl.NUM_EXTENSIONS, &n)
+	for i := int32(0); i < n; i++ {
+		switch gl.GoStr(gl.GetStringi(gl.EXTENSIONS, uint32(i))) {
+		case "GL_KHR_robustness":
+			khr = true
+		case "GL_ARB_robustness":
+			arb = true
+		}
+	}
+
+	switch {
+	case khr:
+		resetStatus = gl.GetGraphicsResetStatus
+	case arb:
+		resetStatus = gl.GetGraphicsResetStatusARB
+	default:
+		resetStatus = nil
+	}
+}
+
+// SetContextLostCallback sets a function called after the Window recovered from a loss of its
+// OpenGL context. Passing nil removes the callback.
+//
+// The OpenGL context can be lost on some platforms, for example when the graphics driver gets
+// updated or restarts after a GPU hang. All video memory is gone then. The Window detects the loss
+// in Update and recovers from it: it recreates the underlying window with a new OpenGL context and
+// a new Canvas, and increments its Generation. Sprites, Batches and other Drawers drawn onto the
+// Window notice the new Generation and upload their Triangles and Pictures again on their next
+// Draw, so most applications don't have to do anything.
+//
+// Everything else created from the lost context is invalid though: Canvases (including the
+// content and the shaders of the Window's Canvas), GLPictures, GLTriangles and GLFrames. Recreate
+// them in the callback:
+//
+//   win.SetContextLostCallback(func() {
+//       win.Canvas().SetFragmentShader(waterShader)
+//       minimap = pixelgl.NewCanvas(minimap.Bounds())
+//   })
+//
+// The callback is called from Update, after the recovery is done.
+//
+// The loss is detected with GL_KHR_robustness or GL_ARB_robustness. If the driver supports neither,
+// the callback is never called.
+func (w *Window) SetContextLostCallback(callback func()) {
+	w.contextLostCallback = callback
+}
+
+// Generation returns a number which changes whenever the Window recovers from a loss of its OpenGL
+// context, see SetContextLostCallback. It makes the Window a pixel.VolatileTarget.
+func (w *Window) Generation() uint64 {
+	return w.generation
+}
+
+// contextLost reports whether the OpenGL context of the current Window was lost. It queries the
+// reset status, so the pending OpenGL errors are left for the application to check.
+//
+// Note: must be called inside the main thread.
+func contextLost() bool {
+	if resetStatus == nil {
+		return false
+	}
+	return resetStatus() != gl.NO_ERROR
+}
+
+// recoverContext replaces the window, whose OpenGL context was lost, with a new one of the same
+// position, size and monitor, and restores the state of the Window.
+func (w *Window) recoverContext() {
+	err := callErr(func() error {
+		old := w.window
+		x, y := old.GetPos()
+		width, height := old.GetSize()
+		monitor := old.GetMonitor()
+
+		if err := w.create(width, height, monitor, nil); err != nil {
+			w.window = old
+			return err
+		}
+		if monitor == nil {
+			w.window.SetPos(x, y)
+		}
+		old.Destroy()
+		return nil
+	})
+	if err != nil {
+		panic(errors.Wrap(err, "recovering from a lost OpenGL context failed"))
+	}
+
+	w.setIcon(w.cfg.Icon)
+	w.initInput()
+	w.SetCursorVisible(w.cursorVisible)
+	w.cursorAnim.current = -1 // the new GLFW window has the default cursor
+	if w.cursorShape != DefaultCursor {
+		call(func() {
+			w.window.SetCursor(w.standardCursor(w.cursorShape))
+		})
+	}
+
+	smooth := w.canvas.Smooth()
+	w.canvas = NewCanvas(w.bounds)
+	w.canvas.SetSmooth(smooth)
+	w.internal = newInternalCanvas(w.cfg)
+	w.generation++
+
+	if w.contextLostCallback != nil {
+		w.contextLostCallback()
+	}
+}
@@ -0,0 +1,159 @@
+package pixelgl
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// cursorAnimation is the animated cursor of a Window, see SetAnimatedCursor.
+type cursorAnimation struct {
+	cursors []*glfw.Cursor
+	fps     int
+	start   time.Time
+	current int // index of the shown cursor, -1 if none is shown yet
+}
+
+// SetAnimatedCursor replaces the mouse cursor inside the Window by an animation cycling through
+// the frames, e.g. a busy spinner. The frames are switched in Update, fps frames per second by the
+// time since this call. A single frame or fps of 0 shows a static custom cursor.
+//
+// The hotspot is the point of the frames at the mouse position, in the coordinates of the
+// Pictures relative to the bottom-left corner of their Bounds. All the frames should have the same
+// size.
+//
+//   win.SetAnimatedCursor(spinnerFrames, 12, pixel.V(8, 8))
+//   ...
+//   win.SetAnimatedCursor(nil, 0, pixel.ZV) // back to the default cursor
+//
+// Passing no frames restores the default cursor. The animated cursor replaces the cursor set by
+// SetStandardCursor. The visibility set by SetCursorVisible applies to the animated cursor too.
+func (w *Window) SetAnimatedCursor(frames []pixel.Picture, fps int, hotspot pixel.Vec) {
+	imgs := make([]image.Image, len(frames))
+	hot := make([]image.Point, len(frames))
+	for i, frame := range frames {
+		pd := pixel.PictureDataFromPicture(frame)
+		imgs[i] = pd.Image()
+		hot[i] = image.Pt(
+			int(math.Floor(hotspot.X)),
+			int(math.Floor(pd.Rect.H()-hotspot.Y)),
+		)
+	}
+
+	w.cursorShape = DefaultCursor
+	call(func() {
+		w.window.SetCursor(nil)
+		w.stopCursorAnimation()
+		w.cursorAnim = cursorAnimation{fps: fps, start: time.Now(), current: -1}
+		for i := range imgs {
+			w.cursorAnim.cursors = append(w.cursorAnim.cursors, glfw.CreateCursor(imgs[i], hot[i].X, hot[i].Y))
+		}
+		w.updateCursor(time.Now())
+	})
+}
+
+// stopCursorAnimation destroys the cursors of the animated cursor.
+//
+// Note: must be called inside the main thread.
+func (w *Window) stopCursorAnimation() {
+	for _, c := range w.cursorAnim.cursors {
+		c.Destroy()
+	}
+	w.cursorAnim = cursorAnimation{current: -1}
+}
+
+// updateCursor shows the frame of the animated cursor due at the time, if it's not shown already.
+//
+// Note: must be called inside the main thread.
+func (w *Window) updateCursor(now time.Time) {
+	ca := &w.cursorAnim
+	if len(ca.cursors) == 0 {
+		return
+	}
+	i := 0
+	if ca.fps > 0 {
+		i = int(now.Sub(ca.start).Seconds()*float64(ca.fps)) % len(ca.cursors)
+	}
+	if i != ca.current {
+		w.window.SetCursor(ca.cursors[i])
+		ca.current = i
+	}
+}
+
+// StandardCursor is a mouse cursor shape provided by the operating system, see
+// Window.SetStandardCursor.
+type StandardCursor int
+
+// List of all standard cursor shapes.
+const (
+	DefaultCursor StandardCursor = iota
+	ArrowCursor
+	IBeamCursor
+	CrosshairCursor
+	HandCursor
+	HResizeCursor
+	VResizeCursor
+)
+
+var standardCursorShapes = map[StandardCursor]glfw.StandardCursor{
+	ArrowCursor:     glfw.ArrowCursor,
+	IBeamCursor:     glfw.IBeamCursor,
+	CrosshairCursor: glfw.CrosshairCursor,
+	HandCursor:      glfw.HandCursor,
+	HResizeCursor:   glfw.HResizeCursor,
+	VResizeCursor:   glfw.VResizeCursor,
+}
+
+// SetStandardCursor replaces the mouse cursor inside the Window by a standard shape of the
+// operating system, e.g. a hand over clickable widgets or a text cursor over text fields:
+//
+//   if button.Bounds().Contains(win.MousePosition()) {
+//       win.SetStandardCursor(pixelgl.HandCursor)
+//   } else {
+//       win.SetStandardCursor(pixelgl.DefaultCursor)
+//   }
+//
+// DefaultCursor restores the default cursor. The cursors are created once and cached, so calling
+// SetStandardCursor every frame is fine. It stops the animated cursor set by SetAnimatedCursor.
+// The visibility set by SetCursorVisible applies to the standard cursors too.
+func (w *Window) SetStandardCursor(shape StandardCursor) {
+	if _, ok := standardCursorShapes[shape]; !ok && shape != DefaultCursor {
+		panic(fmt.Errorf("(%T).SetStandardCursor: invalid cursor shape %d", w, shape))
+	}
+	if shape == w.cursorShape && len(w.cursorAnim.cursors) == 0 {
+		return
+	}
+	w.cursorShape = shape
+	call(func() {
+		w.stopCursorAnimation()
+		w.window.SetCursor(w.standardCursor(shape))
+	})
+}
+
+// StandardCursor returns the standard cursor shape set by SetStandardCursor, DefaultCursor if
+// none is set.
+func (w *Window) StandardCursor() StandardCursor {
+	return w.cursorShape
+}
+
+// standardCursor returns the cached GLFW cursor of the shape, nil for DefaultCursor.
+//
+// Note: must be called inside the main thread.
+func (w *Window) standardCursor(shape StandardCursor) *glfw.Cursor {
+	if shape == DefaultCursor {
+		return nil
+	}
+	if c := w.cursorShapes[shape]; c != nil {
+		return c
+	}
+	if w.cursorShapes == nil {
+		w.cursorShapes = make(map[StandardCursor]*glfw.Cursor)
+	}
+	c := glfw.CreateStandardCursor(standardCursorShapes[shape])
+	w.cursorShapes[shape] = c
+	return c
+}
@@ -0,0 +1,56 @@
+package pixelgl
+
+import "github.com/faiface/pixel"
+
+// VersionedDrawable is an object which can be drawn onto a Target and counts the changes of its
+// content, such as pixel.Batch.
+type VersionedDrawable interface {
+	Draw(t pixel.Target)
+	Version() uint64
+}
+
+// drawnVersions are the versions of a VersionedDrawable and of the Canvas right after the last
+// draw of the VersionedDrawable onto the Canvas.
+type drawnVersions struct {
+	drawable, canvas uint64
+}
+
+// Version returns a number which changes whenever the content of the Canvas changes, that is on
+// every draw onto it, Clear, SetBounds, SetPixels and RawGL.
+func (c *Canvas) Version() uint64 {
+	return c.gf.Version()
+}
+
+// DrawIfChanged draws d onto the Canvas, unless neither d nor the Canvas changed since the last
+// DrawIfChanged of d. It returns whether d was drawn.
+//
+// This makes it cheap to redraw mostly static content (such as the UI of a tool) every frame. As
+// long as the Canvas isn't cleared and nothing else is drawn onto it, the content from the last
+// frame is reused:
+//
+//   for !win.Closed() {
+//       ui.update() // redraws uiBatch, starting with an opaque background, if anything changed
+//       win.DrawIfChanged(uiBatch)
+//       win.Update()
+//   }
+//
+// To redraw d when it didn't change, but the Canvas did, d must be drawn onto it last. The Canvas
+// remembers every VersionedDrawable passed to this method.
+func (c *Canvas) DrawIfChanged(d VersionedDrawable) bool {
+	last, ok := c.drawn[d]
+	if ok && last.drawable == d.Version() && last.canvas == c.Version() {
+		return false
+	}
+	d.Draw(c)
+	if c.drawn == nil {
+		c.drawn = make(map[VersionedDrawable]drawnVersions)
+	}
+	c.drawn[d] = drawnVersions{drawable: d.Version(), canvas: c.Version()}
+	return true
+}
+
+// DrawIfChanged draws d onto the Window, unless neither d nor the Window changed since the last
+// DrawIfChanged of d, see Canvas.DrawIfChanged.
+func (w *Window) DrawIfChanged(d VersionedDrawable) bool {
+	return w.canvas.DrawIfChanged(d)
+}
@@ -0,0 +1,93 @@
+package pixelgl
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/faiface/pixel"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+var (
+	_ pixel.DebugTarget = (*Canvas)(nil)
+	_ pixel.DebugTarget = (*Window)(nil)
+)
+
+// debugOutput is whether the KHR_debug extension is available. When it's not, labeling objects and
+// debug groups do nothing.
+//
+// Note: must only be accessed inside the main thread.
+var debugOutput bool
+
+// labelCount numbers the automatic labels of the OpenGL objects.
+var labelCount uint64
+
+// initDebugOutput checks whether the KHR_debug extension is available in the current OpenGL
+// context.
+//
+// Note: must be called inside the main thread.
+func initDebugOutput() {
+	var n int32
+	gl.GetIntegerv(gl.NUM_EXTENSIONS, &n)
+	for i := int32(0); i < n; i++ {
+		if gl.GoStr(gl.GetStringi(gl.EXTENSIONS, uint32(i))) == "GL_KHR_debug" {
+			debugOutput = true
+			return
+		}
+	}
+	debugOutput = false
+}
+
+// autoLabel returns a label for a new OpenGL object of the given kind and size, such as
+// "Canvas 512x512 #3".
+func autoLabel(kind string, w, h int) string {
+	return fmt.Sprintf("%s %dx%d #%d", kind, w, h, atomic.AddUint64(&labelCount, 1))
+}
+
+// labelObject labels an OpenGL object, so that graphics debuggers (such as RenderDoc or apitrace)
+// show the label instead of an anonymous number.
+//
+// Note: must be called inside the main thread.
+func labelObject(identifier, name uint32, label string) {
+	if !debugOutput || label == "" {
+		return
+	}
+	gl.ObjectLabel(identifier, name, int32(len(label)), gl.Str(label+"\x00"))
+}
+
+// Note: must be called inside the main thread.
+func pushDebugGroup(name string) {
+	if !debugOutput {
+		return
+	}
+	gl.PushDebugGroup(gl.DEBUG_SOURCE_APPLICATION, 0, int32(len(name)), gl.Str(name+"\x00"))
+}
+
+// Note: must be called inside the main thread.
+func popDebugGroup() {
+	if !debugOutput {
+		return
+	}
+	gl.PopDebugGroup()
+}
+
+// PushDebugGroup starts a named group of OpenGL commands, which graphics debuggers (such as
+// RenderDoc or apitrace) show as a node in the hierarchy of a captured frame. Groups can be nested,
+// end each of them with PopDebugGroup:
+//
+//   pixelgl.PushDebugGroup("HUD")
+//   hud.Draw(win)
+//   pixelgl.PopDebugGroup()
+//
+// Draws onto Canvases and Window.Update are grouped automatically. If the KHR_debug extension is
+// not available, this function does nothing.
+func PushDebugGroup(name string) {
+	callNonBlock(func() {
+		pushDebugGroup(name)
+	})
+}
+
+// PopDebugGroup ends the group of OpenGL commands started by the last PushDebugGroup.
+func PopDebugGroup() {
+	callNonBlock(popDebugGroup)
+}
@@ -0,0 +1,293 @@
+package pixelgl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/pkg/errors"
+)
+
+// DiagnosticsReport describes the OpenGL context, the monitors and the result of a rendering
+// self-test, see Diagnose.
+type DiagnosticsReport struct {
+	GLFWVersion string `json:"glfw_version"`
+
+	// RequestedVersion is the OpenGL version requested by pixelgl, ContextVersion the version of
+	// the context actually obtained.
+	RequestedVersion string `json:"requested_version"`
+	ContextVersion   string `json:"context_version"`
+
+	Vendor      string `json:"vendor"`
+	Renderer    string `json:"renderer"`
+	Version     string `json:"version"`
+	GLSLVersion string `json:"glsl_version"`
+
+	// Limits are the implementation limits relevant to pixelgl, keyed by the OpenGL names without
+	// the GL_ prefix, such as "MAX_TEXTURE_SIZE".
+	Limits map[string]int `json:"limits"`
+
+	// Extensions are the supported extensions which pixelgl uses or which are useful to know
+	// about in bug reports. The full list of extensions is usually too long to be useful.
+	Extensions []string `json:"extensions"`
+
+	// DebugOutput is whether labels and debug groups are available, see PushDebugGroup.
+	DebugOutput bool `json:"debug_output"`
+
+	Monitors []MonitorReport `json:"monitors"`
+
+	// Window describes the current Window.
+	Window struct {
+		Bounds      pixel.Rect `json:"bounds"`
+		Framebuffer pixel.Rect `json:"framebuffer"`
+		PixelScale  float64    `json:"pixel_scale"`
+	} `json:"window"`
+
+	SelfTest SelfTestReport `json:"self_test"`
+}
+
+// MonitorReport describes a Monitor in a DiagnosticsReport. GLFW 3.2 doesn't report the content
+// scale of monitors, the scale of the Window is in DiagnosticsReport.Window instead.
+type MonitorReport struct {
+	Name         string      `json:"name"`
+	Primary      bool        `json:"primary"`
+	Position     pixel.Vec   `json:"position"`
+	Size         pixel.Vec   `json:"size"`
+	PhysicalSize pixel.Vec   `json:"physical_size_mm"`
+	RefreshRate  float64     `json:"refresh_rate"`
+	VideoModes   []VideoMode `json:"video_modes"`
+}
+
+// SelfTestReport is the result of drawing a triangle onto a small Canvas and checking the pixels
+// read back from it.
+type SelfTestReport struct {
+	Passed   bool          `json:"passed"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// diagnosticsLimits are the limits in a DiagnosticsReport.
+var diagnosticsLimits = []struct {
+	name  string
+	pname uint32
+}{
+	{"MAX_TEXTURE_SIZE", gl.MAX_TEXTURE_SIZE},
+	{"MAX_VIEWPORT_DIMS", gl.MAX_VIEWPORT_DIMS},
+	{"MAX_TEXTURE_IMAGE_UNITS", gl.MAX_TEXTURE_IMAGE_UNITS},
+	{"MAX_VERTEX_ATTRIBS", gl.MAX_VERTEX_ATTRIBS},
+	{"MAX_COLOR_ATTACHMENTS", gl.MAX_COLOR_ATTACHMENTS},
+	{"MAX_DRAW_BUFFERS", gl.MAX_DRAW_BUFFERS},
+	{"MAX_SAMPLES", gl.MAX_SAMPLES},
+}
+
+// diagnosticsExtensions are the extensions listed in a DiagnosticsReport, if supported.
+var diagnosticsExtensions = map[string]bool{
+	"GL_KHR_debug":                      true,
+	"GL_ARB_debug_output":               true,
+	"GL_KHR_robustness":                 true,
+	"GL_ARB_robustness":                 true,
+	"GL_ARB_buffer_storage":             true,
+	"GL_ARB_texture_storage":            true,
+	"GL_EXT_texture_filter_anisotropic": true,
+	"GL_ARB_framebuffer_sRGB":           true,
+	"GL_ARB_timer_query":                true,
+}
+
+// Diagnose collects a DiagnosticsReport of the OpenGL context shared by the Windows. It needs a
+// Window, call it right after creating one.
+//
+// The report includes a self-test, which draws a triangle onto a 64x64 Canvas and reads it back.
+// It takes a few milliseconds and leaves nothing behind, the Canvas is released by the garbage
+// collector like any other.
+func Diagnose() (*DiagnosticsReport, error) {
+	var (
+		r   DiagnosticsReport
+		win *Window
+	)
+	call(func() {
+		win = currWin
+		if win == nil {
+			return
+		}
+		win.begin()
+
+		r.GLFWVersion = glfw.GetVersionString()
+		r.RequestedVersion = "3.3 core"
+
+		var major, minor int32
+		gl.GetIntegerv(gl.MAJOR_VERSION, &major)
+		gl.GetIntegerv(gl.MINOR_VERSION, &minor)
+		r.ContextVersion = fmt.Sprintf("%d.%d", major, minor)
+
+		r.Vendor = gl.GoStr(gl.GetString(gl.VENDOR))
+		r.Renderer = gl.GoStr(gl.GetString(gl.RENDERER))
+		r.Version = gl.GoStr(gl.GetString(gl.VERSION))
+		r.GLSLVersion = gl.GoStr(gl.GetString(gl.SHADING_LANGUAGE_VERSION))
+
+		r.Limits = make(map[string]int)
+		for _, l := range diagnosticsLimits {
+			var v [2]int32 // MAX_VIEWPORT_DIMS has two values, report the smaller one
+			gl.GetIntegerv(l.pname, &v[0])
+			if l.pname == gl.MAX_VIEWPORT_DIMS && v[1] < v[0] {
+				v[0] = v[1]
+			}
+			r.Limits[l.name] = int(v[0])
+		}
+
+		var n int32
+		gl.GetIntegerv(gl.NUM_EXTENSIONS, &n)
+		for i := int32(0); i < n; i++ {
+			if ext := gl.GoStr(gl.GetStringi(gl.EXTENSIONS, uint32(i))); diagnosticsExtensions[ext] {
+				r.Extensions = append(r.Extensions, ext)
+			}
+		}
+		sort.Strings(r.Extensions)
+		r.DebugOutput = debugOutput
+	})
+	if win == nil {
+		return nil, errors.New("diagnostics: no Window, create one first")
+	}
+
+	r.Window.Bounds = win.Bounds()
+	r.Window.Framebuffer = win.FramebufferBounds()
+	r.Window.PixelScale = win.pixelScale
+
+	primary := PrimaryMonitor()
+	for _, m := range Monitors() {
+		x, y := m.Position()
+		w, h := m.Size()
+		pw, ph := m.PhysicalSize()
+		r.Monitors = append(r.Monitors, MonitorReport{
+			Name:         m.Name(),
+			Primary:      m.monitor == primary.monitor,
+			Position:     pixel.V(x, y),
+			Size:         pixel.V(w, h),
+			PhysicalSize: pixel.V(pw, ph),
+			RefreshRate:  m.RefreshRate(),
+			VideoModes:   m.VideoModes(),
+		})
+	}
+
+	start := time.Now()
+	err := selfTest()
+	r.SelfTest.Duration = time.Since(start)
+	r.SelfTest.Passed = err == nil
+	if err != nil {
+		r.SelfTest.Error = err.Error()
+	}
+
+	return &r, nil
+}
+
+// selfTest draws a red triangle over the bottom-left half of a small Canvas and checks a pixel
+// inside and outside of it.
+func selfTest() error {
+	const size = 64
+	c := NewCanvas(pixel.R(0, 0, size, size))
+	c.Clear(pixel.Alpha(0))
+
+	tri := pixel.MakeTrianglesData(3)
+	for i, pos := range []pixel.Vec{pixel.V(0, 0), pixel.V(size, 0), pixel.V(0, size)} {
+		(*tri)[i].Position = pos
+		(*tri)[i].Color = pixel.RGB(1, 0, 0)
+	}
+	c.MakeTriangles(tri).Draw()
+
+	pixels := c.Pixels()
+	if len(pixels) != 4*size*size {
+		return fmt.Errorf("read back %d bytes, want %d", len(pixels), 4*size*size)
+	}
+	at := func(x, y int) [4]uint8 {
+		i := 4 * (y*size + x)
+		return [4]uint8{pixels[i], pixels[i+1], pixels[i+2], pixels[i+3]}
+	}
+	if got := at(size/8, size/8); got != [4]uint8{255, 0, 0, 255} {
+		return fmt.Errorf("pixel inside the triangle is %v, want [255 0 0 255]", got)
+	}
+	if got := at(size-size/8, size-size/8); got != [4]uint8{0, 0, 0, 0} {
+		return fmt.Errorf("pixel outside the triangle is %v, want [0 0 0 0]", got)
+	}
+	return nil
+}
+
+// Diagnostics writes a human-readable DiagnosticsReport (see Diagnose) to w, e.g. to attach it to
+// bug reports:
+//
+//   win, err := pixelgl.NewWindow(cfg)
+//   ...
+//   pixelgl.Diagnostics(os.Stderr)
+//
+// It returns an error if the report can't be collected or written, or if the self-test failed.
+// In the last case the report is written anyway.
+func Diagnostics(w io.Writer) error {
+	r, err := Diagnose()
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "GLFW\t%s\n", r.GLFWVersion)
+	fmt.Fprintf(tw, "context\trequested %s, obtained %s\n", r.RequestedVersion, r.ContextVersion)
+	fmt.Fprintf(tw, "vendor\t%s\n", r.Vendor)
+	fmt.Fprintf(tw, "renderer\t%s\n", r.Renderer)
+	fmt.Fprintf(tw, "version\t%s\n", r.Version)
+	fmt.Fprintf(tw, "GLSL\t%s\n", r.GLSLVersion)
+	for _, l := range diagnosticsLimits {
+		fmt.Fprintf(tw, "%s\t%d\n", l.name, r.Limits[l.name])
+	}
+	fmt.Fprintf(tw, "extensions\t%s\n", strings.Join(r.Extensions, " "))
+	fmt.Fprintf(tw, "debug output\t%t\n", r.DebugOutput)
+	fmt.Fprintf(tw, "window\tbounds %v, framebuffer %v, scale %v\n",
+		r.Window.Bounds, r.Window.Framebuffer, r.Window.PixelScale)
+	for i, m := range r.Monitors {
+		primary := ""
+		if m.Primary {
+			primary = " (primary)"
+		}
+		fmt.Fprintf(tw, "monitor %d\t%q%s at %v, %vx%v @ %v Hz, %vx%v mm\n", i, m.Name, primary,
+			m.Position, m.Size.X, m.Size.Y, m.RefreshRate, m.PhysicalSize.X, m.PhysicalSize.Y)
+		modes := make([]string, len(m.VideoModes))
+		for j, vm := range m.VideoModes {
+			modes[j] = fmt.Sprintf("%dx%d@%d", vm.Width, vm.Height, vm.RefreshRate)
+		}
+		fmt.Fprintf(tw, "\tmodes %s\n", strings.Join(modes, " "))
+	}
+	if r.SelfTest.Passed {
+		fmt.Fprintf(tw, "self-test\tpassed in %v\n", r.SelfTest.Duration)
+	} else {
+		fmt.Fprintf(tw, "self-test\tFAILED in %v: %s\n", r.SelfTest.Duration, r.SelfTest.Error)
+	}
+	if err := tw.Flush(); err != nil {
+		return errors.Wrap(err, "diagnostics")
+	}
+
+	if !r.SelfTest.Passed {
+		return errors.Errorf("diagnostics: self-test failed: %s", r.SelfTest.Error)
+	}
+	return nil
+}
+
+// DiagnosticsJSON writes the DiagnosticsReport (see Diagnose) to w as JSON, e.g. to attach it to
+// automatic crash reports. It returns an error like Diagnostics.
+func DiagnosticsJSON(w io.Writer) error {
+	r, err := Diagnose()
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return errors.Wrap(err, "diagnostics")
+	}
+	if !r.SelfTest.Passed {
+		return errors.Errorf("diagnostics: self-test failed: %s", r.SelfTest.Error)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package pixelgl
+
+import (
+	"runtime/debug"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// deferred holds the commands recorded in the deferred flush mode (see WindowConfig.DeferredFlush)
+// or while the processing of the calls is paused (see Pause).
+var deferred struct {
+	sync.Mutex
+	enabled bool
+	paused  bool
+	cmds    []func()
+}
+
+// resumed is signaled when the processing of the calls is resumed.
+var resumed = sync.NewCond(&deferred)
+
+// asyncPanic is the first panic recovered from a call which doesn't wait for the result, raised
+// again by the next call which does.
+//
+// Note: must only be accessed inside the main thread.
+var asyncPanic error
+
+// Pause pauses the processing of the calls on the main thread, for example when the Window gets
+// minimized. While paused, the main thread does no work at all: draws and other calls that don't
+// wait for the result are queued and run after Resume, calls that need the result (such as Update,
+// UpdateInput or Canvas.Pixels) block until Resume.
+//
+// Resume must be called from another goroutine than the one blocked, for example from a goroutine
+// waiting for a timer or a network message. Never call Pause and then a blocking method on the
+// same goroutine without resuming first, it would wait forever. In particular, input isn't polled
+// while paused, so the input callbacks can't resume it.
+func Pause() {
+	deferred.Lock()
+	deferred.paused = true
+	deferred.Unlock()
+}
+
+// Resume resumes the processing of the calls paused by Pause. The queued calls run in the order
+// they were made, before any new calls.
+func Resume() {
+	defer resumed.Broadcast()
+	deferred.Lock()
+	defer deferred.Unlock()
+	// the queued calls are dispatched before clearing paused, so that no new call gets ahead of them
+	if !deferred.enabled {
+		dispatchCommands()
+	}
+	deferred.paused = false
+}
+
+// Paused returns whether the processing of the calls is paused, see Pause.
+func Paused() bool {
+	deferred.Lock()
+	defer deferred.Unlock()
+	return deferred.paused
+}
+
+// waitResumed blocks while the processing of the calls is paused.
+func waitResumed() {
+	deferred.Lock()
+	for deferred.paused {
+		resumed.Wait()
+	}
+	deferred.Unlock()
+}
+
+func setDeferredFlush(enabled bool) {
+	if !enabled {
+		flushCommands()
+	}
+	deferred.Lock()
+	deferred.enabled = enabled
+	deferred.Unlock()
+}
+
+func deferredFlush() bool {
+	deferred.Lock()
+	defer deferred.Unlock()
+	return deferred.enabled
+}
+
+// recording returns whether non-blocking calls are recorded instead of being dispatched, either in
+// the deferred flush mode or while paused.
+func recording() bool {
+	deferred.Lock()
+	defer deferred.Unlock()
+	return deferred.enabled || deferred.paused
+}
+
+// callNonBlock runs f on the main thread without waiting for it. In the deferred flush mode, f is
+// only recorded and runs with the next flush. While paused, f is recorded and runs after Resume.
+//
+// If f panics, the panic is recovered on the main thread, so that Run keeps running, and raised
+// again by the next call, since there's no caller waiting for f.
+func callNonBlock(f func()) {
+	deferred.Lock()
+	if deferred.enabled || deferred.paused {
+		deferred.cmds = append(deferred.cmds, f)
+		deferred.Unlock()
+		return
+	}
+	deferred.Unlock()
+	dispatch(func() {
+		runAsync(f)
+	})
+}
+
+// runAsync runs f, a call which doesn't wait for the result, and keeps its panic for the next
+// call, see callNonBlock.
+//
+// Note: must be called inside the main thread.
+func runAsync(f func()) {
+	defer func() {
+		if r := recover(); r != nil && asyncPanic == nil {
+			asyncPanic = errors.Errorf("pixelgl: a call which didn't wait for its result panicked: %v\n%s", r, debug.Stack())
+		}
+	}()
+	f()
+}
+
+// call runs f on the main thread and waits for it. All recorded commands are flushed first, so
+// they run before f. While paused, call waits for Resume.
+//
+// If f panics, the panic is recovered on the main thread, so that Run keeps running, and raised
+// again by call on the calling goroutine. If f doesn't panic, but an earlier call which didn't wait
+// for the result did, call raises that panic instead, see callNonBlock.
+func call(f func()) {
+	waitResumed()
+	flushCommands()
+	done := make(chan interface{})
+	dispatch(func() {
+		defer func() {
+			r := recover()
+			if r == nil && asyncPanic != nil {
+				r, asyncPanic = asyncPanic, nil
+			}
+			done <- r
+		}()
+		f()
+	})
+	if r := <-done; r != nil {
+		panic(r)
+	}
+}
+
+// callErr is the same as call, but returns the error returned by f.
+func callErr(f func() error) error {
+	var err error
+	call(func() {
+		err = f()
+	})
+	return err
+}
+
+// flushCommands submits all recorded commands to the main thread in a single dispatch, without
+// waiting for them. While paused, the commands stay recorded.
+func flushCommands() {
+	deferred.Lock()
+	defer deferred.Unlock()
+	if !deferred.paused {
+		dispatchCommands()
+	}
+}
+
+// dispatchCommands submits all recorded commands to the main thread in a single dispatch. It's
+// called with deferred locked, so that the commands are dispatched before any call made after
+// them, even if it's made concurrently.
+func dispatchCommands() {
+	cmds := deferred.cmds
+	deferred.cmds = nil
+	if len(cmds) == 0 {
+		return
+	}
+	dispatch(func() {
+		for _, f := range cmds {
+			runAsync(f)
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package pixelgl
+
+import (
+	"time"
+
+	"github.com/faiface/pixel"
+)
+
+const (
+	// defaultDoubleClickInterval is the longest time between the presses of a double-click, unless
+	// changed by SetDoubleClickInterval.
+	defaultDoubleClickInterval = 300 * time.Millisecond
+
+	// doubleClickDistance is how far the mouse may move between the presses of a double-click.
+	doubleClickDistance = 4
+)
+
+// doubleClicks tracks the presses of the mouse buttons for JustDoubleClicked.
+type doubleClicks struct {
+	interval time.Duration // zero means defaultDoubleClickInterval
+
+	// the GLFW time and the mouse position of the first press of a possible double-click of each
+	// mouse button, the time is zero if there's none
+	time [MouseButtonLast + 1]float64
+	pos  [MouseButtonLast + 1]pixel.Vec
+}
+
+// update marks the mouse buttons just double-clicked in the InputState. The times are the times
+// of the last events of the Buttons, see LastInputTime.
+func (dc *doubleClicks) update(is *InputState, times *[KeyLast + 1]float64) {
+	interval := dc.interval
+	if interval == 0 {
+		interval = defaultDoubleClickInterval
+	}
+	for b := MouseButton1; b <= MouseButtonLast; b++ {
+		if !is.JustPressed(b) {
+			continue
+		}
+		t := times[b]
+		if dc.time[b] != 0 && t-dc.time[b] <= interval.Seconds() &&
+			dc.pos[b].To(is.mouse).Len() <= doubleClickDistance {
+			is.double.set(b, true)
+			dc.time[b] = 0 // the next press starts a new double-click
+			continue
+		}
+		dc.time[b], dc.pos[b] = t, is.mouse
+	}
+}
+
+// JustDoubleClicked returns whether the mouse Button has just been pressed down for the second
+// time within the double-click interval (see SetDoubleClickInterval), without the mouse moving
+// more than a few units between the presses. A third press starts a new double-click. It's always
+// false for keys.
+//
+//   if win.JustDoubleClicked(pixelgl.MouseButtonLeft) {
+//       open(selected)
+//   }
+func (w *Window) JustDoubleClicked(button Button) bool {
+	return w.InputSnapshot().JustDoubleClicked(button)
+}
+
+// SetDoubleClickInterval sets the longest time between the two presses of a double-click, see
+// JustDoubleClicked. The default is 300ms, zero or less restores it.
+func (w *Window) SetDoubleClickInterval(interval time.Duration) {
+	if interval < 0 {
+		interval = 0
+	}
+	w.doubleClicks.interval = interval
+}
+
+// DoubleClickInterval returns the double-click interval set by SetDoubleClickInterval.
+func (w *Window) DoubleClickInterval() time.Duration {
+	if w.doubleClicks.interval == 0 {
+		return defaultDoubleClickInterval
+	}
+	return w.doubleClicks.interval
+}
@@ -0,0 +1,115 @@
+package pixelgl
+
+import (
+	"sort"
+	"time"
+)
+
+// defaultFrameTimeHistory is the number of frame durations kept by a Window by default.
+const defaultFrameTimeHistory = 120
+
+// frameTimes is a rolling buffer of the durations of the last frames.
+type frameTimes struct {
+	last  time.Time
+	times []time.Duration
+	next  int
+	full  bool
+}
+
+func (ft *frameTimes) setHistory(n int) {
+	if n <= 0 {
+		n = defaultFrameTimeHistory
+	}
+	old := ft.slice()
+	if len(old) > n {
+		old = old[len(old)-n:]
+	}
+	ft.times = make([]time.Duration, n)
+	ft.next = copy(ft.times, old)
+	ft.full = ft.next == n
+	if ft.full {
+		ft.next = 0
+	}
+}
+
+// tick records the end of a frame.
+func (ft *frameTimes) tick(now time.Time) {
+	if !ft.last.IsZero() {
+		ft.times[ft.next] = now.Sub(ft.last)
+		ft.next++
+		if ft.next == len(ft.times) {
+			ft.next = 0
+			ft.full = true
+		}
+	}
+	ft.last = now
+}
+
+// slice returns the recorded durations from the oldest to the newest.
+func (ft *frameTimes) slice() []time.Duration {
+	if !ft.full {
+		return append([]time.Duration(nil), ft.times[:ft.next]...)
+	}
+	s := make([]time.Duration, 0, len(ft.times))
+	s = append(s, ft.times[ft.next:]...)
+	s = append(s, ft.times[:ft.next]...)
+	return s
+}
+
+// FrameTimes returns the durations of the last frames, from the oldest to the newest. A frame is
+// the time between two calls to Update.
+//
+// The number of frames kept is set by WindowConfig.FrameTimeHistory or SetFrameTimeHistory. The
+// returned slice is a copy and can be freely modified.
+func (w *Window) FrameTimes() []time.Duration {
+	return w.frames.slice()
+}
+
+// LongestFrame returns the duration of the longest of the frames returned by FrameTimes. This is
+// useful for detecting stutters.
+func (w *Window) LongestFrame() time.Duration {
+	var longest time.Duration
+	for _, d := range w.frames.slice() {
+		if d > longest {
+			longest = d
+		}
+	}
+	return longest
+}
+
+// minRefreshRateFrames is the number of frames needed by EstimatedRefreshRate.
+const minRefreshRateFrames = 10
+
+// EstimatedRefreshRate returns the rate of the frames returned by FrameTimes in frames per second,
+// measured from their median duration, so that occasional stutters don't affect it. It returns 0
+// until at least 10 frames were measured.
+//
+// If the Window synchronizes with the monitor (see VSync) and the frames are rendered in time, it's
+// close to the refresh rate of the monitor, otherwise it's the rate at which the frames are
+// rendered. So it tells the frame budget the game actually has, even if the driver overrides
+// SetVSync:
+//
+//   if win.VSync() && win.EstimatedRefreshRate() > 1.5*monitor.RefreshRate() {
+//       // vsync is forced off, limit the frame rate by other means
+//   }
+func (w *Window) EstimatedRefreshRate() float64 {
+	times := w.frames.slice()
+	if len(times) < minRefreshRateFrames {
+		return 0
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	median := times[len(times)/2]
+	if len(times)%2 == 0 {
+		median = (times[len(times)/2-1] + median) / 2
+	}
+	if median <= 0 {
+		return 0
+	}
+	return float64(time.Second) / float64(median)
+}
+
+// SetFrameTimeHistory sets the number of frame durations kept by the Window. If n is zero or
+// negative, the default of 120 frames is used. The most recent durations are preserved.
+func (w *Window) SetFrameTimeHistory(n int) {
+	w.frames.setHistory(n)
+}
@@ -1,18 +1,24 @@
 package pixelgl
 
 import (
+	"math"
+
 	"github.com/faiface/glhf"
-	"github.com/faiface/mainthread"
 	"github.com/faiface/pixel"
 )
 
 // GLFrame is a type that helps implementing OpenGL Targets. It implements most common methods to
 // avoid code redundancy. It contains an glhf.Frame that you can draw on.
 type GLFrame struct {
-	frame  *glhf.Frame
-	bounds pixel.Rect
-	pixels []uint8
-	dirty  bool
+	frame   *glhf.Frame
+	tracker *resourceTracker
+	bounds  pixel.Rect
+	pixels  []uint8
+	dirty   bool
+	version uint64
+
+	// scale is the number of pixels of the frame per unit of the bounds, 0 means 1
+	scale float64
 }
 
 // NewGLFrame creates a new GLFrame with the given bounds.
@@ -27,26 +33,56 @@ func (gf *GLFrame) SetBounds(bounds pixel.Rect) {
 	if bounds == gf.Bounds() {
 		return
 	}
+	gf.resize(bounds)
+}
 
-	mainthread.Call(func() {
+// setScale sets the number of pixels of the frame per unit of the bounds. The Window uses it to
+// draw in the resolution of the framebuffer, while keeping the bounds in the screen coordinates.
+func (gf *GLFrame) setScale(scale float64) {
+	if scale == gf.scale || (scale == 1 && gf.scale == 0) {
+		return
+	}
+	gf.scale = scale
+	gf.resize(gf.bounds)
+}
+
+// frameSize returns the size of the frame in pixels for the bounds.
+func (gf *GLFrame) frameSize(bounds pixel.Rect) (w, h int) {
+	_, _, w, h = intBounds(bounds)
+	if gf.scale > 0 && gf.scale != 1 {
+		w = int(math.Ceil(bounds.W()*gf.scale - 1e-6))
+		h = int(math.Ceil(bounds.H()*gf.scale - 1e-6))
+	}
+	if w <= 0 {
+		w = 1
+	}
+	if h <= 0 {
+		h = 1
+	}
+	return w, h
+}
+
+func (gf *GLFrame) resize(bounds pixel.Rect) {
+	call(func() {
 		oldF := gf.frame
 
-		_, _, w, h := intBounds(bounds)
-		if w <= 0 {
-			w = 1
-		}
-		if h <= 0 {
-			h = 1
-		}
+		w, h := gf.frameSize(bounds)
 		gf.frame = glhf.NewFrame(w, h, false)
+		gf.tracker = trackResources("frame", textureName(w, h), 4*int64(w)*int64(h), framebufferResource, textureResource)
 
 		// preserve old content
 		if oldF != nil {
-			ox, oy, ow, oh := intBounds(bounds)
+			ow, oh := oldF.Texture().Width(), oldF.Texture().Height()
+			if ow > w {
+				ow = w
+			}
+			if oh > h {
+				oh = h
+			}
 			oldF.Blit(
 				gf.frame,
-				ox, oy, ox+ow, oy+oh,
-				ox, oy, ox+ow, oy+oh,
+				0, 0, ow, oh,
+				0, 0, ow, oh,
 			)
 		}
 	})
@@ -54,6 +90,7 @@ func (gf *GLFrame) SetBounds(bounds pixel.Rect) {
 	gf.bounds = bounds
 	gf.pixels = nil
 	gf.dirty = true
+	gf.version++
 }
 
 // Bounds returns the current GLFrame's bounds.
@@ -64,7 +101,7 @@ func (gf *GLFrame) Bounds() pixel.Rect {
 // Color returns the color of the pixel under the specified position.
 func (gf *GLFrame) Color(at pixel.Vec) pixel.RGBA {
 	if gf.dirty {
-		mainthread.Call(func() {
+		call(func() {
 			tex := gf.frame.Texture()
 			tex.Begin()
 			gf.pixels = tex.Pixels(0, 0, tex.Width(), tex.Height())
@@ -75,9 +112,14 @@ func (gf *GLFrame) Color(at pixel.Vec) pixel.RGBA {
 	if !gf.bounds.Contains(at) {
 		return pixel.Alpha(0)
 	}
-	bx, by, bw, _ := intBounds(gf.bounds)
+	bx, by, bw, bh := intBounds(gf.bounds)
 	x, y := int(at.X)-bx, int(at.Y)-by
-	off := y*bw + x
+	if gf.scale > 0 && gf.scale != 1 {
+		bw, bh = gf.frame.Texture().Width(), gf.frame.Texture().Height()
+		x = int(math.Floor((at.X - gf.bounds.Min.X) * gf.scale))
+		y = int(math.Floor((at.Y - gf.bounds.Min.Y) * gf.scale))
+	}
+	off := flipRow(y, bh)*bw + x
 	return pixel.RGBA{
 		R: float64(gf.pixels[off*4+0]) / 255,
 		G: float64(gf.pixels[off*4+1]) / 255,
@@ -102,4 +144,11 @@ func (gf *GLFrame) Texture() *glhf.Texture {
 // Frame.
 func (gf *GLFrame) Dirty() {
 	gf.dirty = true
+	gf.version++
+}
+
+// Version returns a number which changes whenever the GLFrame is resized or marked as changed by
+// Dirty.
+func (gf *GLFrame) Version() uint64 {
+	return gf.version
 }
@@ -4,8 +4,9 @@ import (
 	"math"
 
 	"github.com/faiface/glhf"
-	"github.com/faiface/mainthread"
 	"github.com/faiface/pixel"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/pkg/errors"
 )
 
 // GLPicture is a pixel.PictureColor with a Texture. All OpenGL Targets should implement and accept
@@ -21,6 +22,55 @@ type GLPicture interface {
 // NewGLPicture creates a new GLPicture with it's own static OpenGL texture. This function always
 // allocates a new texture that cannot (shouldn't) be further modified.
 func NewGLPicture(p pixel.Picture) GLPicture {
+	gp := newGLPicture(p)
+	call(gp.upload)
+	return gp
+}
+
+// UploadPictures creates GLPictures for all of the PictureData at once. It's equivalent to calling
+// NewGLPicture for each of them, but the textures are all created in a single call to the main
+// thread, instead of one round-trip for each texture. This speeds up loading many textures, e.g.
+// when loading a level, because the main thread is usually busy and each round-trip waits for it.
+//
+//   pics, err := pixelgl.UploadPictures(levelPictures)
+//   if err != nil {
+//       return err
+//   }
+//
+// The pixels are converted on the calling goroutine before the main thread is called. The returned
+// GLPictures are in the same order as pics. Nil PictureData is an error.
+func UploadPictures(pics []*pixel.PictureData) ([]GLPicture, error) {
+	gps := make([]*glPicture, len(pics))
+	for i, pd := range pics {
+		if pd == nil {
+			return nil, errors.Errorf("uploading pictures failed: picture %d is nil", i)
+		}
+		gps[i] = newGLPicture(pd)
+	}
+
+	call(func() {
+		for _, gp := range gps {
+			gp.upload()
+		}
+	})
+
+	glPics := make([]GLPicture, len(gps))
+	for i := range gps {
+		glPics[i] = gps[i]
+	}
+	return glPics, nil
+}
+
+// newGLPicture converts the pixels of the Picture for a GLPicture, without creating the texture.
+func newGLPicture(p pixel.Picture) *glPicture {
+	return &glPicture{
+		bounds: p.Bounds(),
+		pixels: glPixels(p),
+	}
+}
+
+// glPixels converts the pixels of the Picture into the format of a texture.
+func glPixels(p pixel.Picture) []uint8 {
 	bounds := p.Bounds()
 	bx, by, bw, bh := intBounds(bounds)
 
@@ -55,23 +105,48 @@ func NewGLPicture(p pixel.Picture) GLPicture {
 		}
 	}
 
-	var tex *glhf.Texture
-	mainthread.Call(func() {
-		tex = glhf.NewTexture(bw, bh, false, pixels)
-	})
+	return pixels
+}
 
-	gp := &glPicture{
-		bounds: bounds,
-		tex:    tex,
-		pixels: pixels,
+// update replaces the pixels of the GLPicture with the pixels of the Picture of the same bounds
+// and uploads them into the existing texture, if any.
+//
+// Note: must be called inside the main thread.
+func (gp *glPicture) update(pixels []uint8) {
+	gp.pixels = pixels
+	tex := gp.tex
+	if gp.budget != nil {
+		textureBudget.mu.Lock()
+		tex = gp.budget.tex
+		textureBudget.mu.Unlock()
+	}
+	if tex == nil {
+		// evicted, uploaded again with the new pixels when drawn
+		return
+	}
+	tex.Begin()
+	tex.SetPixels(0, 0, tex.Width(), tex.Height(), pixels)
+	tex.End()
+}
+
+// upload creates the texture of the GLPicture.
+//
+// Note: must be called inside the main thread.
+func (gp *glPicture) upload() {
+	_, _, bw, bh := intBounds(gp.bounds)
+	gp.tex = glhf.NewTexture(bw, bh, false, gp.pixels)
+	gp.tracker = trackResources("texture", textureName(bw, bh), 4*int64(bw)*int64(bh), textureResource)
+	if debugOutput {
+		labelObject(gl.TEXTURE, gp.tex.ID(), autoLabel("Picture", bw, bh))
 	}
-	return gp
 }
 
 type glPicture struct {
-	bounds pixel.Rect
-	tex    *glhf.Texture
-	pixels []uint8
+	bounds  pixel.Rect
+	tex     *glhf.Texture
+	tracker *resourceTracker
+	pixels  []uint8
+	budget  *budgetEntry // the texture instead of tex, if subject to the texture budget
 }
 
 func (gp *glPicture) Bounds() pixel.Rect {
@@ -79,6 +154,11 @@ func (gp *glPicture) Bounds() pixel.Rect {
 }
 
 func (gp *glPicture) Texture() *glhf.Texture {
+	if gp.budget != nil {
+		textureBudget.mu.Lock()
+		defer textureBudget.mu.Unlock()
+		return gp.budget.tex
+	}
 	return gp.tex
 }
 
@@ -86,9 +166,9 @@ func (gp *glPicture) Color(at pixel.Vec) pixel.RGBA {
 	if !gp.bounds.Contains(at) {
 		return pixel.Alpha(0)
 	}
-	bx, by, bw, _ := intBounds(gp.bounds)
+	bx, by, bw, bh := intBounds(gp.bounds)
 	x, y := int(at.X)-bx, int(at.Y)-by
-	off := y*bw + x
+	off := flipRow(y, bh)*bw + x
 	return pixel.RGBA{
 		R: float64(gp.pixels[off*4+0]) / 255,
 		G: float64(gp.pixels[off*4+1]) / 255,
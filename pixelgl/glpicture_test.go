@@ -0,0 +1,65 @@
+package pixelgl
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+// BenchmarkUploadPictures compares loading 50 textures one by one with NewGLPicture against a
+// single UploadPictures. The main thread is idle here, in a game it's usually busy drawing the
+// frames, which makes every round-trip of NewGLPicture wait longer.
+func BenchmarkUploadPictures(b *testing.B) {
+	pics := make([]*pixel.PictureData, 50)
+	for i := range pics {
+		pics[i] = pixel.MakePictureData(pixel.R(0, 0, 64, 64))
+	}
+
+	for _, bc := range []struct {
+		name string
+		load func()
+	}{
+		{"NewGLPicture", func() {
+			for _, pd := range pics {
+				NewGLPicture(pd)
+			}
+		}},
+		{"UploadPictures", func() {
+			if _, err := UploadPictures(pics); err != nil {
+				panic(err)
+			}
+		}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			var err error
+			runOrSkip(b, func() {
+				var win *Window
+				win, err = NewWindow(WindowConfig{Bounds: pixel.R(0, 0, 64, 64)})
+				if err != nil {
+					return
+				}
+				defer win.Destroy()
+
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					bc.load()
+					if i%64 == 63 {
+						// delete the textures of the dropped GLPictures from time to time
+						b.StopTimer()
+						runtime.GC()
+						b.StartTimer()
+					}
+				}
+				b.StopTimer()
+
+				// let the finalizers delete the textures while Run is still running, see Run
+				runtime.GC()
+				call(func() {})
+			})
+			if err != nil {
+				b.Skip(err)
+			}
+		})
+	}
+}
@@ -2,7 +2,6 @@ package pixelgl
 
 import (
 	"github.com/faiface/glhf"
-	"github.com/faiface/mainthread"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/pkg/errors"
 )
@@ -42,7 +41,7 @@ func (gs *glShader) update() {
 		})
 	}
 	var shader *glhf.Shader
-	mainthread.Call(func() {
+	call(func() {
 		var err error
 		shader, err = glhf.NewShader(
 			gs.vf,
@@ -218,6 +217,33 @@ func getAttrType(v interface{}) (glhf.AttrType, bool) {
 var baseCanvasVertexShader = `
 #version 330 core
 
+in vec2  aPosition;
+in vec4  aColor; // four normalized unsigned bytes, see GLTriangles.setColorPointer
+in vec2  aTexCoords;
+in float aIntensity;
+
+out vec4  vColor;
+out vec2  vTexCoords;
+out float vIntensity;
+out vec2  vPosition;
+
+uniform mat3 uTransform;
+uniform vec4 uBounds;
+
+void main() {
+	vec2 transPos = (uTransform * vec3(aPosition, 1.0)).xy;
+	vec2 normPos = (transPos - uBounds.xy) / uBounds.zw * 2 - vec2(1, 1);
+	gl_Position = vec4(normPos, 0.0, 1.0);
+	vColor = aColor;
+	vPosition = aPosition;
+	vTexCoords = aTexCoords;
+	vIntensity = aIntensity;
+}
+`
+
+var hdrCanvasVertexShader = `
+#version 330 core
+
 in vec2  aPosition;
 in vec4  aColor;
 in vec2  aTexCoords;
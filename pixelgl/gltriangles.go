@@ -2,20 +2,55 @@ package pixelgl
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/faiface/glhf"
-	"github.com/faiface/mainthread"
 	"github.com/faiface/pixel"
+	"github.com/go-gl/gl/v3.3-core/gl"
 )
 
 // GLTriangles are OpenGL triangles implemented using glhf.VertexSlice.
 //
 // Triangles returned from this function support TrianglesPosition, TrianglesColor and
 // TrianglesPicture. If you need to support more, you can "override" SetLen and Update methods.
+//
+// The vertex format of the Shader determines how the colors are stored. If the "aColor" attribute
+// is a glhf.Float, colors are packed into 8 bits per component (see PackColor) and read by the
+// shader as a normalized vec4, if it is a glhf.Vec4, colors are stored as four floats.
 type GLTriangles struct {
-	vs     *glhf.VertexSlice
-	data   []float32
-	shader *glhf.Shader
+	vs      *glhf.VertexSlice
+	tracker *resourceTracker
+	data    []float32
+	shader  *glhf.Shader
+	layout  glLayout
+}
+
+// glLayout specifies the offsets of the vertex properties within one vertex of GLTriangles.
+type glLayout struct {
+	pos, col, tex, in int
+	packed            bool
+}
+
+func makeGLLayout(format glhf.AttrFormat) glLayout {
+	var (
+		l   glLayout
+		off int
+	)
+	for _, attr := range format {
+		switch attr.Name {
+		case "aPosition":
+			l.pos = off
+		case "aColor":
+			l.col = off
+			l.packed = attr.Type == glhf.Float
+		case "aTexCoords":
+			l.tex = off
+		case "aIntensity":
+			l.in = off
+		}
+		off += attr.Type.Size() / 4
+	}
+	return l
 }
 
 var (
@@ -24,16 +59,37 @@ var (
 	_ pixel.TrianglesPicture  = (*GLTriangles)(nil)
 )
 
+// PackColor packs a color into a single float32 with 8 bits per component (see pixel.PackRGBA),
+// the format used by the "aColor" attribute of the default Canvas vertex format. The components
+// are clamped to [0, 1].
+//
+// The bits of the float32 are the bytes of the color (red being the lowest byte), not a meaningful
+// float value. GLTriangles never do arithmetic on it and they upload it as normalized unsigned
+// bytes (see the Canvas vertex format), so it arrives in the shader as a vec4 in [0, 1], exactly.
+func PackColor(c pixel.RGBA) float32 {
+	return math.Float32frombits(uint32(pixel.PackRGBA(c)))
+}
+
+// UnpackColor does the inverse operation to PackColor.
+func UnpackColor(f float32) pixel.RGBA {
+	return pixel.RGBA8(math.Float32bits(f)).Unpack()
+}
+
 // NewGLTriangles returns GLTriangles initialized with the data from the supplied Triangles.
 //
 // Only draw the Triangles using the provided Shader.
 func NewGLTriangles(shader *glhf.Shader, t pixel.Triangles) *GLTriangles {
 	var gt *GLTriangles
-	mainthread.Call(func() {
+	call(func() {
 		gt = &GLTriangles{
 			vs:     glhf.MakeVertexSlice(shader, 0, t.Len()),
 			shader: shader,
+			layout: makeGLLayout(shader.VertexFormat()),
 		}
+		gt.tracker = trackResources("vertex buffer", fmt.Sprintf("%p", gt), 0, bufferResource)
+		gt.vs.Begin()
+		gt.setColorPointer()
+		gt.vs.End()
 	})
 	gt.SetLen(t.Len())
 	gt.Update(t)
@@ -61,41 +117,73 @@ func (gt *GLTriangles) Len() int {
 //
 // Time complexity is amortized O(1).
 func (gt *GLTriangles) SetLen(length int) {
+	stride := gt.vs.Stride()
 	switch {
 	case length > gt.Len():
 		needAppend := length - gt.Len()
 		for i := 0; i < needAppend; i++ {
-			gt.data = append(gt.data,
-				0, 0,
-				1, 1, 1, 1,
-				0, 0,
-				0,
-			)
+			off := len(gt.data)
+			gt.data = append(gt.data, make([]float32, stride)...)
+			gt.setColor(off, pixel.Alpha(1))
 		}
 	case length < gt.Len():
-		gt.data = gt.data[:length*gt.vs.Stride()]
+		gt.data = gt.data[:length*stride]
 	default:
 		return
 	}
-	mainthread.CallNonBlock(func() {
+	callNonBlock(func() {
 		gt.vs.Begin()
 		gt.vs.SetLen(length)
+		gt.setColorPointer() // growing may have made a new vertex array
 		gt.vs.End()
 	})
+	gt.tracker.setBytes(4 * int64(cap(gt.data)))
 }
 
 // Slice returns a sub-Triangles of this GLTriangles in range [i, j).
 func (gt *GLTriangles) Slice(i, j int) pixel.Triangles {
 	return &GLTriangles{
-		vs:     gt.vs.Slice(i, j),
-		data:   gt.data[i*gt.vs.Stride() : j*gt.vs.Stride()],
-		shader: gt.shader,
+		vs:      gt.vs.Slice(i, j),
+		tracker: gt.tracker,
+		data:    gt.data[i*gt.vs.Stride() : j*gt.vs.Stride()],
+		shader:  gt.shader,
+		layout:  gt.layout,
+	}
+}
+
+// setColorPointer makes the packed color attribute read as four normalized unsigned bytes. glhf
+// only knows float attributes, passing the packed bits through one could flush them as denormals or
+// canonicalize them as NaNs, which are exactly the bits of fully transparent and opaque colors.
+//
+// Note: must be called inside the main thread, with the VertexSlice bound.
+func (gt *GLTriangles) setColorPointer() {
+	if !gt.layout.packed {
+		return
+	}
+	loc := gl.GetAttribLocation(gt.shader.ID(), gl.Str("aColor\x00"))
+	if loc < 0 {
+		return
+	}
+	stride := int32(4 * gt.vs.Stride())
+	gl.VertexAttribPointer(uint32(loc), 4, gl.UNSIGNED_BYTE, true, stride, gl.PtrOffset(4*gt.layout.col))
+}
+
+// setColor sets the color of the vertex starting at the offset off in the data.
+func (gt *GLTriangles) setColor(off int, col pixel.RGBA) {
+	off += gt.layout.col
+	if gt.layout.packed {
+		gt.data[off] = PackColor(col)
+		return
 	}
+	gt.data[off+0] = float32(col.R)
+	gt.data[off+1] = float32(col.G)
+	gt.data[off+2] = float32(col.B)
+	gt.data[off+3] = float32(col.A)
 }
 
 func (gt *GLTriangles) updateData(t pixel.Triangles) {
 	// glTriangles short path
-	if t, ok := t.(*GLTriangles); ok {
+	if t, ok := t.(*GLTriangles); ok && t.layout == gt.layout {
 		copy(gt.data, t.data)
 		return
 	}
@@ -103,6 +191,27 @@ func (gt *GLTriangles) updateData(t pixel.Triangles) {
 	// TrianglesData short path
 	stride := gt.vs.Stride()
 	length := gt.Len()
+	l := gt.layout
+	if t, ok := t.(*pixel.PackedTrianglesData); ok {
+		for i := 0; i < length; i++ {
+			var (
+				px, py = (*t)[i].Position.XY()
+				tx, ty = (*t)[i].Picture.XY()
+			)
+			off := i * stride
+			gt.data[off+l.pos+0] = float32(px)
+			gt.data[off+l.pos+1] = float32(py)
+			if l.packed {
+				gt.data[off+l.col] = math.Float32frombits(uint32((*t)[i].Color))
+			} else {
+				gt.setColor(off, (*t)[i].Color.Unpack())
+			}
+			gt.data[off+l.tex+0] = float32(tx)
+			gt.data[off+l.tex+1] = float32(ty)
+			gt.data[off+l.in] = float32((*t)[i].Intensity)
+		}
+		return
+	}
 	if t, ok := t.(*pixel.TrianglesData); ok {
 		for i := 0; i < length; i++ {
 			var (
@@ -111,16 +220,13 @@ func (gt *GLTriangles) updateData(t pixel.Triangles) {
 				tx, ty = (*t)[i].Picture.XY()
 				in     = (*t)[i].Intensity
 			)
-			d := gt.data[i*stride : i*stride+9]
-			d[0] = float32(px)
-			d[1] = float32(py)
-			d[2] = float32(col.R)
-			d[3] = float32(col.G)
-			d[4] = float32(col.B)
-			d[5] = float32(col.A)
-			d[6] = float32(tx)
-			d[7] = float32(ty)
-			d[8] = float32(in)
+			off := i * stride
+			gt.data[off+l.pos+0] = float32(px)
+			gt.data[off+l.pos+1] = float32(py)
+			gt.setColor(off, col)
+			gt.data[off+l.tex+0] = float32(tx)
+			gt.data[off+l.tex+1] = float32(ty)
+			gt.data[off+l.in] = float32(in)
 		}
 		return
 	}
@@ -128,25 +234,21 @@ func (gt *GLTriangles) updateData(t pixel.Triangles) {
 	if t, ok := t.(pixel.TrianglesPosition); ok {
 		for i := 0; i < length; i++ {
 			px, py := t.Position(i).XY()
-			gt.data[i*stride+0] = float32(px)
-			gt.data[i*stride+1] = float32(py)
+			gt.data[i*stride+l.pos+0] = float32(px)
+			gt.data[i*stride+l.pos+1] = float32(py)
 		}
 	}
 	if t, ok := t.(pixel.TrianglesColor); ok {
 		for i := 0; i < length; i++ {
-			col := t.Color(i)
-			gt.data[i*stride+2] = float32(col.R)
-			gt.data[i*stride+3] = float32(col.G)
-			gt.data[i*stride+4] = float32(col.B)
-			gt.data[i*stride+5] = float32(col.A)
+			gt.setColor(i*stride, t.Color(i))
 		}
 	}
 	if t, ok := t.(pixel.TrianglesPicture); ok {
 		for i := 0; i < length; i++ {
 			pic, intensity := t.Picture(i)
-			gt.data[i*stride+6] = float32(pic.X)
-			gt.data[i*stride+7] = float32(pic.Y)
-			gt.data[i*stride+8] = float32(intensity)
+			gt.data[i*stride+l.tex+0] = float32(pic.X)
+			gt.data[i*stride+l.tex+1] = float32(pic.Y)
+			gt.data[i*stride+l.in] = float32(intensity)
 		}
 	}
 }
@@ -162,15 +264,18 @@ func (gt *GLTriangles) Update(t pixel.Triangles) {
 
 	// this code is supposed to copy the vertex data and CallNonBlock the update if
 	// the data is small enough, otherwise it'll block and not copy the data
-	if len(gt.data) < 256 { // arbitrary heurestic constant
+	//
+	// in the deferred flush mode or while paused, the calls are recorded and blocking would flush
+	// them (or wait for Resume), so always copy
+	if len(gt.data) < 256 || recording() { // arbitrary heurestic constant
 		data := append([]float32{}, gt.data...)
-		mainthread.CallNonBlock(func() {
+		callNonBlock(func() {
 			gt.vs.Begin()
 			gt.vs.SetVertexData(data)
 			gt.vs.End()
 		})
 	} else {
-		mainthread.Call(func() {
+		call(func() {
 			gt.vs.Begin()
 			gt.vs.SetVertexData(gt.data)
 			gt.vs.End()
@@ -187,29 +292,29 @@ func (gt *GLTriangles) Copy() pixel.Triangles {
 
 // Position returns the Position property of the i-th vertex.
 func (gt *GLTriangles) Position(i int) pixel.Vec {
-	px := gt.data[i*gt.vs.Stride()+0]
-	py := gt.data[i*gt.vs.Stride()+1]
+	px := gt.data[i*gt.vs.Stride()+gt.layout.pos+0]
+	py := gt.data[i*gt.vs.Stride()+gt.layout.pos+1]
 	return pixel.V(float64(px), float64(py))
 }
 
 // Color returns the Color property of the i-th vertex.
 func (gt *GLTriangles) Color(i int) pixel.RGBA {
-	r := gt.data[i*gt.vs.Stride()+2]
-	g := gt.data[i*gt.vs.Stride()+3]
-	b := gt.data[i*gt.vs.Stride()+4]
-	a := gt.data[i*gt.vs.Stride()+5]
+	off := i*gt.vs.Stride() + gt.layout.col
+	if gt.layout.packed {
+		return UnpackColor(gt.data[off])
+	}
 	return pixel.RGBA{
-		R: float64(r),
-		G: float64(g),
-		B: float64(b),
-		A: float64(a),
+		R: float64(gt.data[off+0]),
+		G: float64(gt.data[off+1]),
+		B: float64(gt.data[off+2]),
+		A: float64(gt.data[off+3]),
 	}
 }
 
 // Picture returns the Picture property of the i-th vertex.
 func (gt *GLTriangles) Picture(i int) (pic pixel.Vec, intensity float64) {
-	tx := gt.data[i*gt.vs.Stride()+6]
-	ty := gt.data[i*gt.vs.Stride()+7]
-	intensity = float64(gt.data[i*gt.vs.Stride()+8])
+	tx := gt.data[i*gt.vs.Stride()+gt.layout.tex+0]
+	ty := gt.data[i*gt.vs.Stride()+gt.layout.tex+1]
+	intensity = float64(gt.data[i*gt.vs.Stride()+gt.layout.in])
 	return pixel.V(float64(tx), float64(ty)), intensity
 }
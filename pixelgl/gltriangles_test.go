@@ -0,0 +1,35 @@
+package pixelgl_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+)
+
+func TestPackColor(t *testing.T) {
+	for k := 0; k < 256; k++ {
+		v := float64(k) / 255
+		for _, c := range []pixel.RGBA{{R: v}, {G: v}, {B: v}, {A: v}, {R: v, G: v, B: v, A: v}} {
+			if got := pixelgl.UnpackColor(pixelgl.PackColor(c)); got != c {
+				t.Fatalf("UnpackColor(PackColor(%v)) = %v", c, got)
+			}
+		}
+	}
+
+	// transparent colors are denormals and opaque colors with a lot of blue are NaNs, their bits
+	// must survive being copied around like the vertex data is
+	for _, c := range []pixel.RGBA{
+		{},
+		{R: 1, G: 1, B: 1},
+		pixel.Alpha(1),
+		{R: 1, B: 1, A: 1},
+		{R: 0.2, G: 0.3, B: 0.6, A: 1},
+	} {
+		data := append([]float32{}, pixelgl.PackColor(c))
+		if got, want := math.Float32bits(data[0]), uint32(pixel.PackRGBA(c)); got != want {
+			t.Errorf("PackColor(%v) copied has bits %#08x, want %#08x", c, got, want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package pixelgl
+
+import (
+	"fmt"
+	"sync"
+)
+
+// hookList is an ordered list of frame hooks of a Window.
+type hookList struct {
+	mu     sync.Mutex
+	nextID int
+	hooks  []hook // replaced on every change, so that running hooks can add and remove hooks
+}
+
+type hook struct {
+	id int
+	f  func()
+}
+
+func (hl *hookList) add(f func()) (remove func()) {
+	hl.mu.Lock()
+	defer hl.mu.Unlock()
+	id := hl.nextID
+	hl.nextID++
+	hooks := make([]hook, len(hl.hooks), len(hl.hooks)+1)
+	copy(hooks, hl.hooks)
+	hl.hooks = append(hooks, hook{id: id, f: f})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			hl.mu.Lock()
+			defer hl.mu.Unlock()
+			hooks := make([]hook, 0, len(hl.hooks))
+			for _, h := range hl.hooks {
+				if h.id != id {
+					hooks = append(hooks, h)
+				}
+			}
+			hl.hooks = hooks
+		})
+	}
+}
+
+// run runs all the hooks in order. A panic in a hook doesn't skip the others, it's reported to
+// the callback set by Window.SetHookPanicCallback, or re-raised after all the hooks ran if there's
+// no callback.
+func (hl *hookList) run(w *Window, point string) {
+	hl.mu.Lock()
+	hooks := hl.hooks
+	hl.mu.Unlock()
+
+	var first interface{}
+	for _, h := range hooks {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					if w.hookPanicCallback != nil {
+						w.hookPanicCallback(fmt.Errorf("pixelgl: %s hook panicked: %v", point, r))
+					} else if first == nil {
+						first = r
+					}
+				}
+			}()
+			h.f()
+		}()
+	}
+	if first != nil {
+		panic(first)
+	}
+}
+
+// OnPreInput registers a function called at the beginning of UpdateInput, before the events are
+// polled. Hooks registered on the same point run in the order of registration. Calling the
+// returned function removes the hook.
+//
+// Frame hooks let libraries (such as recorders, overlays or debug UIs) do their work at the right
+// point of every frame without the user calling them. They run on the goroutine calling Update and
+// UpdateInput.
+func (w *Window) OnPreInput(f func()) (remove func()) {
+	return w.hooks.preInput.add(f)
+}
+
+// OnPostInput registers a function called at the end of UpdateInput, after the new input state
+// is available. See OnPreInput.
+func (w *Window) OnPostInput(f func()) (remove func()) {
+	return w.hooks.postInput.add(f)
+}
+
+// OnPreSwap registers a function called in Update before the content of the Window is shown. It's
+// the last chance to draw onto the Window in the frame, e.g. an overlay on top of everything.
+// See OnPreInput.
+func (w *Window) OnPreSwap(f func()) (remove func()) {
+	return w.hooks.preSwap.add(f)
+}
+
+// OnPostSwap registers a function called in Update after the content of the Window was shown,
+// before the input is updated. See OnPreInput.
+func (w *Window) OnPostSwap(f func()) (remove func()) {
+	return w.hooks.postSwap.add(f)
+}
+
+// SetHookPanicCallback sets a function called with an error when a frame hook panics. The other
+// hooks still run. If no callback is set (the default), the panic is raised again after all the
+// hooks of the same point ran.
+func (w *Window) SetHookPanicCallback(callback func(err error)) {
+	w.hookPanicCallback = callback
+}
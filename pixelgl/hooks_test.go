@@ -0,0 +1,94 @@
+package pixelgl
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHookListOrder(t *testing.T) {
+	var hl hookList
+	var calls []int
+	hl.add(func() { calls = append(calls, 1) })
+	remove2 := hl.add(func() { calls = append(calls, 2) })
+	hl.add(func() { calls = append(calls, 3) })
+
+	hl.run(&Window{}, "test")
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("ran %v, want %v", calls, want)
+	}
+
+	// removing twice is fine
+	remove2()
+	remove2()
+	calls = nil
+	hl.run(&Window{}, "test")
+	if want := []int{1, 3}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("ran %v after removing, want %v", calls, want)
+	}
+}
+
+func TestHookListRemoveWhileRunning(t *testing.T) {
+	var hl hookList
+	var calls []int
+	var remove2, remove3 func()
+	hl.add(func() {
+		calls = append(calls, 1)
+		remove3()
+		hl.add(func() { calls = append(calls, 4) })
+	})
+	remove2 = hl.add(func() {
+		calls = append(calls, 2)
+		remove2()
+	})
+	remove3 = hl.add(func() { calls = append(calls, 3) })
+
+	// the changes take effect with the next run
+	hl.run(&Window{}, "test")
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("first run ran %v, want %v", calls, want)
+	}
+	calls = nil
+	remove3 = func() {}
+	hl.run(&Window{}, "test")
+	if want := []int{1, 4}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("second run ran %v, want %v", calls, want)
+	}
+}
+
+func TestHookListPanic(t *testing.T) {
+	var hl hookList
+	var calls []int
+	hl.add(func() { calls = append(calls, 1) })
+	hl.add(func() { panic("first") })
+	hl.add(func() { panic("second") })
+	hl.add(func() { calls = append(calls, 4) })
+
+	// with a callback, every panic is reported and the other hooks run
+	var errs []string
+	w := &Window{}
+	w.SetHookPanicCallback(func(err error) {
+		errs = append(errs, err.Error())
+	})
+	hl.run(w, "pre-swap")
+	if want := []int{1, 4}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("ran %v, want %v", calls, want)
+	}
+	if len(errs) != 2 || !strings.Contains(errs[0], "pre-swap hook panicked: first") {
+		t.Errorf("reported %q", errs)
+	}
+
+	// without a callback, the first panic is raised after all the hooks ran
+	calls = nil
+	func() {
+		defer func() {
+			if r := recover(); r != "first" {
+				t.Errorf("recovered %v, want first", r)
+			}
+		}()
+		hl.run(&Window{}, "pre-swap")
+	}()
+	if want := []int{1, 4}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("ran %v with a panic, want %v", calls, want)
+	}
+}
@@ -1,72 +1,126 @@
 package pixelgl
 
 import (
-	"github.com/faiface/mainthread"
+	"time"
+
 	"github.com/faiface/pixel"
 	"github.com/go-gl/glfw/v3.2/glfw"
 )
 
 // Pressed returns whether the Button is currently pressed down.
 func (w *Window) Pressed(button Button) bool {
-	return w.currInp.buttons[button]
+	return w.InputSnapshot().Pressed(button)
+}
+
+// PressedKeys returns all the Buttons currently pressed down, both the keys and the mouse buttons,
+// e.g. for "press any key" prompts when rebinding the controls:
+//
+//   if keys := win.PressedKeys(); len(keys) > 0 {
+//       bindings[action] = keys[0]
+//   }
+func (w *Window) PressedKeys() []Button {
+	return w.InputSnapshot().PressedKeys()
 }
 
 // JustPressed returns whether the Button has just been pressed down.
 func (w *Window) JustPressed(button Button) bool {
-	return w.currInp.buttons[button] && !w.prevInp.buttons[button]
+	return w.InputSnapshot().JustPressed(button)
 }
 
 // JustReleased returns whether the Button has just been released up.
 func (w *Window) JustReleased(button Button) bool {
-	return !w.currInp.buttons[button] && w.prevInp.buttons[button]
+	return w.InputSnapshot().JustReleased(button)
 }
 
 // Repeated returns whether a repeat event has been triggered on button.
 //
 // Repeat event occurs repeatedly when a button is held down for some time.
 func (w *Window) Repeated(button Button) bool {
-	return w.currInp.repeat[button]
+	return w.InputSnapshot().Repeated(button)
 }
 
 // MousePosition returns the current mouse position in the Window's Bounds.
 func (w *Window) MousePosition() pixel.Vec {
-	return w.currInp.mouse
+	return w.InputSnapshot().MousePosition()
 }
 
 // MousePreviousPosition returns the previous mouse position in the Window's Bounds.
 func (w *Window) MousePreviousPosition() pixel.Vec {
-	return w.prevInp.mouse
+	return w.InputSnapshot().MousePreviousPosition()
 }
 
-// SetMousePosition positions the mouse cursor anywhere within the Window's Bounds.
+// SetMousePosition moves the mouse cursor of the operating system (warps it) to a position within
+// the Window's Bounds, e.g. to recenter it for mouse-look camera controls or to snap it onto a UI
+// element. Positions outside of the Bounds are ignored.
+//
+// The position is in the same coordinates as MousePosition, which returns it right away, and the
+// move is not reported as a mouse motion: MousePreviousPosition returns it too. Warping works the
+// same while the cursor is hidden by SetCursorVisible, so for mouse-look hide the cursor and
+// recenter it every frame after reading the motion.
 func (w *Window) SetMousePosition(v pixel.Vec) {
-	mainthread.Call(func() {
-		if (v.X >= 0 && v.X <= w.bounds.W()) &&
-			(v.Y >= 0 && v.Y <= w.bounds.H()) {
-			w.window.SetCursorPos(
-				v.X+w.bounds.Min.X,
-				(w.bounds.H()-v.Y)+w.bounds.Min.Y,
-			)
-			w.prevInp.mouse = v
-			w.currInp.mouse = v
-			w.tempInp.mouse = v
+	var moved bool
+	call(func() {
+		if !w.bounds.Contains(v) {
+			return
+		}
+		x, y := v.X-w.bounds.Min.X, w.bounds.Max.Y-v.Y
+		if CurrentYAxis() == YAxisDown {
+			y = v.Y - w.bounds.Min.Y
 		}
+		w.window.SetCursorPos(x, y)
+		w.tempInp.mouse = v
+		moved = true
 	})
+	if moved {
+		is := w.InputSnapshot()
+		is.mouse = v
+		is.prevMouse = v
+		w.input.Store(is)
+	}
 }
 
-// MouseEntered returns true if the mouse position is within the Window's Bounds.
+// LastInputTime returns the time of the last press, release or repeat event of the Button, as of
+// the last UpdateInput (or Update), or 0 if there was none. The time is in seconds of the GLFW
+// timer, see Time:
+//
+//   if win.JustPressed(pixelgl.KeySpace) {
+//       latency := pixelgl.Time() - win.LastInputTime(pixelgl.KeySpace)
+//       ...
+//   }
+//
+// GLFW doesn't report when the operating system received an event, so the time is taken when
+// the event is delivered while polling the events. It's as precise as the polling, events which
+// arrived while the Window wasn't polling (e.g. waiting for the vertical sync) get the time of the
+// next poll.
+func (w *Window) LastInputTime(button Button) float64 {
+	if button < 0 || button > KeyLast {
+		return 0
+	}
+	return w.inputTimes[button]
+}
+
+// MouseInsideWindow returns true if the mouse cursor is within the Window.
 func (w *Window) MouseInsideWindow() bool {
-	return w.cursorInsideWindow
+	return w.InputSnapshot().MouseInsideWindow()
+}
+
+// SetCursorEnterCallback sets a function called whenever the mouse cursor enters (entered is true)
+// or leaves (entered is false) the Window. Passing nil removes the callback.
+//
+// The callback is called from Window.UpdateInput (or Window.Update), after all the events have
+// been polled, so it's safe to call any Window methods from it.
+func (w *Window) SetCursorEnterCallback(callback func(entered bool)) {
+	w.cursorEnterCallback = callback
 }
 
 // MouseScroll returns the mouse scroll amount (in both axes) since the last call to Window.Update.
 func (w *Window) MouseScroll() pixel.Vec {
-	return w.currInp.scroll
+	return w.InputSnapshot().MouseScroll()
 }
 
 // Typed returns the text typed on the keyboard since the last call to Window.Update.
 func (w *Window) Typed() string {
-	return w.currInp.typed
+	return w.InputSnapshot().Typed()
 }
 
 // Button is a keyboard or mouse button. Why distinguish?
@@ -356,7 +410,15 @@ var buttonNames = map[Button]string{
 }
 
 func (w *Window) initInput() {
-	mainthread.Call(func() {
+	call(func() {
+		// the enter callback only reports changes, so find out where the cursor starts
+		x, y := w.window.GetCursorPos()
+		width, height := w.window.GetSize()
+		w.tempInp.inside = x >= 0 && y >= 0 && x < float64(width) && y < float64(height)
+	})
+	w.input.Store(InputState{time: time.Now(), inside: w.tempInp.inside})
+
+	call(func() {
 		w.window.SetMouseButtonCallback(func(_ *glfw.Window, button glfw.MouseButton, action glfw.Action, mod glfw.ModifierKey) {
 			switch action {
 			case glfw.Press:
@@ -364,6 +426,7 @@ func (w *Window) initInput() {
 			case glfw.Release:
 				w.tempInp.buttons[Button(button)] = false
 			}
+			w.tempInp.times[Button(button)] = glfw.GetTime()
 		})
 
 		w.window.SetKeyCallback(func(_ *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
@@ -378,13 +441,19 @@ func (w *Window) initInput() {
 			case glfw.Repeat:
 				w.tempInp.repeat[Button(key)] = true
 			}
+			w.tempInp.times[Button(key)] = glfw.GetTime()
 		})
 
 		w.window.SetCursorEnterCallback(func(_ *glfw.Window, entered bool) {
-			w.cursorInsideWindow = entered
+			w.tempInp.inside = entered
+			w.tempInp.entered = append(w.tempInp.entered, entered)
 		})
 
 		w.window.SetCursorPosCallback(func(_ *glfw.Window, x, y float64) {
+			if CurrentYAxis() == YAxisDown {
+				w.tempInp.mouse = pixel.V(x+w.bounds.Min.X, y+w.bounds.Min.Y)
+				return
+			}
 			w.tempInp.mouse = pixel.V(
 				x+w.bounds.Min.X,
 				(w.bounds.H()-y)+w.bounds.Min.Y,
@@ -405,16 +474,43 @@ func (w *Window) initInput() {
 // UpdateInput polls window events. Call this function to poll window events
 // without swapping buffers. Note that the Update method invokes UpdateInput.
 func (w *Window) UpdateInput() {
-	mainthread.Call(func() {
+	w.hooks.preInput.run(w, "pre-input")
+
+	call(func() {
 		glfw.PollEvents()
 	})
 
-	w.prevInp = w.currInp
-	w.currInp = w.tempInp
+	prev := w.InputSnapshot()
+	is := InputState{
+		time:      time.Now(),
+		prev:      prev.curr,
+		mouse:     w.tempInp.mouse,
+		prevMouse: prev.mouse,
+		scroll:    w.tempInp.scroll,
+		typed:     w.tempInp.typed,
+		inside:    w.tempInp.inside,
+	}
+	for b := range w.tempInp.buttons {
+		is.curr.set(Button(b), w.tempInp.buttons[b])
+		is.repeat.set(Button(b), w.tempInp.repeat[b])
+	}
+	w.inputTimes = w.tempInp.times
+	w.doubleClicks.update(&is, &w.inputTimes)
+	w.input.Store(is)
 
 	w.tempInp.repeat = [KeyLast + 1]bool{}
 	w.tempInp.scroll = pixel.ZV
 	w.tempInp.typed = ""
+	entered := w.tempInp.entered
+	w.tempInp.entered = nil
 
 	w.updateJoystickInput()
+
+	if w.cursorEnterCallback != nil {
+		for _, e := range entered {
+			w.cursorEnterCallback(e)
+		}
+	}
+
+	w.hooks.postInput.run(w, "post-input")
 }
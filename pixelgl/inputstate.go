@@ -0,0 +1,129 @@
+package pixelgl
+
+import (
+	"time"
+
+	"github.com/faiface/pixel"
+)
+
+// InputState is an immutable snapshot of the input state of a Window, as it was after a call to
+// Window.UpdateInput (or Window.Update).
+//
+// Unlike the Window itself, InputState is a plain value and is safe to pass around and read from
+// any goroutine. It's cheap to copy, buttons are stored in bitsets.
+type InputState struct {
+	time      time.Time
+	curr      buttonSet
+	prev      buttonSet
+	repeat    buttonSet
+	double    buttonSet
+	mouse     pixel.Vec
+	prevMouse pixel.Vec
+	scroll    pixel.Vec
+	typed     string
+	inside    bool
+}
+
+// Time returns the time when the InputState was captured.
+func (is InputState) Time() time.Time {
+	return is.time
+}
+
+// Pressed returns whether the Button was pressed down.
+func (is InputState) Pressed(button Button) bool {
+	return is.curr.get(button)
+}
+
+// PressedKeys returns all the pressed Buttons, both the keys and the mouse buttons, in the order of
+// their values (mouse buttons first).
+func (is InputState) PressedKeys() []Button {
+	return is.curr.buttons()
+}
+
+// JustPressed returns whether the Button has just been pressed down.
+func (is InputState) JustPressed(button Button) bool {
+	return is.curr.get(button) && !is.prev.get(button)
+}
+
+// JustReleased returns whether the Button has just been released up.
+func (is InputState) JustReleased(button Button) bool {
+	return !is.curr.get(button) && is.prev.get(button)
+}
+
+// Repeated returns whether a repeat event has been triggered on button.
+func (is InputState) Repeated(button Button) bool {
+	return is.repeat.get(button)
+}
+
+// JustDoubleClicked returns whether the mouse Button has just been double-clicked, see
+// Window.JustDoubleClicked.
+func (is InputState) JustDoubleClicked(button Button) bool {
+	return is.double.get(button)
+}
+
+// MousePosition returns the mouse position in the Window's Bounds.
+func (is InputState) MousePosition() pixel.Vec {
+	return is.mouse
+}
+
+// MousePreviousPosition returns the previous mouse position in the Window's Bounds.
+func (is InputState) MousePreviousPosition() pixel.Vec {
+	return is.prevMouse
+}
+
+// MouseInsideWindow returns whether the mouse cursor was within the Window.
+func (is InputState) MouseInsideWindow() bool {
+	return is.inside
+}
+
+// MouseScroll returns the mouse scroll amount (in both axes) since the previous InputState.
+func (is InputState) MouseScroll() pixel.Vec {
+	return is.scroll
+}
+
+// Typed returns the text typed on the keyboard since the previous InputState.
+func (is InputState) Typed() string {
+	return is.typed
+}
+
+// buttonSet is a bitset of all Buttons.
+type buttonSet [(KeyLast + 64) / 64]uint64
+
+func (bs buttonSet) get(b Button) bool {
+	if b < 0 || b > KeyLast {
+		return false
+	}
+	return bs[b/64]&(1<<uint(b%64)) != 0
+}
+
+func (bs *buttonSet) set(b Button, v bool) {
+	if b < 0 || b > KeyLast {
+		return
+	}
+	if v {
+		bs[b/64] |= 1 << uint(b%64)
+	} else {
+		bs[b/64] &^= 1 << uint(b%64)
+	}
+}
+
+// buttons returns the Buttons in the set in ascending order.
+func (bs buttonSet) buttons() []Button {
+	var buttons []Button
+	for i, word := range bs {
+		for bit := 0; word != 0; bit++ {
+			if word&1 != 0 {
+				buttons = append(buttons, Button(i*64+bit))
+			}
+			word >>= 1
+		}
+	}
+	return buttons
+}
+
+// InputSnapshot returns the current InputState of the Window. Unlike other input methods, this
+// method is safe to call from any goroutine.
+func (w *Window) InputSnapshot() InputState {
+	is, _ := w.input.Load().(InputState)
+	return is
+}
@@ -0,0 +1,10 @@
+//go:build !pixelgl_nolock
+// +build !pixelgl_nolock
+
+package pixelgl
+
+// The main goroutine runs the initialization on the main thread, locking it here keeps the main
+// function there for Run. Build with the pixelgl_nolock tag to opt out, see LockMainThread.
+func init() {
+	LockMainThread()
+}
@@ -1,7 +1,6 @@
 package pixelgl
 
 import (
-	"github.com/faiface/mainthread"
 	"github.com/go-gl/glfw/v3.2/glfw"
 )
 
@@ -24,7 +23,7 @@ type VideoMode struct {
 // PrimaryMonitor returns the main monitor (usually the one with the taskbar and stuff).
 func PrimaryMonitor() *Monitor {
 	var monitor *glfw.Monitor
-	mainthread.Call(func() {
+	call(func() {
 		monitor = glfw.GetPrimaryMonitor()
 	})
 	return &Monitor{
@@ -35,7 +34,7 @@ func PrimaryMonitor() *Monitor {
 // Monitors returns a slice of all currently available monitors.
 func Monitors() []*Monitor {
 	var monitors []*Monitor
-	mainthread.Call(func() {
+	call(func() {
 		for _, monitor := range glfw.GetMonitors() {
 			monitors = append(monitors, &Monitor{monitor: monitor})
 		}
@@ -46,7 +45,7 @@ func Monitors() []*Monitor {
 // Name returns a human-readable name of the Monitor.
 func (m *Monitor) Name() string {
 	var name string
-	mainthread.Call(func() {
+	call(func() {
 		name = m.monitor.GetName()
 	})
 	return name
@@ -55,7 +54,7 @@ func (m *Monitor) Name() string {
 // PhysicalSize returns the size of the display area of the Monitor in millimeters.
 func (m *Monitor) PhysicalSize() (width, height float64) {
 	var wi, hi int
-	mainthread.Call(func() {
+	call(func() {
 		wi, hi = m.monitor.GetPhysicalSize()
 	})
 	width = float64(wi)
@@ -66,7 +65,7 @@ func (m *Monitor) PhysicalSize() (width, height float64) {
 // Position returns the position of the upper-left corner of the Monitor in screen coordinates.
 func (m *Monitor) Position() (x, y float64) {
 	var xi, yi int
-	mainthread.Call(func() {
+	call(func() {
 		xi, yi = m.monitor.GetPos()
 	})
 	x = float64(xi)
@@ -77,7 +76,7 @@ func (m *Monitor) Position() (x, y float64) {
 // Size returns the resolution of the Monitor in pixels.
 func (m *Monitor) Size() (width, height float64) {
 	var mode *glfw.VidMode
-	mainthread.Call(func() {
+	call(func() {
 		mode = m.monitor.GetVideoMode()
 	})
 	width = float64(mode.Width)
@@ -88,7 +87,7 @@ func (m *Monitor) Size() (width, height float64) {
 // BitDepth returns the number of bits per color of the Monitor.
 func (m *Monitor) BitDepth() (red, green, blue int) {
 	var mode *glfw.VidMode
-	mainthread.Call(func() {
+	call(func() {
 		mode = m.monitor.GetVideoMode()
 	})
 	red = mode.RedBits
@@ -100,7 +99,7 @@ func (m *Monitor) BitDepth() (red, green, blue int) {
 // RefreshRate returns the refresh frequency of the Monitor in Hz (refreshes/second).
 func (m *Monitor) RefreshRate() (rate float64) {
 	var mode *glfw.VidMode
-	mainthread.Call(func() {
+	call(func() {
 		mode = m.monitor.GetVideoMode()
 	})
 	rate = float64(mode.RefreshRate)
@@ -110,7 +109,7 @@ func (m *Monitor) RefreshRate() (rate float64) {
 // VideoModes returns all available video modes for the monitor.
 func (m *Monitor) VideoModes() (vmodes []VideoMode) {
 	var modes []*glfw.VidMode
-	mainthread.Call(func() {
+	call(func() {
 		modes = m.monitor.GetVideoModes()
 	})
 	for _, mode := range modes {
@@ -0,0 +1,161 @@
+package pixelgl
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/faiface/glhf"
+	"github.com/faiface/pixel"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// NewCanvasMRT creates a new empty, fully transparent Canvas with n color attachments (multiple
+// render targets). Everything drawn onto the Canvas is written to all attachments at once, each
+// attachment gets the output variable of the fragment shader at the same location:
+//
+//   #version 330 core
+//   ...
+//   layout(location = 0) out vec4 fragColor;
+//   layout(location = 1) out vec4 glowMask;
+//
+// Set such a fragment shader with SetFragmentShader. The default fragment shader only writes the
+// first attachment, the content of the others is undefined after drawing with it.
+//
+// The first attachment is the Canvas itself, use Target to get the others. Clear clears all
+// attachments to the same color, ClearAttachment clears a single one. SetBounds discards the
+// content of all attachments but the first.
+func NewCanvasMRT(bounds pixel.Rect, n int) *Canvas {
+	if n < 1 {
+		panic(fmt.Errorf("NewCanvasMRT: invalid number of attachments %d", n))
+	}
+	c := NewCanvas(bounds)
+	c.attachments = make([]*glhf.Texture, n-1)
+	c.attach()
+	return c
+}
+
+// Target returns the i-th color attachment of the Canvas as a Picture. The 0th attachment is the
+// Canvas itself. The returned Picture can be drawn onto other Canvases and Windows, for example in
+// a post-processing pass.
+//
+// The Picture reflects the current content of the attachment, even after SetBounds.
+func (c *Canvas) Target(i int) pixel.Picture {
+	if i < 0 || i > len(c.attachments) {
+		panic(fmt.Errorf("(%T).Target: invalid attachment %d", c, i))
+	}
+	if i == 0 {
+		return c
+	}
+	return &mrtPicture{canvas: c, i: i}
+}
+
+// ClearAttachment fills the i-th color attachment of the Canvas with a color, leaving the other
+// attachments unchanged. This way the attachments of a Canvas created by NewCanvasMRT can be
+// cleared to different colors, e.g. the color black and the glow mask transparent:
+//
+//   c.Clear(colornames.Black)
+//   c.ClearAttachment(1, pixel.Alpha(0))
+//
+// The 0th attachment is the Canvas itself, on a Canvas with a single attachment ClearAttachment(0,
+// color) is the same as Clear. ClearAttachment panics if the Canvas has no i-th attachment. The
+// color is masked by the color mask of the Canvas, just like by Clear.
+func (c *Canvas) ClearAttachment(i int, color color.Color) {
+	if i < 0 || i > len(c.attachments) {
+		panic(fmt.Errorf("(%T).ClearAttachment: invalid attachment %d", c, i))
+	}
+	c.gf.Dirty()
+
+	rgba := pixel.ToRGBA(color).Mul(pixel.RGBA{
+		R: float64(c.col[0]),
+		G: float64(c.col[1]),
+		B: float64(c.col[2]),
+		A: float64(c.col[3]),
+	})
+	value := [4]float32{float32(rgba.R), float32(rgba.G), float32(rgba.B), float32(rgba.A)}
+
+	callNonBlock(func() {
+		c.setGlhfBounds()
+		c.gf.Frame().Begin()
+		gl.ClearBufferfv(gl.COLOR, int32(i), &value[0])
+		c.gf.Frame().End()
+	})
+}
+
+// attach creates the additional color attachments of the Canvas and attaches them to the frame.
+func (c *Canvas) attach() {
+	if len(c.attachments) == 0 {
+		return
+	}
+	call(func() {
+		frame := c.gf.Frame()
+		tex := frame.Texture()
+
+		empty := make([]uint8, 4*tex.Width()*tex.Height())
+
+		frame.Begin()
+		bufs := []uint32{gl.COLOR_ATTACHMENT0}
+		for i := range c.attachments {
+			c.attachments[i] = glhf.NewTexture(tex.Width(), tex.Height(), false, empty)
+			attachment := gl.COLOR_ATTACHMENT0 + uint32(i+1)
+			gl.FramebufferTexture2D(gl.FRAMEBUFFER, attachment, gl.TEXTURE_2D, c.attachments[i].ID(), 0)
+			bufs = append(bufs, attachment)
+		}
+		gl.DrawBuffers(int32(len(bufs)), &bufs[0])
+		frame.End()
+
+		kinds := make([]resourceKind, len(c.attachments))
+		for i := range kinds {
+			kinds[i] = textureResource
+		}
+		bytes := int64(len(kinds)) * 4 * int64(tex.Width()) * int64(tex.Height())
+		name := fmt.Sprintf("%d x %s", len(kinds), textureName(tex.Width(), tex.Height()))
+		c.attachmentsTracker = trackResources("attachment", name, bytes, kinds...)
+	})
+}
+
+// mrtPicture is an additional color attachment of a Canvas.
+type mrtPicture struct {
+	canvas *Canvas
+	i      int
+}
+
+var _ GLPicture = (*mrtPicture)(nil)
+
+func (mp *mrtPicture) Bounds() pixel.Rect {
+	return mp.canvas.Bounds()
+}
+
+func (mp *mrtPicture) Texture() *glhf.Texture {
+	return mp.canvas.attachments[mp.i-1]
+}
+
+// Color reads a single pixel of the attachment back from the video memory, so it's slow.
+func (mp *mrtPicture) Color(at pixel.Vec) pixel.RGBA {
+	bounds := mp.Bounds()
+	if !bounds.Contains(at) {
+		return pixel.Alpha(0)
+	}
+	bx, by, bw, bh := intBounds(bounds)
+	x, y := int(math.Floor(at.X))-bx, int(math.Floor(at.Y))-by
+	if x >= bw {
+		x = bw - 1
+	}
+	if y >= bh {
+		y = bh - 1
+	}
+
+	var pixels []uint8
+	call(func() {
+		tex := mp.Texture()
+		tex.Begin()
+		pixels = tex.Pixels(x, flipRow(y, bh), 1, 1)
+		tex.End()
+	})
+	return pixel.RGBA{
+		R: float64(pixels[0]) / 255,
+		G: float64(pixels[1]) / 255,
+		B: float64(pixels[2]) / 255,
+		A: float64(pixels[3]) / 255,
+	}
+}
@@ -0,0 +1,13 @@
+//go:build pixelgl_nolock
+// +build pixelgl_nolock
+
+package pixelgl
+
+import "runtime"
+
+// The mainthread package, which glhf and pixelgl use for the calls to the main thread, locks the
+// main goroutine in its init, which runs before this one. The locks nest, so a single unlock
+// undoes it, see LockMainThread.
+func init() {
+	runtime.UnlockOSThread()
+}
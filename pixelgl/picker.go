@@ -0,0 +1,176 @@
+package pixelgl
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// Picker is an off-screen Target used for pixel-perfect object picking. Instead of colors, it
+// stores an object ID for every pixel. Objects drawn later cover the objects drawn earlier, so the
+// ID under the cursor is always the ID of the top-most object, regardless of rotation, overlapping
+// or the shape of the objects.
+//
+// Wrap the draws of each object between Begin and End, then query the ID at a position with At:
+//
+//   picker.Clear()
+//   for _, u := range units {
+//       picker.Begin(u.id)
+//       u.sprite.Draw(picker, u.matrix)
+//       picker.End()
+//   }
+//   if id, ok := picker.At(win.MousePosition()); ok {
+//       units[id].highlighted = true
+//   }
+//
+// For hover highlighting, pick at the mouse position every frame and draw the hovered object
+// differently:
+//
+//   hovered, _ := picker.At(win.MousePosition())
+//   for _, u := range units {
+//       mask := pixel.Alpha(1)
+//       if u.id == hovered {
+//           mask = pixel.RGB(1, 1, 0.5)
+//       }
+//       u.sprite.DrawColorMask(win, u.matrix, mask)
+//   }
+//
+// Pixels whose alpha is below the alpha threshold (see SetAlphaThreshold) are not written, so
+// transparent parts of Pictures do not pick. Objects drawn outside of Begin and End occlude the
+// objects below them without being pickable themselves.
+//
+// ID 0 is reserved and means "no object".
+type Picker struct {
+	canvas *Canvas
+}
+
+var _ pixel.Target = (*Picker)(nil)
+
+// NewPicker creates a new empty Picker with the given bounds.
+func NewPicker(bounds pixel.Rect) *Picker {
+	p := &Picker{canvas: NewCanvas(bounds)}
+	p.canvas.SetComposeMethod(pixel.ComposeCopy)
+	p.canvas.SetColorMask(pixel.Alpha(0))
+	p.SetAlphaThreshold(0.5)
+	p.canvas.SetFragmentShader(pickerFragmentShader)
+	return p
+}
+
+// SetBounds resizes the Picker to the new bounds.
+func (p *Picker) SetBounds(bounds pixel.Rect) {
+	p.canvas.SetBounds(bounds)
+}
+
+// Bounds returns the rectangular bounds of the Picker.
+func (p *Picker) Bounds() pixel.Rect {
+	return p.canvas.Bounds()
+}
+
+// SetMatrix sets a Matrix that every point will be projected by. Use the same Matrix as for the
+// Target the objects are really drawn to.
+func (p *Picker) SetMatrix(m pixel.Matrix) {
+	p.canvas.SetMatrix(m)
+}
+
+// SetAlphaThreshold sets the minimal alpha a pixel needs to have to be written to the Picker. The
+// default is 0.5.
+func (p *Picker) SetAlphaThreshold(threshold float64) {
+	p.canvas.SetUniform("uAlphaThreshold", float32(threshold))
+}
+
+// Begin sets the ID of the object drawn in the following draws. The ID must not be 0.
+func (p *Picker) Begin(id uint32) {
+	if id == 0 {
+		panic("(*pixelgl.Picker).Begin: ID 0 is reserved")
+	}
+	p.canvas.SetColorMask(pixel.RGBA{
+		R: float64(id>>24&0xff) / 255,
+		G: float64(id>>16&0xff) / 255,
+		B: float64(id>>8&0xff) / 255,
+		A: float64(id&0xff) / 255,
+	})
+}
+
+// End ends the draws of the current object. Following draws will be occluders with no ID.
+func (p *Picker) End() {
+	p.canvas.SetColorMask(pixel.Alpha(0))
+}
+
+// Clear removes all objects from the Picker.
+func (p *Picker) Clear() {
+	p.canvas.Clear(pixel.Alpha(0))
+}
+
+// At returns the ID of the top-most object at the given position. If there's no object at the
+// position, ok is false.
+//
+// Only a single pixel is read back from the video memory (with glReadPixels on the framebuffer of
+// the Picker), so this is cheap enough to call every frame. It waits for the draws onto the Picker
+// to finish though, so query it after the draws of the frame, not in between them.
+func (p *Picker) At(at pixel.Vec) (id uint32, ok bool) {
+	bounds := p.canvas.Bounds()
+	if !bounds.Contains(at) {
+		return 0, false
+	}
+
+	var rgba [4]uint8
+	call(func() {
+		frame := p.canvas.Frame()
+		bw, bh := frame.Texture().Width(), frame.Texture().Height()
+		if bw == 0 || bh == 0 {
+			return
+		}
+		x := int(math.Floor((at.X - bounds.Min.X) / bounds.W() * float64(bw)))
+		y := int(math.Floor((at.Y - bounds.Min.Y) / bounds.H() * float64(bh)))
+		if x >= bw {
+			x = bw - 1
+		}
+		if y >= bh {
+			y = bh - 1
+		}
+		frame.Begin()
+		gl.ReadPixels(int32(x), int32(flipRow(y, bh)), 1, 1, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(&rgba[0]))
+		frame.End()
+	})
+
+	id = uint32(rgba[0])<<24 | uint32(rgba[1])<<16 | uint32(rgba[2])<<8 | uint32(rgba[3])
+	return id, id != 0
+}
+
+// MakeTriangles creates a specialized copy of the supplied Triangles that draws onto this Picker.
+func (p *Picker) MakeTriangles(t pixel.Triangles) pixel.TargetTriangles {
+	return p.canvas.MakeTriangles(t)
+}
+
+// MakePicture creates a specialized copy of the supplied Picture that draws onto this Picker.
+func (p *Picker) MakePicture(pic pixel.Picture) pixel.TargetPicture {
+	return p.canvas.MakePicture(pic)
+}
+
+var pickerFragmentShader = `
+#version 330 core
+
+in vec4  vColor;
+in vec2  vTexCoords;
+in float vIntensity;
+
+out vec4 fragColor;
+
+uniform vec4 uColorMask;
+uniform vec4 uTexBounds;
+uniform float uAlphaThreshold;
+uniform sampler2D uTexture;
+
+void main() {
+	float alpha = vColor.a;
+	if (vIntensity != 0) {
+		vec2 t = (vTexCoords - uTexBounds.xy) / uTexBounds.zw;
+		alpha = mix(alpha, alpha * texture(uTexture, t).a, vIntensity);
+	}
+	if (alpha < uAlphaThreshold) {
+		discard;
+	}
+	fragColor = uColorMask;
+}
+`
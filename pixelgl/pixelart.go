@@ -0,0 +1,68 @@
+package pixelgl
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+// newInternalCanvas creates the Canvas of the internal resolution set by WindowConfig.InternalWidth
+// and InternalHeight, or returns nil if there is none.
+func newInternalCanvas(cfg WindowConfig) *Canvas {
+	if cfg.InternalWidth <= 0 || cfg.InternalHeight <= 0 {
+		return nil
+	}
+	return NewCanvas(pixel.R(0, 0, float64(cfg.InternalWidth), float64(cfg.InternalHeight)))
+}
+
+// internalPlacement returns the integer scale of the internal Canvas in the pixels of the
+// framebuffer and the position of its center in the Window.
+func (w *Window) internalPlacement() (scale float64, center pixel.Vec) {
+	ib, fb := w.internal.Bounds(), w.framebufferBounds
+	scale = math.Max(1, math.Floor(math.Min(fb.W()/ib.W(), fb.H()/ib.H())))
+
+	// align the bottom-left corner to a pixel of the framebuffer
+	min := pixel.V(
+		math.Floor((fb.W()-ib.W()*scale)/2),
+		math.Floor((fb.H()-ib.H()*scale)/2),
+	)
+	pixelScale := w.pixelScale
+	if pixelScale <= 0 {
+		pixelScale = 1
+	}
+	center = w.bounds.Min.Add(min.Add(ib.Size().Scaled(scale / 2)).Scaled(1 / pixelScale))
+	return scale / pixelScale, center
+}
+
+// InternalMatrix returns the Matrix projecting the coordinates of the internal Canvas (see
+// WindowConfig.InternalWidth) to the coordinates of the Window. It's useful for mapping the mouse
+// into the game:
+//
+//   mouse := win.InternalMatrix().Unproject(win.MousePosition())
+//
+// Without an internal resolution, it returns pixel.IM.
+func (w *Window) InternalMatrix() pixel.Matrix {
+	if w.internal == nil {
+		return pixel.IM
+	}
+	scale, center := w.internalPlacement()
+	return pixel.IM.Moved(w.internal.Bounds().Center().Scaled(-1)).Scaled(pixel.ZV, scale).Moved(center)
+}
+
+// drawInternal clears the Window to black and draws the internal Canvas onto it, integer-scaled and
+// centered, without any smoothing. The settings of the Window's Canvas are left untouched.
+func (w *Window) drawInternal() {
+	c := w.canvas
+	mat, col, cmp, smooth := c.mat, c.col, c.cmp, c.smooth
+
+	c.SetMatrix(pixel.IM)
+	c.SetColorMask(nil)
+	c.SetComposeMethod(pixel.ComposeOver)
+	c.SetSmooth(false)
+
+	c.Clear(pixel.RGB(0, 0, 0))
+	scale, center := w.internalPlacement()
+	w.internal.Draw(c, pixel.IM.Scaled(pixel.ZV, scale).Moved(center))
+
+	c.mat, c.col, c.cmp, c.smooth = mat, col, cmp, smooth
+}
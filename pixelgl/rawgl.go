@@ -0,0 +1,120 @@
+package pixelgl
+
+import (
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// RawGL calls f on the main thread with the Canvas's framebuffer bound and the viewport set to the
+// whole Canvas. This is an escape hatch for mixing custom OpenGL code (e.g. an existing 3D
+// renderer) with the drawing done by this package.
+//
+// The following OpenGL state is saved before calling f and restored afterwards, so f may freely
+// change it:
+//
+//   - the bound program, vertex array, array buffer and read/draw framebuffers
+//   - the active texture unit and the 2D texture bound to it
+//   - the viewport and the scissor box
+//   - enabled blending, scissor test, depth test, stencil test and face culling
+//   - the blend function and the color write mask
+//   - the pack and unpack alignment
+//
+// Any other state changed by f (e.g. textures bound to other units, the depth function or the
+// element array buffer of a vertex array created by f) must be restored by f itself. The depth
+// and stencil buffers are not provided by the Canvas, attach your own to a framebuffer if you need
+// them.
+//
+// The content of the Canvas is assumed to be changed by f.
+func (c *Canvas) RawGL(f func()) {
+	c.gf.Dirty()
+
+	call(func() {
+		c.setGlhfBounds()
+		c.gf.Frame().Begin()
+
+		var state glState
+		state.save()
+		f()
+		state.restore()
+
+		c.gf.Frame().End()
+	})
+}
+
+// RawGL calls f on the main thread with the Window's framebuffer bound, see Canvas.RawGL.
+//
+// The Window draws onto an off-screen Canvas which gets copied to the screen in Update, so the
+// framebuffer bound during f is the one of that Canvas, not the default framebuffer.
+func (w *Window) RawGL(f func()) {
+	w.canvas.RawGL(f)
+}
+
+// glState is the OpenGL state saved and restored by RawGL.
+type glState struct {
+	program, vertexArray, arrayBuffer int32
+	readFramebuffer, drawFramebuffer  int32
+	activeTexture, texture            int32
+	viewport, scissorBox              [4]int32
+	blend, scissor, depth, stencil    bool
+	cullFace                          bool
+	blendFunc                         [4]int32
+	colorMask                         [4]bool
+	packAlignment, unpackAlignment    int32
+}
+
+func (s *glState) save() {
+	gl.GetIntegerv(gl.CURRENT_PROGRAM, &s.program)
+	gl.GetIntegerv(gl.VERTEX_ARRAY_BINDING, &s.vertexArray)
+	gl.GetIntegerv(gl.ARRAY_BUFFER_BINDING, &s.arrayBuffer)
+	gl.GetIntegerv(gl.READ_FRAMEBUFFER_BINDING, &s.readFramebuffer)
+	gl.GetIntegerv(gl.DRAW_FRAMEBUFFER_BINDING, &s.drawFramebuffer)
+	gl.GetIntegerv(gl.ACTIVE_TEXTURE, &s.activeTexture)
+	gl.GetIntegerv(gl.TEXTURE_BINDING_2D, &s.texture)
+	gl.GetIntegerv(gl.VIEWPORT, &s.viewport[0])
+	gl.GetIntegerv(gl.SCISSOR_BOX, &s.scissorBox[0])
+	s.blend = gl.IsEnabled(gl.BLEND)
+	s.scissor = gl.IsEnabled(gl.SCISSOR_TEST)
+	s.depth = gl.IsEnabled(gl.DEPTH_TEST)
+	s.stencil = gl.IsEnabled(gl.STENCIL_TEST)
+	s.cullFace = gl.IsEnabled(gl.CULL_FACE)
+	gl.GetIntegerv(gl.BLEND_SRC_RGB, &s.blendFunc[0])
+	gl.GetIntegerv(gl.BLEND_DST_RGB, &s.blendFunc[1])
+	gl.GetIntegerv(gl.BLEND_SRC_ALPHA, &s.blendFunc[2])
+	gl.GetIntegerv(gl.BLEND_DST_ALPHA, &s.blendFunc[3])
+	gl.GetBooleanv(gl.COLOR_WRITEMASK, &s.colorMask[0])
+	gl.GetIntegerv(gl.PACK_ALIGNMENT, &s.packAlignment)
+	gl.GetIntegerv(gl.UNPACK_ALIGNMENT, &s.unpackAlignment)
+}
+
+func (s *glState) restore() {
+	gl.UseProgram(uint32(s.program))
+	gl.BindVertexArray(uint32(s.vertexArray))
+	gl.BindBuffer(gl.ARRAY_BUFFER, uint32(s.arrayBuffer))
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, uint32(s.readFramebuffer))
+	gl.BindFramebuffer(gl.DRAW_FRAMEBUFFER, uint32(s.drawFramebuffer))
+	gl.ActiveTexture(uint32(s.activeTexture))
+	gl.BindTexture(gl.TEXTURE_2D, uint32(s.texture))
+	gl.Viewport(s.viewport[0], s.viewport[1], s.viewport[2], s.viewport[3])
+	gl.Scissor(s.scissorBox[0], s.scissorBox[1], s.scissorBox[2], s.scissorBox[3])
+	setEnabled(gl.BLEND, s.blend)
+	setEnabled(gl.SCISSOR_TEST, s.scissor)
+	setEnabled(gl.DEPTH_TEST, s.depth)
+	setEnabled(gl.STENCIL_TEST, s.stencil)
+	setEnabled(gl.CULL_FACE, s.cullFace)
+	gl.BlendFuncSeparate(
+		uint32(s.blendFunc[0]),
+		uint32(s.blendFunc[1]),
+		uint32(s.blendFunc[2]),
+		uint32(s.blendFunc[3]),
+	)
+	gl.ColorMask(s.colorMask[0], s.colorMask[1], s.colorMask[2], s.colorMask[3])
+	gl.PixelStorei(gl.PACK_ALIGNMENT, s.packAlignment)
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, s.unpackAlignment)
+}
+
+func setEnabled(capability uint32, enabled bool) {
+	if enabled {
+		gl.Enable(capability)
+	} else {
+		gl.Disable(capability)
+	}
+}
@@ -1,11 +1,65 @@
 package pixelgl
 
 import (
+	"fmt"
+	"runtime"
+	"sync"
+
 	"github.com/faiface/mainthread"
 	"github.com/go-gl/glfw/v3.2/glfw"
 	"github.com/pkg/errors"
 )
 
+// callQueueCap is the default capacity of the queue of the calls to the main thread, that is how
+// many non-blocking calls can be made before they start to wait for the main thread.
+const callQueueCap = 16
+
+// mainLoop is the state of the running Run. The calls to the main thread go through the queue of
+// the mainthread package, which glhf uses too, e.g. to delete the OpenGL objects from finalizers.
+var mainLoop struct {
+	sync.RWMutex
+	running bool
+	runs    uint64 // the number of calls to Run so far, identifies the current one
+}
+
+// callQueueSize is the capacity of the queue of the next Run, see SetQueueSize. It's guarded by
+// mainLoop.
+var callQueueSize = callQueueCap
+
+// LockMainThread locks the calling goroutine to its current OS thread. Call it from an init
+// function of the main package, which runs on the main thread, to keep the main function there:
+//
+//   func init() {
+//       pixelgl.LockMainThread()
+//   }
+//
+// By default pixelgl does this itself when it's initialized, so calling LockMainThread isn't
+// needed. Building with the pixelgl_nolock tag turns this off, for applications which embed
+// pixelgl next to other libraries or a runtime that manages the threads themselves, and which
+// don't want the goroutine running the initialization to stay locked. The tag also undoes the lock
+// made by the init of the mainthread package. Run locks the thread it's called from in any case, so
+// with the tag the main thread only needs to be locked if something could move the main goroutine
+// away from it before Run is called.
+func LockMainThread() {
+	runtime.LockOSThread()
+}
+
+// SetQueueSize sets the capacity of the queue of the calls to the main thread, that is how many
+// calls which don't wait for the result (such as draws) can be queued before they start to wait
+// for the main thread. The default is 16. A larger queue helps when many goroutines make such calls
+// at once, e.g. when streaming textures, at the cost of the queued calls lagging further behind.
+//
+// The size is applied when Run starts, so call SetQueueSize before Run. Calling it while Run is
+// running doesn't affect the running Run, only the next one. SetQueueSize panics if n is negative.
+func SetQueueSize(n int) {
+	if n < 0 {
+		panic(fmt.Errorf("SetQueueSize: negative size %d", n))
+	}
+	mainLoop.Lock()
+	callQueueSize = n
+	mainLoop.Unlock()
+}
+
 // Run is essentially the main function of PixelGL. It exists mainly due to the technical
 // limitations of OpenGL and operating systems. In short, all graphics and window manipulating calls
 // must be done from the main thread. Run makes this possible.
@@ -23,11 +77,90 @@ import (
 //
 // You can spawn any number of goroutines from your run function and interact with PixelGL
 // concurrently. The only condition is that the Run function is called from your main function.
+//
+// The thread Run is called from stays locked to the calling goroutine until Run returns, see
+// LockMainThread.
+//
+// Run may be called again after it returned, e.g. to tear down all the graphics and create new
+// Windows later. Everything created during the previous Run, such as Windows, Canvases and
+// Pictures, is gone and must not be used anymore. Calls made after Run returned panic. Calling Run
+// while it's already running panics too. The OpenGL objects are deleted by their finalizers through
+// the queue of the main thread, so if Run is going to be called again, drop the references to them
+// and call runtime.GC before the run function returns, otherwise the finalizers may block.
 func Run(run func()) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	startMainLoop()
 	err := glfw.Init()
 	if err != nil {
+		mainLoop.Lock()
+		mainLoop.running = false
+		mainLoop.Unlock()
 		panic(errors.Wrap(err, "failed to initialize GLFW"))
 	}
 	defer glfw.Terminate()
-	mainthread.Run(run)
+
+	mainthread.Run(func() {
+		run()
+		stopMainLoop()
+	})
+}
+
+// startMainLoop sets up the state of a new Run.
+func startMainLoop() {
+	mainLoop.Lock()
+	defer mainLoop.Unlock()
+	if mainLoop.running {
+		panic(errors.New("pixelgl: Run is already running"))
+	}
+	mainLoop.running = true
+	mainLoop.runs++
+	mainthread.CallQueueCap = callQueueSize
+}
+
+// stopMainLoop tears down the state of the Run returning, so that Run can be called again. The
+// calls already in the queue still run, so that no caller waits forever, the later ones panic.
+//
+// Note: must be called from the function passed to mainthread.Run after the run function
+// returned, while the main thread still processes the calls.
+func stopMainLoop() {
+	// wait until no call is being sent, the main thread makes room for the ones waiting
+	mainLoop.Lock()
+	mainLoop.running = false
+	mainLoop.Unlock()
+
+	// the queue is FIFO, so all the calls sent before run after this one
+	mainthread.Call(func() {
+		currWin = nil
+		asyncPanic = nil
+		resetTextureBudget()
+	})
+
+	deferred.Lock()
+	deferred.enabled, deferred.paused, deferred.cmds = false, false, nil
+	deferred.Unlock()
+	resumed.Broadcast()
+}
+
+// dispatch sends f to the main thread without waiting for it to run. It panics if Run is not
+// running.
+func dispatch(f func()) {
+	mainLoop.RLock()
+	defer mainLoop.RUnlock()
+	if !mainLoop.running {
+		panic(errors.New("pixelgl: did not call Run"))
+	}
+	mainthread.CallNonBlock(f)
+}
+
+// currentRun identifies the current call to Run, see Window.Destroy. It returns 0 if Run is not
+// running.
+func currentRun() uint64 {
+	mainLoop.RLock()
+	defer mainLoop.RUnlock()
+	if !mainLoop.running {
+		return 0
+	}
+	return mainLoop.runs
 }
@@ -0,0 +1,151 @@
+package pixelgl
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// mainFuncs are the functions run on the main thread by TestMain, see onMainThread.
+var mainFuncs = make(chan func())
+
+// TestMain runs the tests on another goroutine and keeps the main goroutine, which is locked to the
+// main thread since the initialization, serving onMainThread.
+func TestMain(m *testing.M) {
+	done := make(chan int)
+	go func() {
+		done <- m.Run()
+	}()
+	for {
+		select {
+		case f := <-mainFuncs:
+			f()
+		case code := <-done:
+			os.Exit(code)
+		}
+	}
+}
+
+// onMainThread runs f on the main thread and waits for it. A panic in f is raised again on the
+// calling goroutine.
+func onMainThread(f func()) {
+	done := make(chan interface{})
+	mainFuncs <- func() {
+		defer func() {
+			done <- recover()
+		}()
+		f()
+	}
+	if r := <-done; r != nil {
+		panic(r)
+	}
+}
+
+// runOrSkip calls Run with the run function on the main thread. It skips the test if GLFW can't be
+// initialized, e.g. without a display.
+func runOrSkip(tb testing.TB, run func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			if strings.Contains(fmt.Sprint(r), "failed to initialize GLFW") {
+				tb.Skip(r)
+			}
+			panic(r)
+		}
+	}()
+	onMainThread(func() {
+		Run(run)
+	})
+}
+
+func TestRunTwice(t *testing.T) {
+	for i := 0; i < 2; i++ {
+		ran, called := false, false
+		runOrSkip(t, func() {
+			ran = true
+			call(func() {
+				called = true
+			})
+		})
+		if !ran || !called {
+			t.Fatalf("Run %d: ran the run function %v, called the main thread %v", i+1, ran, called)
+		}
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("call after Run returned didn't panic")
+			}
+		}()
+		call(func() {})
+	}()
+}
+
+func TestCallPanic(t *testing.T) {
+	runOrSkip(t, func() {
+		func() {
+			defer func() {
+				if r := recover(); r != "boom" {
+					t.Errorf("call recovered %v, want boom", r)
+				}
+			}()
+			call(func() {
+				panic("boom")
+			})
+		}()
+
+		// the main thread keeps running and the next call is served
+		callNonBlock(func() {
+			panic("async boom")
+		})
+		func() {
+			defer func() {
+				if r := recover(); !strings.Contains(fmt.Sprint(r), "async boom") {
+					t.Errorf("call after a panicking callNonBlock recovered %v", r)
+				}
+			}()
+			call(func() {})
+		}()
+
+		ok := false
+		call(func() {
+			ok = true
+		})
+		if !ok {
+			t.Errorf("call didn't run after the panics")
+		}
+	})
+}
+
+func BenchmarkCallNonBlock(b *testing.B) {
+	defer SetQueueSize(callQueueCap)
+
+	// a flood of draws from several goroutines, the main thread being the bottleneck
+	const goroutines = 8
+	for _, size := range []int{0, 16, 256, 4096} {
+		b.Run(fmt.Sprintf("QueueSize%d", size), func(b *testing.B) {
+			SetQueueSize(size)
+			runOrSkip(b, func() {
+				var wg sync.WaitGroup
+				b.ResetTimer()
+				for g := 0; g < goroutines; g++ {
+					n := b.N / goroutines
+					if g < b.N%goroutines {
+						n++
+					}
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						for i := 0; i < n; i++ {
+							callNonBlock(func() {})
+						}
+					}()
+				}
+				wg.Wait()
+				call(func() {}) // the queue is FIFO, so this waits for all the calls
+			})
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package pixelgl
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/faiface/pixel"
+)
+
+// resourceKind is a kind of OpenGL objects counted by ResourceStats.
+type resourceKind int
+
+const (
+	textureResource resourceKind = iota
+	framebufferResource
+	bufferResource
+	numResourceKinds
+)
+
+var resourceCounts [numResourceKinds]int64
+
+// resourceRegistry holds the memory usage of the live resourceTrackers for MemoryStats. It doesn't
+// reference the trackers, so that they can be garbage collected.
+var resourceRegistry struct {
+	mu     sync.Mutex
+	nextID uint64
+	byID   map[uint64]*pixel.MemoryUsage
+}
+
+// resourceTracker counts OpenGL objects as alive until it's garbage collected and accounts for
+// their video memory.
+//
+// The objects created by glhf are deleted by their own finalizers, which can't be hooked into.
+// Instead, a resourceTracker is kept next to the glhf object by its owner (e.g. a GLFrame) and
+// replaced whenever the object is, so that both become unreachable at the same time.
+type resourceTracker struct {
+	kinds []resourceKind
+	id    uint64
+	usage *pixel.MemoryUsage
+}
+
+func trackResources(category, name string, bytes int64, kinds ...resourceKind) *resourceTracker {
+	for _, k := range kinds {
+		atomic.AddInt64(&resourceCounts[k], 1)
+	}
+
+	resourceRegistry.mu.Lock()
+	if resourceRegistry.byID == nil {
+		resourceRegistry.byID = make(map[uint64]*pixel.MemoryUsage)
+	}
+	rt := &resourceTracker{
+		kinds: kinds,
+		id:    resourceRegistry.nextID,
+		usage: &pixel.MemoryUsage{Category: category, Name: name, Bytes: bytes},
+	}
+	resourceRegistry.nextID++
+	resourceRegistry.byID[rt.id] = rt.usage
+	resourceRegistry.mu.Unlock()
+
+	runtime.SetFinalizer(rt, (*resourceTracker).release)
+	return rt
+}
+
+// release stops counting the objects, when they're garbage collected or deleted explicitly.
+func (rt *resourceTracker) release() {
+	runtime.SetFinalizer(rt, nil)
+	for _, k := range rt.kinds {
+		atomic.AddInt64(&resourceCounts[k], -1)
+	}
+	resourceRegistry.mu.Lock()
+	delete(resourceRegistry.byID, rt.id)
+	resourceRegistry.mu.Unlock()
+}
+
+// textureName is the name of a texture in MemoryStats.
+func textureName(w, h int) string {
+	return fmt.Sprintf("%dx%d RGBA8", w, h)
+}
+
+func (rt *resourceTracker) setBytes(bytes int64) {
+	atomic.StoreInt64(&rt.usage.Bytes, bytes)
+}
+
+// ResourceStats returns the number of OpenGL textures, framebuffers and vertex buffers currently
+// held by pixelgl, e.g. to find leaks in long-running applications:
+//
+//   runtime.GC()
+//   textures, framebuffers, buffers := pixelgl.ResourceStats()
+//
+// The objects are counted from their creation until their owner (a Canvas, a GLFrame, a GLPicture
+// or GLTriangles) is garbage collected, which is when they get deleted too. So the numbers only go
+// down after a garbage collection, and they are only accurate if the glhf objects returned by
+// methods such as Texture are not kept after their owner. Each Canvas takes a framebuffer and a
+// texture, plus a texture per additional color attachment.
+func ResourceStats() (textures, framebuffers, buffers int) {
+	return int(atomic.LoadInt64(&resourceCounts[textureResource])),
+		int(atomic.LoadInt64(&resourceCounts[framebufferResource])),
+		int(atomic.LoadInt64(&resourceCounts[bufferResource]))
+}
+
+// MemoryStats returns the video memory held by the OpenGL objects counted by ResourceStats, one
+// MemoryUsage per owner, sorted by the Bytes from the largest. The categories are "texture"
+// (GLPictures), "frame" (the textures of Canvases and GLFrames), "attachment" (the additional
+// color attachments of Canvases) and "vertex buffer" (GLTriangles). The names include the size and
+// the format of the textures, which are all RGBA8 without mipmaps.
+//
+// The sizes are computed from the dimensions and the formats, the actual usage depends on the
+// driver. Print them with pixel.WriteMemoryStats, along with pixel.MemoryStats for the CPU side:
+//
+//   runtime.GC()
+//   pixel.WriteMemoryStats(os.Stderr, append(pixel.MemoryStats(), pixelgl.MemoryStats()...))
+func MemoryStats() []pixel.MemoryUsage {
+	resourceRegistry.mu.Lock()
+	usages := make([]pixel.MemoryUsage, 0, len(resourceRegistry.byID))
+	for _, u := range resourceRegistry.byID {
+		usages = append(usages, pixel.MemoryUsage{
+			Category: u.Category,
+			Name:     u.Name,
+			Bytes:    atomic.LoadInt64(&u.Bytes),
+		})
+	}
+	resourceRegistry.mu.Unlock()
+
+	sort.SliceStable(usages, func(i, j int) bool {
+		if usages[i].Bytes != usages[j].Bytes {
+			return usages[i].Bytes > usages[j].Bytes
+		}
+		return usages[i].Name < usages[j].Name
+	})
+	return usages
+}
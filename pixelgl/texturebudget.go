@@ -0,0 +1,161 @@
+package pixelgl
+
+import (
+	"container/list"
+	"runtime"
+	"sync"
+
+	"github.com/faiface/glhf"
+	"github.com/faiface/pixel"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// textureBudget holds the textures which can be evicted, see SetTextureBudget.
+var textureBudget struct {
+	mu  sync.Mutex
+	lru textureLRU
+}
+
+// textureLRU is the bookkeeping of the texture budget: the entries with a texture in the order
+// they were drawn and the video memory they take.
+type textureLRU struct {
+	limit int64 // 0 for no limit
+	used  int64
+	list  list.List // of *budgetEntry, the least recently drawn at the front
+}
+
+// budgetEntry is the texture of a glPicture subject to the texture budget. It doesn't reference its
+// glPicture, so that the glPicture can be garbage collected while the entry is in the LRU list.
+type budgetEntry struct {
+	tex     *glhf.Texture // nil if evicted
+	tracker *resourceTracker
+	bytes   int64
+	elem    *list.Element // nil if evicted
+}
+
+// add adds the entry as the most recently drawn one and returns the entries which must be evicted
+// to fit the limit, removed from the list already. The added entry is never evicted.
+func (l *textureLRU) add(e *budgetEntry) (evict []*budgetEntry) {
+	e.elem = l.list.PushBack(e)
+	l.used += e.bytes
+	for l.limit > 0 && l.used > l.limit {
+		front := l.list.Front().Value.(*budgetEntry)
+		if front == e {
+			break
+		}
+		l.remove(front)
+		evict = append(evict, front)
+	}
+	return evict
+}
+
+// touch marks the entry as the most recently drawn one.
+func (l *textureLRU) touch(e *budgetEntry) {
+	l.list.MoveToBack(e.elem)
+}
+
+// remove removes the entry from the list, if it's there.
+func (l *textureLRU) remove(e *budgetEntry) {
+	if e.elem == nil {
+		return
+	}
+	l.list.Remove(e.elem)
+	l.used -= e.bytes
+	e.elem = nil
+}
+
+// SetTextureBudget limits the video memory taken by the textures of the Pictures drawn onto the
+// Canvases and Windows, e.g. for asset-heavy games on machines with little video memory. A budget
+// of 0 (the default) means no limit.
+//
+// When a texture is uploaded and the textures exceed the budget, the textures of the least
+// recently drawn Pictures are deleted until they fit. The pixels of an evicted texture stay in the
+// memory, so it's uploaded again the next time its Picture is drawn. A texture being drawn is never
+// evicted, so the budget can be exceeded by a single Picture larger than the budget.
+//
+// The budget applies to the textures which the Targets make for plain Pictures, such as
+// PictureData, e.g. when drawing a Sprite or a Batch. GLPictures (see NewGLPicture), Canvases and
+// the Window itself are never evicted.
+//
+// The budget is checked whenever a texture is uploaded, so lowering it takes effect with the next
+// upload.
+func SetTextureBudget(bytes int) {
+	textureBudget.mu.Lock()
+	textureBudget.lru.limit = int64(bytes)
+	textureBudget.mu.Unlock()
+}
+
+// TextureBudget returns the budget set by SetTextureBudget and the video memory currently taken by
+// the textures subject to it.
+func TextureBudget() (budget, used int) {
+	textureBudget.mu.Lock()
+	defer textureBudget.mu.Unlock()
+	return int(textureBudget.lru.limit), int(textureBudget.lru.used)
+}
+
+// newBudgetedGLPicture creates a GLPicture of the Picture with its texture subject to the texture
+// budget.
+func newBudgetedGLPicture(p pixel.Picture) *glPicture {
+	gp := newGLPicture(p)
+	_, _, bw, bh := intBounds(gp.bounds)
+	gp.budget = &budgetEntry{bytes: 4 * int64(bw) * int64(bh)}
+	call(func() {
+		gp.budgetTexture()
+	})
+	runtime.SetFinalizer(gp, func(gp *glPicture) {
+		// the texture, if any, is deleted by its own finalizer
+		textureBudget.mu.Lock()
+		textureBudget.lru.remove(gp.budget)
+		textureBudget.mu.Unlock()
+	})
+	return gp
+}
+
+// budgetTexture returns the texture of the budgeted glPicture, uploading it again if it was
+// evicted, and marks it as the most recently drawn.
+//
+// Note: must be called inside the main thread.
+func (gp *glPicture) budgetTexture() *glhf.Texture {
+	textureBudget.mu.Lock()
+	defer textureBudget.mu.Unlock()
+
+	e := gp.budget
+	if e.tex != nil {
+		textureBudget.lru.touch(e)
+		return e.tex
+	}
+
+	_, _, bw, bh := intBounds(gp.bounds)
+	e.tex = glhf.NewTexture(bw, bh, false, gp.pixels)
+	e.tracker = trackResources("texture", textureName(bw, bh), e.bytes, textureResource)
+	if debugOutput {
+		labelObject(gl.TEXTURE, e.tex.ID(), autoLabel("Picture", bw, bh))
+	}
+	for _, ev := range textureBudget.lru.add(e) {
+		deleteTexture(ev.tex)
+		ev.tracker.release()
+		ev.tex, ev.tracker = nil, nil
+	}
+	return e.tex
+}
+
+// deleteTexture deletes the texture right away, instead of leaving it to its finalizer.
+//
+// Note: must be called inside the main thread.
+func deleteTexture(tex *glhf.Texture) {
+	// glhf's finalizer would delete the name again, when it may belong to another texture already
+	runtime.SetFinalizer(tex, nil)
+	id := tex.ID()
+	gl.DeleteTextures(1, &id)
+}
+
+// resetTextureBudget forgets all the textures, when they're gone with the OpenGL context.
+func resetTextureBudget() {
+	textureBudget.mu.Lock()
+	defer textureBudget.mu.Unlock()
+	for textureBudget.lru.list.Len() > 0 {
+		e := textureBudget.lru.list.Front().Value.(*budgetEntry)
+		textureBudget.lru.remove(e)
+		e.tex, e.tracker = nil, nil
+	}
+}
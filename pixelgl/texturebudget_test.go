@@ -0,0 +1,50 @@
+package pixelgl
+
+import "testing"
+
+func TestTextureLRU(t *testing.T) {
+	var l textureLRU
+	l.limit = 100
+	a, b, c := &budgetEntry{bytes: 40}, &budgetEntry{bytes: 40}, &budgetEntry{bytes: 40}
+
+	if evict := l.add(a); len(evict) != 0 {
+		t.Fatalf("evicted %v under the budget", evict)
+	}
+	if evict := l.add(b); len(evict) != 0 {
+		t.Fatalf("evicted %v under the budget", evict)
+	}
+	l.touch(a)
+
+	// b is the least recently drawn now
+	evict := l.add(c)
+	if len(evict) != 1 || evict[0] != b {
+		t.Fatalf("evicted %v, want only b", evict)
+	}
+	if b.elem != nil || l.used != 80 || l.list.Len() != 2 {
+		t.Errorf("after eviction: b in the list %v, used %d, %d entries", b.elem != nil, l.used, l.list.Len())
+	}
+
+	// an entry larger than the budget evicts everything else, but not itself
+	big := &budgetEntry{bytes: 500}
+	evict = l.add(big)
+	if len(evict) != 2 || evict[0] != a || evict[1] != c {
+		t.Errorf("evicted %v, want a and c", evict)
+	}
+	if l.used != 500 || l.list.Len() != 1 {
+		t.Errorf("used %d with %d entries, want 500 with 1", l.used, l.list.Len())
+	}
+
+	l.remove(big)
+	l.remove(big)
+	if l.used != 0 || l.list.Len() != 0 {
+		t.Errorf("used %d with %d entries after removing all, want 0", l.used, l.list.Len())
+	}
+
+	// no limit
+	l.limit = 0
+	for i := 0; i < 10; i++ {
+		if evict := l.add(&budgetEntry{bytes: 1000}); len(evict) != 0 {
+			t.Fatalf("evicted %v without a limit", evict)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package pixelgl
+
+import "github.com/go-gl/glfw/v3.2/glfw"
+
+// Time returns the time in seconds of the GLFW timer. The timer starts at zero when Run
+// initializes GLFW, unless it's changed by SetTime. Only call this function from within Run.
+//
+// This is the clock GLFW uses internally, it has a high resolution on all platforms. It's
+// independent of the time package, which the Window uses to measure the durations of frames (see
+// Window.FrameTimes), so don't mix the two clocks: take a delta time either from time.Since or from
+// the differences of Time, not from both.
+func Time() float64 {
+	var t float64
+	call(func() {
+		t = glfw.GetTime()
+	})
+	return t
+}
+
+// SetTime sets the GLFW timer to t seconds, see Time. The timer continues counting from t. It must
+// be positive and less than 18446744073, which is roughly 584.5 years. Only call this function from
+// within Run.
+func SetTime(t float64) {
+	call(func() {
+		glfw.SetTime(t)
+	})
+}
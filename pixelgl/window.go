@@ -4,9 +4,10 @@ import (
 	"image"
 	"image/color"
 	"runtime"
+	"sync/atomic"
+	"time"
 
 	"github.com/faiface/glhf"
-	"github.com/faiface/mainthread"
 	"github.com/faiface/pixel"
 	"github.com/go-gl/glfw/v3.2/glfw"
 	"github.com/pkg/errors"
@@ -49,32 +50,74 @@ type WindowConfig struct {
 	// VSync (vertical synchronization) synchronizes Window's framerate with the framerate of
 	// the monitor.
 	VSync bool
+
+	// FrameTimeHistory is the number of the last frame durations kept by the Window, see
+	// Window.FrameTimes. Defaults to 120 frames if zero.
+	FrameTimeHistory int
+
+	// DeferredFlush enables the deferred flush mode, see Window.SetDeferredFlush.
+	DeferredFlush bool
+
+	// InternalWidth and InternalHeight set a low internal resolution for pixel art. If both are
+	// set, Window.Canvas returns a Canvas of this size, which is the main target to draw onto.
+	// Every Update clears the Window to black and draws the Canvas onto it, scaled by the largest
+	// integer factor that fits, centered and without smoothing. Anything drawn directly onto the
+	// Window is overdrawn, except by the pre-swap hooks, which can draw on top at the full
+	// resolution. See Window.InternalMatrix for mapping the mouse position.
+	InternalWidth, InternalHeight int
 }
 
 // Window is a window handler. Use this type to manipulate a window (input, drawing, etc.).
 type Window struct {
 	window *glfw.Window
+	cfg    WindowConfig // for recreating the window, see recoverContext
+	run    uint64       // the Run the window was created in, see currentRun
 
-	bounds             pixel.Rect
-	canvas             *Canvas
-	vsync              bool
-	cursorVisible      bool
-	cursorInsideWindow bool
+	bounds        pixel.Rect
+	canvas        *Canvas
+	internal      *Canvas // the Canvas of the internal resolution, if any
+	vsync         bool
+	cursorVisible bool
+
+	framebufferBounds pixel.Rect
+	pixelScale        float64 // framebuffer pixels per unit of bounds
 
 	// need to save these to correctly restore a fullscreen window
 	restore struct {
 		xpos, ypos, width, height int
 	}
 
-	prevInp, currInp, tempInp struct {
+	input   atomic.Value // InputState
+	tempInp struct {
 		mouse   pixel.Vec
 		buttons [KeyLast + 1]bool
 		repeat  [KeyLast + 1]bool
+		times   [KeyLast + 1]float64
 		scroll  pixel.Vec
 		typed   string
+		inside  bool
+		entered []bool
 	}
 
+	inputTimes   [KeyLast + 1]float64
+	doubleClicks doubleClicks
+
+	cursorEnterCallback func(entered bool)
+	cursorAnim          cursorAnimation
+	cursorShape         StandardCursor
+	cursorShapes        map[StandardCursor]*glfw.Cursor
+
+	contextLostCallback func()
+	generation          uint64
+
 	prevJoy, currJoy, tempJoy joystickState
+
+	frames frameTimes
+
+	hooks struct {
+		preInput, postInput, preSwap, postSwap hookList
+	}
+	hookPanicCallback func(err error)
 }
 
 var currWin *Window
@@ -83,68 +126,33 @@ var currWin *Window
 //
 // If Window creation fails, an error is returned (e.g. due to unavailable graphics device).
 func NewWindow(cfg WindowConfig) (*Window, error) {
-	bool2int := map[bool]int{
-		true:  glfw.True,
-		false: glfw.False,
-	}
-
-	w := &Window{bounds: cfg.Bounds, cursorVisible: true}
-
-	err := mainthread.CallErr(func() error {
-		var err error
-
-		glfw.WindowHint(glfw.ContextVersionMajor, 3)
-		glfw.WindowHint(glfw.ContextVersionMinor, 3)
-		glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-		glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
-
-		glfw.WindowHint(glfw.Resizable, bool2int[cfg.Resizable])
-		glfw.WindowHint(glfw.Decorated, bool2int[!cfg.Undecorated])
+	w := &Window{bounds: cfg.Bounds, cursorVisible: true, cfg: cfg, run: currentRun()}
 
+	err := callErr(func() error {
 		var share *glfw.Window
 		if currWin != nil {
 			share = currWin.window
 		}
 		_, _, width, height := intBounds(cfg.Bounds)
-		w.window, err = glfw.CreateWindow(
-			width,
-			height,
-			cfg.Title,
-			nil,
-			share,
-		)
-		if err != nil {
-			return err
-		}
-
-		// enter the OpenGL context
-		w.begin()
-		glhf.Init()
-		w.end()
-
-		return nil
+		return w.create(width, height, nil, share)
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "creating window failed")
 	}
 
-	if len(cfg.Icon) > 0 {
-		imgs := make([]image.Image, len(cfg.Icon))
-		for i, icon := range cfg.Icon {
-			pic := pixel.PictureDataFromPicture(icon)
-			imgs[i] = pic.Image()
-		}
-		mainthread.Call(func() {
-			w.window.SetIcon(imgs)
-		})
-	}
+	w.setIcon(cfg.Icon)
 
 	w.SetVSync(cfg.VSync)
+	w.SetFrameTimeHistory(cfg.FrameTimeHistory)
+	if cfg.DeferredFlush {
+		w.SetDeferredFlush(true)
+	}
 
 	w.initInput()
 	w.SetMonitor(cfg.Monitor)
 
 	w.canvas = NewCanvas(cfg.Bounds)
+	w.internal = newInternalCanvas(cfg)
 	w.Update()
 
 	runtime.SetFinalizer(w, (*Window).Destroy)
@@ -152,29 +160,100 @@ func NewWindow(cfg WindowConfig) (*Window, error) {
 	return w, nil
 }
 
+// create creates the GLFW window with its OpenGL context and initializes the context.
+//
+// Note: must be called inside the main thread.
+func (w *Window) create(width, height int, monitor *glfw.Monitor, share *glfw.Window) error {
+	bool2int := map[bool]int{
+		true:  glfw.True,
+		false: glfw.False,
+	}
+
+	glfw.WindowHint(glfw.ContextVersionMajor, 3)
+	glfw.WindowHint(glfw.ContextVersionMinor, 3)
+	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
+	glfw.WindowHint(glfw.ContextRobustness, glfw.LoseContextOnReset)
+
+	glfw.WindowHint(glfw.Resizable, bool2int[w.cfg.Resizable])
+	glfw.WindowHint(glfw.Decorated, bool2int[!w.cfg.Undecorated])
+
+	window, err := glfw.CreateWindow(width, height, w.cfg.Title, monitor, share)
+	if err != nil {
+		return err
+	}
+	w.window = window
+
+	// enter the OpenGL context
+	currWin = nil
+	w.begin()
+	glhf.Init()
+	initDebugOutput()
+	initRobustness()
+	w.end()
+
+	return nil
+}
+
+func (w *Window) setIcon(icon []pixel.Picture) {
+	if len(icon) == 0 {
+		return
+	}
+	imgs := make([]image.Image, len(icon))
+	for i, icon := range icon {
+		pic := pixel.PictureDataFromPicture(icon)
+		imgs[i] = pic.Image()
+	}
+	call(func() {
+		w.window.SetIcon(imgs)
+	})
+}
+
 // Destroy destroys the Window. The Window can't be used any further.
+//
+// The Windows are destroyed when Run returns, calling Destroy on them afterwards does nothing.
 func (w *Window) Destroy() {
-	mainthread.Call(func() {
+	if w.run != currentRun() {
+		return
+	}
+	call(func() {
 		w.window.Destroy()
 	})
 }
 
 // Update swaps buffers and polls events. Call this method at the end of each frame.
 func (w *Window) Update() {
-	mainthread.Call(func() {
+	call(func() {
 		_, _, oldW, oldH := intBounds(w.bounds)
 		newW, newH := w.window.GetSize()
 		w.bounds = w.bounds.ResizedMin(w.bounds.Size().Add(pixel.V(
 			float64(newW-oldW),
 			float64(newH-oldH),
 		)))
+
+		fbW, fbH := w.window.GetFramebufferSize()
+		w.framebufferBounds = pixel.R(0, 0, float64(fbW), float64(fbH))
+		if newW > 0 && fbW > 0 {
+			w.pixelScale = float64(fbW) / float64(newW)
+		}
 	})
 
+	// the canvas has the resolution of the framebuffer, so that it's not stretched on HiDPI screens
+	w.canvas.gf.setScale(w.pixelScale)
 	w.canvas.SetBounds(w.bounds)
+	if w.internal != nil {
+		w.drawInternal()
+	}
+
+	w.hooks.preSwap.run(w, "pre-swap")
 
-	mainthread.Call(func() {
+	var lost bool
+	call(func() {
 		w.begin()
 
+		// the group ends before SwapBuffers, because graphics debuggers start a new frame there
+		pushDebugGroup("Window.Update")
+
 		framebufferWidth, framebufferHeight := w.window.GetFramebufferSize()
 		glhf.Bounds(0, 0, framebufferWidth, framebufferHeight)
 
@@ -187,15 +266,27 @@ func (w *Window) Update() {
 		)
 		w.canvas.gf.Frame().End()
 
+		popDebugGroup()
+
 		if w.vsync {
 			glfw.SwapInterval(1)
 		} else {
 			glfw.SwapInterval(0)
 		}
 		w.window.SwapBuffers()
+		w.updateCursor(time.Now())
+		lost = contextLost()
 		w.end()
 	})
 
+	if lost {
+		w.recoverContext()
+	}
+
+	w.frames.tick(time.Now())
+
+	w.hooks.postSwap.run(w, "post-swap")
+
 	w.UpdateInput()
 }
 
@@ -204,7 +295,7 @@ func (w *Window) Update() {
 // This is useful when overriding the user's attempt to close the Window, or just to close the
 // Window from within the program.
 func (w *Window) SetClosed(closed bool) {
-	mainthread.Call(func() {
+	call(func() {
 		w.window.SetShouldClose(closed)
 	})
 }
@@ -214,7 +305,7 @@ func (w *Window) SetClosed(closed bool) {
 // The closed flag is automatically set when a user attempts to close the Window.
 func (w *Window) Closed() bool {
 	var closed bool
-	mainthread.Call(func() {
+	call(func() {
 		closed = w.window.ShouldClose()
 	})
 	return closed
@@ -222,7 +313,8 @@ func (w *Window) Closed() bool {
 
 // SetTitle changes the title of the Window.
 func (w *Window) SetTitle(title string) {
-	mainthread.Call(func() {
+	w.cfg.Title = title
+	call(func() {
 		w.window.SetTitle(title)
 	})
 }
@@ -231,7 +323,7 @@ func (w *Window) SetTitle(title string) {
 // of the window will be rounded to integers.
 func (w *Window) SetBounds(bounds pixel.Rect) {
 	w.bounds = bounds
-	mainthread.Call(func() {
+	call(func() {
 		_, _, width, height := intBounds(bounds)
 		w.window.SetSize(width, height)
 	})
@@ -243,7 +335,7 @@ func (w *Window) SetBounds(bounds pixel.Rect) {
 //
 // If it is a full screen window, this function does nothing.
 func (w *Window) SetPos(pos pixel.Vec) {
-	mainthread.Call(func() {
+	call(func() {
 		left, top := int(pos.X), int(pos.Y)
 		w.window.SetPos(left, top)
 	})
@@ -253,7 +345,7 @@ func (w *Window) SetPos(pos pixel.Vec) {
 // of the client area of the window. The position is rounded to integers.
 func (w *Window) GetPos() pixel.Vec {
 	var v pixel.Vec
-	mainthread.Call(func() {
+	call(func() {
 		x, y := w.window.GetPos()
 		v = pixel.V(float64(x), float64(y))
 	})
@@ -261,14 +353,40 @@ func (w *Window) GetPos() pixel.Vec {
 }
 
 // Bounds returns the current bounds of the Window.
+//
+// The bounds are in screen coordinates, which are not always pixels. With content scaling (e.g. on
+// Retina displays on macOS), the framebuffer has more pixels than the Window has units, see
+// FramebufferBounds. Everything drawn onto the Window is still positioned in the units of Bounds,
+// just rendered in the higher resolution.
 func (w *Window) Bounds() pixel.Rect {
 	return w.bounds
 }
 
+// FramebufferBounds returns the bounds of the framebuffer of the Window in pixels, with the Min at
+// the origin. They are updated by Update.
+//
+// Without content scaling, the framebuffer has the same size as Bounds. With content scaling, it's
+// larger by the scale (e.g. twice on most Retina displays). Use it e.g. to create a Canvas with the
+// resolution of the screen:
+//
+//   fb := win.FramebufferBounds()
+//   canvas := pixelgl.NewCanvas(fb)
+//   // draw onto the canvas in pixels, then
+//   canvas.Draw(win, pixel.IM.ScaledXY(pixel.ZV, pixel.V(
+//       win.Bounds().W()/fb.W(),
+//       win.Bounds().H()/fb.H(),
+//   )).Moved(win.Bounds().Center()))
+func (w *Window) FramebufferBounds() pixel.Rect {
+	return w.framebufferBounds
+}
+
 func (w *Window) setFullscreen(monitor *Monitor) {
-	mainthread.Call(func() {
-		w.restore.xpos, w.restore.ypos = w.window.GetPos()
-		w.restore.width, w.restore.height = w.window.GetSize()
+	call(func() {
+		// when moving between monitors, keep the windowed bounds from before the first one
+		if w.window.GetMonitor() == nil {
+			w.restore.xpos, w.restore.ypos = w.window.GetPos()
+			w.restore.width, w.restore.height = w.window.GetSize()
+		}
 
 		mode := monitor.monitor.GetVideoMode()
 
@@ -284,7 +402,7 @@ func (w *Window) setFullscreen(monitor *Monitor) {
 }
 
 func (w *Window) setWindowed() {
-	mainthread.Call(func() {
+	call(func() {
 		w.window.SetMonitor(
 			nil,
 			w.restore.xpos,
@@ -302,20 +420,40 @@ func (w *Window) setWindowed() {
 // The Window will be automatically set to the Monitor's resolution. If you want a different
 // resolution, you will need to set it manually with SetBounds method.
 func (w *Window) SetMonitor(monitor *Monitor) {
-	if w.Monitor() != monitor {
-		if monitor != nil {
-			w.setFullscreen(monitor)
-		} else {
-			w.setWindowed()
-		}
+	current := w.Monitor()
+	switch {
+	case monitor == nil && current != nil:
+		w.setWindowed()
+	case monitor != nil && (current == nil || current.monitor != monitor.monitor):
+		w.setFullscreen(monitor)
 	}
 }
 
+// ToggleFullscreen switches the Window between fullscreen and windowed, e.g. on Alt+Enter:
+//
+//   if win.Pressed(pixelgl.KeyLeftAlt) && win.JustPressed(pixelgl.KeyEnter) {
+//       win.ToggleFullscreen(nil)
+//   }
+//
+// A windowed Window goes fullscreen on the Monitor in its current video mode, on the primary
+// Monitor if it's nil. A fullscreen Window is restored to the position and size it had before it
+// went fullscreen. See SetMonitor.
+func (w *Window) ToggleFullscreen(monitor *Monitor) {
+	if w.Monitor() != nil {
+		w.SetMonitor(nil)
+		return
+	}
+	if monitor == nil {
+		monitor = PrimaryMonitor()
+	}
+	w.SetMonitor(monitor)
+}
+
 // Monitor returns a monitor the Window is fullscreen on. If the Window is not fullscreen, this
 // function returns nil.
 func (w *Window) Monitor() *Monitor {
 	var monitor *glfw.Monitor
-	mainthread.Call(func() {
+	call(func() {
 		monitor = w.window.GetMonitor()
 	})
 	if monitor == nil {
@@ -329,18 +467,59 @@ func (w *Window) Monitor() *Monitor {
 // Focused returns true if the Window has input focus.
 func (w *Window) Focused() bool {
 	var focused bool
-	mainthread.Call(func() {
+	call(func() {
 		focused = w.window.GetAttrib(glfw.Focused) == glfw.True
 	})
 	return focused
 }
 
+// SetDeferredFlush sets whether draws onto Windows and Canvases should be recorded and submitted to
+// the main thread together by Flush, instead of being dispatched one by one.
+//
+// In the deferred flush mode, a frame is done in two phases. Draws only record commands, which is
+// cheap, and Flush submits them all at once without waiting for them to finish. The game can then
+// simulate the next frame while the main thread renders the current one, and Update waits only for
+// the flushed commands before swapping the buffers:
+//
+//   for !win.Closed() {
+//       drawWorld(win)
+//       win.Flush()   // rendering of this frame starts in the background
+//       updateWorld() // simulation of the next frame overlaps with it
+//       win.Update()
+//   }
+//
+// Operations that need the main thread to finish (such as reading pixels or Update) flush the
+// recorded commands automatically. Large Triangles are copied when updated in this mode, so the
+// game may change them while they're waiting to be rendered.
+//
+// The mode is shared by all Windows and Canvases, because they share the main thread. It's
+// disabled by default.
+func (w *Window) SetDeferredFlush(deferred bool) {
+	setDeferredFlush(deferred)
+}
+
+// DeferredFlush returns whether the deferred flush mode is enabled.
+func (w *Window) DeferredFlush() bool {
+	return deferredFlush()
+}
+
+// Flush submits all recorded draws to the main thread without waiting for them, see
+// SetDeferredFlush. It does nothing if the deferred flush mode is disabled.
+func (w *Window) Flush() {
+	flushCommands()
+}
+
 // SetVSync sets whether the Window's Update should synchronize with the monitor refresh rate.
 func (w *Window) SetVSync(vsync bool) {
 	w.vsync = vsync
 }
 
-// VSync returns whether the Window is set to synchronize with the monitor refresh rate.
+// VSync returns whether the Window is set to synchronize with the monitor refresh rate, as last
+// requested by SetVSync or WindowConfig.VSync.
+//
+// Whether the synchronization actually happens depends on the graphics driver, which may force it
+// on or off regardless of the request (e.g. in its control panel). Compare EstimatedRefreshRate
+// with the RefreshRate of the Monitor to find out.
 func (w *Window) VSync() bool {
 	return w.vsync
 }
@@ -348,7 +527,7 @@ func (w *Window) VSync() bool {
 // SetCursorVisible sets the visibility of the mouse cursor inside the Window client area.
 func (w *Window) SetCursorVisible(visible bool) {
 	w.cursorVisible = visible
-	mainthread.Call(func() {
+	call(func() {
 		if visible {
 			w.window.SetInputMode(glfw.CursorMode, glfw.CursorNormal)
 		} else {
@@ -428,7 +607,23 @@ func (w *Window) Color(at pixel.Vec) pixel.RGBA {
 	return w.canvas.Color(at)
 }
 
-// Canvas returns the window's underlying Canvas
+// PushDebugGroup starts a named group of OpenGL commands, see the package function
+// PushDebugGroup.
+func (w *Window) PushDebugGroup(name string) {
+	PushDebugGroup(name)
+}
+
+// PopDebugGroup ends the group of OpenGL commands started by the last PushDebugGroup.
+func (w *Window) PopDebugGroup() {
+	PopDebugGroup()
+}
+
+// Canvas returns the window's underlying Canvas. If an internal resolution is set by
+// WindowConfig.InternalWidth and InternalHeight, it returns the Canvas of the internal resolution
+// instead, which is drawn onto the Window in Update.
 func (w *Window) Canvas() *Canvas {
+	if w.internal != nil {
+		return w.internal
+	}
 	return w.canvas
 }
@@ -0,0 +1,59 @@
+package pixelgl
+
+import "sync/atomic"
+
+// YAxis is the direction of the y axis of all the Windows and Canvases, see SetYAxis.
+type YAxis int32
+
+const (
+	// YAxisUp is the OpenGL convention (and the default): the y axis points up, Bounds().Min is
+	// the bottom-left corner.
+	YAxisUp YAxis = iota
+
+	// YAxisDown is the convention of images and most UI toolkits: the y axis points down,
+	// Bounds().Min is the top-left corner.
+	YAxisDown
+)
+
+// yAxis is the current YAxis, accessed atomically.
+var yAxis int32
+
+// SetYAxis sets the direction of the y axis of all the Windows and Canvases. Call it at the start
+// of the function passed to Run, before creating any Window:
+//
+//   func run() {
+//       pixelgl.SetYAxis(pixelgl.YAxisDown)
+//       win, err := pixelgl.NewWindow(cfg)
+//       ...
+//   }
+//
+// With YAxisDown, the following are measured from the top edge of the Bounds instead of the bottom:
+//   - the positions of everything drawn onto Windows and Canvases
+//   - the coordinates within Pictures drawn onto them, e.g. the frames of Sprites, which then match
+//     the coordinates of image editors
+//   - the mouse position and SetMousePosition
+//   - the positions passed to Color of Windows, Canvases and the Pictures made by them
+//
+// Only the interpretation of the coordinates changes. The raw pixels of Canvas.Pixels,
+// Canvas.SetPixels and Canvas.ReadPixels keep the OpenGL order, bottom row first, and
+// pixel.PictureData keeps its y axis up.
+//
+// Changing the YAxis while drawing affects the draws after the change, so mixing the directions
+// in a frame is possible, though rarely useful.
+func SetYAxis(direction YAxis) {
+	atomic.StoreInt32(&yAxis, int32(direction))
+}
+
+// CurrentYAxis returns the direction of the y axis set by SetYAxis.
+func CurrentYAxis() YAxis {
+	return YAxis(atomic.LoadInt32(&yAxis))
+}
+
+// flipRow returns the row (counted from the bottom, as in OpenGL) of the y coordinate of a pixel
+// counted from the bottom in the YAxisUp convention, in an area of height rows.
+func flipRow(y, height int) int {
+	if CurrentYAxis() == YAxisDown {
+		return height - 1 - y
+	}
+	return y
+}
@@ -0,0 +1,160 @@
+package pixel
+
+import "fmt"
+
+// RingTriangles is a fixed-capacity ring buffer of vertices implementing TrianglesPosition,
+// TrianglesColor and TrianglesPicture.
+//
+// Unlike TrianglesData, RingTriangles never reallocates. When SetLen grows it beyond its capacity,
+// the oldest vertices (those at the lowest indices) are overwritten and the length stays at the
+// capacity. Index 0 always refers to the oldest vertex still in the buffer. Keep the capacity a
+// multiple of 3 and grow in multiples of 3, so that whole triangles get overwritten.
+//
+// This makes it a good container for a Batch of particles with a bounded number of live particles,
+// new particles simply replace the oldest ones without any allocations in the steady state:
+//
+//   ring := pixel.NewRingTriangles(3 * 2 * 1000) // up to 1000 sprites
+//   batch := pixel.NewBatch(ring, spritesheet)
+//   // ...
+//   particle.Draw(batch, pixel.IM.Moved(pos)) // overwrites the oldest particle once full
+//
+// Use Discard to remove the oldest vertices, e.g. when particles expire.
+type RingTriangles struct {
+	data  TrianglesData
+	start int
+	len   int
+}
+
+// NewRingTriangles creates empty RingTriangles with the given capacity in vertices.
+func NewRingTriangles(capacity int) *RingTriangles {
+	if capacity <= 0 {
+		panic(fmt.Errorf("NewRingTriangles: invalid capacity %d", capacity))
+	}
+	return &RingTriangles{data: *MakeTrianglesData(capacity)}
+}
+
+func (r *RingTriangles) index(i int) int {
+	return (r.start + i) % len(r.data)
+}
+
+// Len returns the number of vertices in RingTriangles.
+func (r *RingTriangles) Len() int {
+	return r.len
+}
+
+// Cap returns the capacity of RingTriangles, the maximum number of vertices it holds.
+func (r *RingTriangles) Cap() int {
+	return len(r.data)
+}
+
+// SetLen resizes RingTriangles to len.
+//
+// If len is greater than the current length, the new vertices are appended after the newest one
+// and filled with default values ((0, 0), white, (0, 0), 0). If len exceeds the capacity, the
+// oldest vertices are overwritten and the length becomes the capacity. If len is smaller than the
+// current length, the newest vertices are removed.
+func (r *RingTriangles) SetLen(len int) {
+	c := r.Cap()
+	from := r.len
+	if from < len-c {
+		from = len - c // the rest would be overwritten right away
+	}
+	for i := from; i < len; i++ {
+		r.data[r.index(i)] = struct {
+			Position  Vec
+			Color     RGBA
+			Picture   Vec
+			Intensity float64
+		}{Color: RGBA{1, 1, 1, 1}}
+	}
+	if len > c {
+		r.start = r.index(len - c)
+		len = c
+	}
+	r.len = len
+}
+
+// Discard removes the n oldest vertices from RingTriangles.
+func (r *RingTriangles) Discard(n int) {
+	if n > r.len {
+		n = r.len
+	}
+	r.start = r.index(n)
+	r.len -= n
+}
+
+// Slice returns a sub-Triangles of this RingTriangles, covering the vertices in the logical range
+// [i, j), i.e. counted from the oldest vertex.
+//
+// The returned RingTriangles view into the same buffer, so modifying them modifies this
+// RingTriangles. The view may wrap around the end of the buffer. Calling SetLen on the view
+// overwrites vertices of this RingTriangles.
+func (r *RingTriangles) Slice(i, j int) Triangles {
+	if i < 0 || j < i || j > r.len {
+		panic(fmt.Errorf("(%T).Slice: invalid range [%d, %d) of length %d", r, i, j, r.len))
+	}
+	return &RingTriangles{data: r.data, start: r.index(i), len: j - i}
+}
+
+// Update copies vertex properties from the supplied Triangles into this RingTriangles.
+//
+// TrianglesPosition, TrianglesColor and TrianglesPicture are supported.
+func (r *RingTriangles) Update(t Triangles) {
+	if r.len != t.Len() {
+		panic(fmt.Errorf("(%T).Update: invalid triangles length", r))
+	}
+
+	// fast paths
+	switch t := t.(type) {
+	case *TrianglesData:
+		for i := 0; i < r.len; i++ {
+			r.data[r.index(i)] = (*t)[i]
+		}
+		return
+	case *RingTriangles:
+		for i := 0; i < r.len; i++ {
+			r.data[r.index(i)] = t.data[t.index(i)]
+		}
+		return
+	}
+
+	if t, ok := t.(TrianglesPosition); ok {
+		for i := 0; i < r.len; i++ {
+			r.data[r.index(i)].Position = t.Position(i)
+		}
+	}
+	if t, ok := t.(TrianglesColor); ok {
+		for i := 0; i < r.len; i++ {
+			r.data[r.index(i)].Color = t.Color(i)
+		}
+	}
+	if t, ok := t.(TrianglesPicture); ok {
+		for i := 0; i < r.len; i++ {
+			d := &r.data[r.index(i)]
+			d.Picture, d.Intensity = t.Picture(i)
+		}
+	}
+}
+
+// Copy returns an exact independent copy of this RingTriangles with the same capacity.
+func (r *RingTriangles) Copy() Triangles {
+	copyR := NewRingTriangles(r.Cap())
+	copyR.SetLen(r.len)
+	copyR.Update(r)
+	return copyR
+}
+
+// Position returns the position property of i-th vertex.
+func (r *RingTriangles) Position(i int) Vec {
+	return r.data[r.index(i)].Position
+}
+
+// Color returns the color property of i-th vertex.
+func (r *RingTriangles) Color(i int) RGBA {
+	return r.data[r.index(i)].Color
+}
+
+// Picture returns the picture property of i-th vertex.
+func (r *RingTriangles) Picture(i int) (pic Vec, intensity float64) {
+	return r.data[r.index(i)].Picture, r.data[r.index(i)].Intensity
+}
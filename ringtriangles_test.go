@@ -0,0 +1,116 @@
+package pixel_test
+
+import (
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+// push appends vertices at the given x positions to the RingTriangles.
+func push(r *pixel.RingTriangles, xs ...float64) {
+	r.SetLen(r.Len() + len(xs))
+	added := r.Slice(r.Len()-len(xs), r.Len())
+	td := pixel.MakeTrianglesData(len(xs))
+	for i, x := range xs {
+		(*td)[i].Position = pixel.V(x, 0)
+	}
+	added.Update(td)
+}
+
+func positions(r *pixel.RingTriangles) []float64 {
+	xs := make([]float64, r.Len())
+	for i := range xs {
+		xs[i] = r.Position(i).X
+	}
+	return xs
+}
+
+func equalFloats(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRingTrianglesWrapAround(t *testing.T) {
+	r := pixel.NewRingTriangles(6)
+
+	push(r, 1, 2, 3)
+	push(r, 4, 5, 6)
+	if got, want := positions(r), []float64{1, 2, 3, 4, 5, 6}; !equalFloats(got, want) {
+		t.Fatalf("full ring: got %v, want %v", got, want)
+	}
+
+	push(r, 7, 8, 9)
+	if got, want := positions(r), []float64{4, 5, 6, 7, 8, 9}; !equalFloats(got, want) {
+		t.Fatalf("after overwriting: got %v, want %v", got, want)
+	}
+	if r.Len() != r.Cap() {
+		t.Errorf("Len() = %d, want capacity %d", r.Len(), r.Cap())
+	}
+
+	// growing by more than the capacity keeps only the newest vertices, with default values
+	r.SetLen(r.Len() + 7)
+	for i := 0; i < r.Len(); i++ {
+		if pos, col := r.Position(i), r.Color(i); pos != pixel.ZV || col != pixel.Alpha(1) {
+			t.Errorf("vertex %d: got %v %v, want default values", i, pos, col)
+		}
+	}
+
+	push(r, 10, 11, 12, 13, 14, 15)
+	r.Discard(2)
+	push(r, 16)
+	if got, want := positions(r), []float64{12, 13, 14, 15, 16}; !equalFloats(got, want) {
+		t.Errorf("after Discard: got %v, want %v", got, want)
+	}
+	r.SetLen(3)
+	if got, want := positions(r), []float64{12, 13, 14}; !equalFloats(got, want) {
+		t.Errorf("after shrinking: got %v, want %v", got, want)
+	}
+}
+
+func TestRingTrianglesSlice(t *testing.T) {
+	r := pixel.NewRingTriangles(6)
+	push(r, 1, 2, 3, 4, 5, 6)
+	push(r, 7, 8) // physically [7 8 3 4 5 6], logically [3 4 5 6 7 8]
+
+	s := r.Slice(2, 5).(*pixel.RingTriangles) // wraps around the end of the buffer
+	if got, want := positions(s), []float64{5, 6, 7}; !equalFloats(got, want) {
+		t.Fatalf("Slice(2, 5): got %v, want %v", got, want)
+	}
+
+	td := pixel.MakeTrianglesData(3)
+	for i := range *td {
+		(*td)[i].Position = pixel.V(float64(-i), 0)
+	}
+	s.Update(td)
+	if got, want := positions(r), []float64{3, 4, 0, -1, -2, 8}; !equalFloats(got, want) {
+		t.Errorf("after updating the slice: got %v, want %v", got, want)
+	}
+
+	c := r.Copy().(*pixel.RingTriangles)
+	push(r, 9)
+	if got, want := positions(c), []float64{3, 4, 0, -1, -2, 8}; !equalFloats(got, want) {
+		t.Errorf("copy changed with the original: got %v, want %v", got, want)
+	}
+}
+
+func TestRingTrianglesBatch(t *testing.T) {
+	ring := pixel.NewRingTriangles(6)
+	batch := pixel.NewBatch(ring, nil)
+
+	tri := pixel.MakeTrianglesData(3)
+	bt := batch.MakeTriangles(tri)
+	for i := 0; i < 5; i++ {
+		batch.SetMatrix(pixel.IM.Moved(pixel.V(float64(i), 0)))
+		bt.Draw()
+	}
+	if got, want := positions(ring), []float64{3, 3, 3, 4, 4, 4}; !equalFloats(got, want) {
+		t.Errorf("got %v, want the last two triangles %v", got, want)
+	}
+}
@@ -0,0 +1,133 @@
+package pixel
+
+import (
+	"container/heap"
+	"fmt"
+	"time"
+)
+
+// Scheduler calls functions after a delay or repeatedly, e.g. for timed effects:
+//
+//   var sched pixel.Scheduler
+//   spawn()
+//   sched.After(2*time.Second, func() {
+//       fadeOut()
+//       sched.After(500*time.Millisecond, remove)
+//   })
+//
+//   for !win.Closed() {
+//       if !paused {
+//           sched.Update(dt)
+//       }
+//       ...
+//   }
+//
+// The time of the Scheduler only advances by Update, not by the wall clock, so not calling Update
+// pauses it. The functions are called from Update, in the order of their times, and functions
+// due at the same time in the order they were scheduled. While a function is called, the time of
+// the Scheduler is the time it was due, so the delays scheduled by it are exact even if a single
+// Update skips past several of them.
+//
+// The zero value is an empty Scheduler ready to use. A Scheduler must not be used by multiple
+// goroutines at once.
+type Scheduler struct {
+	now   float64
+	seq   uint64
+	tasks schedulerQueue
+}
+
+type scheduledTask struct {
+	at, every float64
+	seq       uint64
+	f         func()
+	canceled  bool
+}
+
+// After schedules f to be called once after d. Calling the returned function cancels it.
+func (s *Scheduler) After(d time.Duration, f func()) (cancel func()) {
+	return s.schedule(d.Seconds(), 0, f)
+}
+
+// Every schedules f to be called repeatedly every d, the first time after d. If an Update skips
+// several periods, f is called once for each of them. Calling the returned function cancels it,
+// also from within f.
+//
+// Every panics if d is not positive.
+func (s *Scheduler) Every(d time.Duration, f func()) (cancel func()) {
+	if d <= 0 {
+		panic(fmt.Errorf("(%T).Every: period must be positive, got %v", s, d))
+	}
+	return s.schedule(d.Seconds(), d.Seconds(), f)
+}
+
+func (s *Scheduler) schedule(delay, every float64, f func()) (cancel func()) {
+	t := &scheduledTask{at: s.now + delay, every: every, seq: s.seq, f: f}
+	s.seq++
+	heap.Push(&s.tasks, t)
+	return func() { t.canceled = true }
+}
+
+// Update advances the time of the Scheduler by dt seconds and calls the functions due until then.
+func (s *Scheduler) Update(dt float64) {
+	end := s.now + dt
+	for len(s.tasks) > 0 && s.tasks[0].at <= end {
+		t := s.tasks[0]
+		if t.canceled {
+			heap.Pop(&s.tasks)
+			continue
+		}
+		s.now = t.at
+		if t.every > 0 {
+			t.at += t.every
+			heap.Fix(&s.tasks, 0)
+		} else {
+			heap.Pop(&s.tasks)
+		}
+		t.f()
+	}
+	s.now = end
+}
+
+// Len returns the number of scheduled functions, excluding the canceled ones.
+func (s *Scheduler) Len() int {
+	n := 0
+	for _, t := range s.tasks {
+		if !t.canceled {
+			n++
+		}
+	}
+	return n
+}
+
+// Clear cancels all the scheduled functions.
+func (s *Scheduler) Clear() {
+	for _, t := range s.tasks {
+		t.canceled = true
+	}
+	s.tasks = nil
+}
+
+// schedulerQueue is a heap of the scheduled tasks ordered by their time and the order of
+// scheduling.
+type schedulerQueue []*scheduledTask
+
+func (q schedulerQueue) Len() int { return len(q) }
+
+func (q schedulerQueue) Less(i, j int) bool {
+	if q[i].at != q[j].at {
+		return q[i].at < q[j].at
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q schedulerQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *schedulerQueue) Push(x interface{}) { *q = append(*q, x.(*scheduledTask)) }
+
+func (q *schedulerQueue) Pop() interface{} {
+	old := *q
+	t := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return t
+}
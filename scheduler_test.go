@@ -0,0 +1,62 @@
+package pixel_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/faiface/pixel"
+)
+
+func TestScheduler(t *testing.T) {
+	var (
+		s   pixel.Scheduler
+		log []string
+	)
+	s.After(2*time.Second, func() {
+		log = append(log, "fade")
+		s.After(500*time.Millisecond, func() { log = append(log, "remove") })
+	})
+	stop := s.Every(time.Second, func() { log = append(log, "tick") })
+	canceled := s.After(time.Second, func() { log = append(log, "canceled") })
+	canceled()
+
+	s.Update(0.5)
+	if len(log) != 0 {
+		t.Fatalf("called %v after 0.5s", log)
+	}
+
+	// a single large step fires everything due in order, chained delays are exact
+	s.Update(2.25)
+	want := []string{"tick", "fade", "tick", "remove"}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("after 2.75s got %v, want %v", log, want)
+	}
+
+	stop()
+	s.Update(10)
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("after stopping got %v, want %v", log, want)
+	}
+	if s.Len() != 0 {
+		t.Errorf("Len = %d, want 0", s.Len())
+	}
+}
+
+func TestSchedulerPaused(t *testing.T) {
+	var s pixel.Scheduler
+	fired := false
+	s.After(time.Second, func() { fired = true })
+	for i := 0; i < 59; i++ {
+		s.Update(1.0 / 60)
+	}
+	time.Sleep(10 * time.Millisecond) // the wall clock doesn't matter
+	if fired {
+		t.Fatal("fired before a second of updates")
+	}
+	s.Update(1.0 / 60)
+	s.Update(1e-9) // absorb the rounding of the sum of the steps
+	if !fired {
+		t.Fatal("didn't fire after a second of updates")
+	}
+}
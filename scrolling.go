@@ -0,0 +1,67 @@
+package pixel
+
+import "math"
+
+// DrawScrolling draws the Picture onto the Target repeated to fill the dst rectangle, with the
+// content shifted by the offset. The Picture is drawn unscaled, the offset wraps around its size.
+// Increasing the offset moves the content left and down, so for a parallax background, use the
+// camera position scaled by the depth of the layer:
+//
+//   pixel.DrawScrolling(win, clouds, win.Bounds(), camPos.Scaled(0.2))
+//
+// Repeating doesn't depend on the texture wrapping of the Target. The rectangle is split into quads
+// at the edges of the repeated Picture, each showing a part of the Picture, so it works with any
+// Picture, even a frame of an atlas (use a Picture of its frame, e.g. from a Sprite, then). On the
+// other hand, a rectangle many times larger than the Picture takes many quads.
+//
+// DrawScrolling creates the geometry on every call, like DrawNineSlice.
+func DrawScrolling(t Target, pic Picture, dst Rect, offset Vec) {
+	src := pic.Bounds()
+	dst = dst.Norm()
+	if src.W() <= 0 || src.H() <= 0 || dst.W() <= 0 || dst.H() <= 0 {
+		return
+	}
+
+	dx, sx := scrollingSplit(dst.Min.X, dst.Max.X, src.Min.X, src.W(), offset.X)
+	dy, sy := scrollingSplit(dst.Min.Y, dst.Max.Y, src.Min.Y, src.H(), offset.Y)
+
+	td := MakeTrianglesData(6 * len(dx) * len(dy))
+	k := 0
+	for j := range dy {
+		for i := range dx {
+			pos := [...]Vec{
+				V(dx[i][0], dy[j][0]), V(dx[i][1], dy[j][0]), V(dx[i][1], dy[j][1]), V(dx[i][0], dy[j][1]),
+			}
+			uv := [...]Vec{
+				V(sx[i][0], sy[j][0]), V(sx[i][1], sy[j][0]), V(sx[i][1], sy[j][1]), V(sx[i][0], sy[j][1]),
+			}
+			for _, v := range [...]int{0, 1, 2, 0, 2, 3} {
+				(*td)[k].Position = pos[v]
+				(*td)[k].Picture = uv[v]
+				(*td)[k].Intensity = 1
+				k++
+			}
+		}
+	}
+
+	d := Drawer{Triangles: td, Picture: pic}
+	d.Draw(t)
+}
+
+// scrollingSplit splits the [min, max] destination interval at the edges of a repeated source
+// interval of the given size starting at start and shifted by offset. It returns the destination
+// parts and the corresponding source parts.
+func scrollingSplit(min, max, start, size, offset float64) (dst, src [][2]float64) {
+	u := math.Mod(offset, size)
+	if u < 0 {
+		u += size
+	}
+	for x := min; x < max; {
+		n := math.Min(size-u, max-x)
+		dst = append(dst, [2]float64{x, x + n})
+		src = append(src, [2]float64{start + u, start + u + n})
+		x += n
+		u = 0
+	}
+	return dst, src
+}
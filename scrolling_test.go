@@ -0,0 +1,48 @@
+package pixel_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+func TestDrawScrolling(t *testing.T) {
+	pic := pixel.MakePictureData(pixel.R(10, 20, 30, 30))
+	dst := pixel.R(0, 0, 50, 25)
+
+	for _, offset := range []pixel.Vec{pixel.ZV, pixel.V(5, 3), pixel.V(-47, 1001.5)} {
+		target := &nopTarget{}
+		pixel.DrawScrolling(target, pic, dst, offset)
+
+		tris := target.tris
+		area := 0.0
+		for i := 0; i+2 < tris.Len(); i += 3 {
+			a, b, c := tris.Position(i), tris.Position(i+1), tris.Position(i+2)
+			area += math.Abs(a.To(b).Cross(a.To(c))) / 2
+
+			for j := i; j < i+3; j++ {
+				pos := tris.Position(j)
+				uv, _ := tris.Picture(j)
+				if !dst.Contains(pos) || !pic.Rect.Contains(uv) {
+					t.Errorf("offset %v: vertex %v with picture coordinates %v out of bounds", offset, pos, uv)
+				}
+			}
+
+			// within a quad, the picture is drawn unscaled and shifted by the offset modulo its size
+			uvA, _ := tris.Picture(i)
+			uvB, _ := tris.Picture(i + 1)
+			if d := uvA.To(uvB).Sub(a.To(b)); d.Len() > 1e-9 {
+				t.Errorf("offset %v: picture is scaled", offset)
+			}
+			shift := a.Add(offset).Sub(uvA)
+			if math.Abs(math.Remainder(shift.X-dst.Min.X+pic.Rect.Min.X, pic.Rect.W())) > 1e-9 ||
+				math.Abs(math.Remainder(shift.Y-dst.Min.Y+pic.Rect.Min.Y, pic.Rect.H())) > 1e-9 {
+				t.Errorf("offset %v: vertex %v shows %v", offset, a, uvA)
+			}
+		}
+		if math.Abs(area-dst.Area()) > 1e-9 {
+			t.Errorf("offset %v: covered area %v, want %v", offset, area, dst.Area())
+		}
+	}
+}
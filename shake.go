@@ -0,0 +1,86 @@
+package pixel
+
+import "math"
+
+// Shake produces the offset of a screen shake, which decays over time. Add some intensity on an
+// impact and add the offset returned by Update to the camera every frame:
+//
+//   shake := pixel.NewShake(12)
+//   ...
+//   if hit {
+//       shake.Add(0.5)
+//   }
+//   cam := pixel.IM.Moved(win.Bounds().Center().Sub(camPos.Add(shake.Update(dt))))
+//
+// The offset follows smooth noise rather than jumping randomly each frame, and it only depends on
+// the elapsed time, not on the frame rate. Its magnitude grows with the square of the intensity,
+// so small impacts barely shake and hits add up to a strong shake.
+type Shake struct {
+	// MaxOffset is the largest offset in each axis, reached at the intensity of 1.
+	MaxOffset float64
+
+	// Decay is the intensity lost per second. The default is 1.5, so a full shake lasts 2/3 of
+	// a second.
+	Decay float64
+
+	// Frequency is the number of changes of direction per second. The default is 15.
+	Frequency float64
+
+	intensity float64
+	time      float64
+}
+
+// NewShake creates a Shake with the given MaxOffset and the default Decay and Frequency.
+func NewShake(maxOffset float64) *Shake {
+	return &Shake{
+		MaxOffset: maxOffset,
+		Decay:     1.5,
+		Frequency: 15,
+	}
+}
+
+// Add adds to the intensity of the Shake. The intensity is capped at 1.
+func (s *Shake) Add(intensity float64) {
+	s.intensity = Clamp(s.intensity+intensity, 0, 1)
+}
+
+// Intensity returns the current intensity of the Shake, between 0 and 1.
+func (s *Shake) Intensity() float64 {
+	return s.intensity
+}
+
+// Update advances the Shake by dt seconds and returns the current offset. The offset is zero once
+// the intensity decays to zero.
+func (s *Shake) Update(dt float64) Vec {
+	s.intensity = math.Max(0, s.intensity-s.Decay*dt)
+	if s.intensity == 0 {
+		s.time = 0
+		return ZV
+	}
+	s.time += dt
+
+	t := s.time * s.Frequency
+	amount := s.MaxOffset * s.intensity * s.intensity
+	return V(valueNoise(t, 0), valueNoise(t, 1)).Scaled(amount)
+}
+
+// valueNoise returns smooth 1D noise in the range [-1, 1] with a different sequence for each seed.
+func valueNoise(t float64, seed uint32) float64 {
+	i := math.Floor(t)
+	f := t - i
+	f = f * f * (3 - 2*f)
+	a := latticeValue(int64(i), seed)
+	b := latticeValue(int64(i)+1, seed)
+	return a + (b-a)*f
+}
+
+// latticeValue returns a pseudo-random value in [-1, 1] for the integer point.
+func latticeValue(i int64, seed uint32) float64 {
+	h := uint32(i)*0x9e3779b1 ^ seed*0x85ebca6b
+	h ^= h >> 16
+	h *= 0x7feb352d
+	h ^= h >> 15
+	h *= 0x846ca68b
+	h ^= h >> 16
+	return float64(h)/float64(math.MaxUint32)*2 - 1
+}
@@ -0,0 +1,45 @@
+package pixel_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+func TestShakeDecays(t *testing.T) {
+	s := pixel.NewShake(10)
+	s.Add(1)
+
+	moved := false
+	for i := 0; i < 60; i++ {
+		off := s.Update(1.0 / 60)
+		if math.Abs(off.X) > 10 || math.Abs(off.Y) > 10 {
+			t.Fatalf("offset %v exceeds MaxOffset", off)
+		}
+		if off != pixel.ZV {
+			moved = true
+		}
+	}
+	if !moved {
+		t.Error("the Shake didn't move")
+	}
+	if off := s.Update(1.0 / 60); off != pixel.ZV || s.Intensity() != 0 {
+		t.Errorf("got offset %v and intensity %v after a second, want zero", off, s.Intensity())
+	}
+}
+
+func TestShakeFrameRateIndependent(t *testing.T) {
+	run := func(fps int) pixel.Vec {
+		s := pixel.NewShake(10)
+		s.Add(0.8)
+		var off pixel.Vec
+		for i := 0; i < fps/4; i++ {
+			off = s.Update(1 / float64(fps))
+		}
+		return off
+	}
+	if a, b := run(60), run(240); a.To(b).Len() > 1e-6 {
+		t.Errorf("offset after 0.25s: %v at 60 FPS, %v at 240 FPS", a, b)
+	}
+}
@@ -0,0 +1,312 @@
+package pixel
+
+import (
+	"fmt"
+	"math"
+)
+
+// shapeEpsilon is the tolerance used by the shape intersection tests to deal with rounding errors.
+const shapeEpsilon = 1e-9
+
+// Polygon is a convex polygon defined by its vertices in counter-clockwise order.
+//
+// Create Polygons with the NewPolygon or PolygonFromRect constructors, they check that the
+// vertices really form a convex counter-clockwise polygon:
+//
+//   p := pixel.NewPolygon(pixel.V(0, 0), pixel.V(10, 0), pixel.V(5, 8))
+//   q := pixel.PolygonFromRect(pixel.R(-5, -5, 5, 5), pixel.IM.Rotated(pixel.ZV, math.Pi/4))
+//
+// All intersection methods return a minimal translation vector (MTV). Moving the receiver by the
+// MTV separates it from the other shape. Shapes that are exactly touching (sharing only the border)
+// are not considered intersecting.
+type Polygon []Vec
+
+// NewPolygon returns a new Polygon with the given vertices.
+//
+// The vertices must form a convex polygon in counter-clockwise order with no zero-length edges,
+// otherwise this function panics. Collinear vertices are allowed.
+func NewPolygon(vertices ...Vec) Polygon {
+	p := Polygon(append([]Vec(nil), vertices...))
+	if err := p.validate(); err != nil {
+		panic(fmt.Errorf("NewPolygon: %v", err))
+	}
+	return p
+}
+
+// PolygonFromRect returns the Polygon of the Rect r transformed by the Matrix m. This is the
+// easiest way to create a rotated rectangle.
+//
+// The Rect must have a non-zero area and the Matrix must be invertible.
+func PolygonFromRect(r Rect, m Matrix) Polygon {
+	r = r.Norm()
+	p := Polygon{
+		m.Project(r.Min),
+		m.Project(V(r.Max.X, r.Min.Y)),
+		m.Project(r.Max),
+		m.Project(V(r.Min.X, r.Max.Y)),
+	}
+	if m[0]*m[3]-m[1]*m[2] < 0 {
+		// the matrix flips the orientation
+		p[1], p[3] = p[3], p[1]
+	}
+	if err := p.validate(); err != nil {
+		panic(fmt.Errorf("PolygonFromRect: %v", err))
+	}
+	return p
+}
+
+func (p Polygon) validate() error {
+	if len(p) < 3 {
+		return fmt.Errorf("polygon needs at least 3 vertices, got %d", len(p))
+	}
+	turning := 0.0
+	for i := range p {
+		a, b, c := p[i], p[(i+1)%len(p)], p[(i+2)%len(p)]
+		ab, bc := a.To(b), b.To(c)
+		if ab.Len() == 0 {
+			return fmt.Errorf("zero-length edge at vertex %d", i)
+		}
+		cross := ab.Cross(bc)
+		if cross < -shapeEpsilon*ab.Len()*bc.Len() {
+			return fmt.Errorf("polygon is not convex or not counter-clockwise at vertex %d", (i+1)%len(p))
+		}
+		turning += math.Atan2(cross, ab.Dot(bc))
+	}
+	// a self-intersecting polygon with only left turns (such as a pentagram) turns more than once
+	if math.Abs(turning-2*math.Pi) > 1e-6 {
+		return fmt.Errorf("polygon is self-intersecting")
+	}
+	return nil
+}
+
+// String returns the string representation of the Polygon.
+func (p Polygon) String() string {
+	return fmt.Sprintf("Polygon%v", []Vec(p))
+}
+
+// Bounds returns the smallest Rect which contains the whole Polygon.
+func (p Polygon) Bounds() Rect {
+	if len(p) == 0 {
+		return Rect{}
+	}
+	b := Rect{Min: p[0], Max: p[0]}
+	for _, v := range p[1:] {
+		b = b.Union(Rect{Min: v, Max: v})
+	}
+	return b
+}
+
+// Center returns the average of the vertices of the Polygon.
+func (p Polygon) Center() Vec {
+	var c Vec
+	for _, v := range p {
+		c = c.Add(v)
+	}
+	return c.Scaled(1 / float64(len(p)))
+}
+
+// Moved returns the Polygon moved by the given vector delta.
+func (p Polygon) Moved(delta Vec) Polygon {
+	q := make(Polygon, len(p))
+	for i := range p {
+		q[i] = p[i].Add(delta)
+	}
+	return q
+}
+
+// Contains checks whether a vector u is contained within this Polygon (including it's borders).
+func (p Polygon) Contains(u Vec) bool {
+	for i := range p {
+		a, b := p[i], p[(i+1)%len(p)]
+		if a.To(b).Cross(a.To(u)) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// project returns the interval of the projection of the Polygon onto the axis.
+func (p Polygon) project(axis Vec) (min, max float64) {
+	min, max = math.Inf(+1), math.Inf(-1)
+	for _, v := range p {
+		d := v.Dot(axis)
+		min, max = math.Min(min, d), math.Max(max, d)
+	}
+	return min, max
+}
+
+// axes returns the unit outward normals of the edges of the Polygon.
+func (p Polygon) axes() []Vec {
+	axes := make([]Vec, len(p))
+	for i := range p {
+		axes[i] = p[i].To(p[(i+1)%len(p)]).Normal().Unit().Scaled(-1)
+	}
+	return axes
+}
+
+// IntersectPolygon checks whether the Polygon p intersects the Polygon q. If they intersect, the
+// minimal translation vector of p out of q is returned together with true.
+func (p Polygon) IntersectPolygon(q Polygon) (mtv Vec, ok bool) {
+	axes := append(p.axes(), q.axes()...)
+	return satMTV(axes, p.Center().Sub(q.Center()), p.project, q.project)
+}
+
+// IntersectCapsule checks whether the Polygon p intersects the Capsule c. If they intersect, the
+// minimal translation vector of p out of c is returned together with true.
+func (p Polygon) IntersectCapsule(c Capsule) (mtv Vec, ok bool) {
+	axes := p.axes()
+	if seg := c.A.To(c.B); seg.Len() > 0 {
+		axes = append(axes, seg.Normal().Unit())
+	}
+	// the round parts of the capsule need the axes towards the vertices of the polygon
+	for _, v := range p {
+		if d := c.closest(v).To(v); d.Len() > shapeEpsilon {
+			axes = append(axes, d.Unit())
+		}
+	}
+	return satMTV(axes, p.Center().Sub(Lerp(c.A, c.B, 0.5)), p.project, c.project)
+}
+
+// Capsule is a line segment from A to B inflated by Radius in all directions, a shape of a circle
+// swept along the segment. A Capsule with A equal to B is a circle.
+//
+// The intersection methods follow the same conventions as the ones of Polygon.
+type Capsule struct {
+	A, B   Vec
+	Radius float64
+}
+
+// String returns the string representation of the Capsule.
+func (c Capsule) String() string {
+	return fmt.Sprintf("Capsule(%v, %v, %v)", c.A, c.B, c.Radius)
+}
+
+// Bounds returns the smallest Rect which contains the whole Capsule.
+func (c Capsule) Bounds() Rect {
+	return R(
+		math.Min(c.A.X, c.B.X)-c.Radius,
+		math.Min(c.A.Y, c.B.Y)-c.Radius,
+		math.Max(c.A.X, c.B.X)+c.Radius,
+		math.Max(c.A.Y, c.B.Y)+c.Radius,
+	)
+}
+
+// Moved returns the Capsule moved by the given vector delta.
+func (c Capsule) Moved(delta Vec) Capsule {
+	return Capsule{A: c.A.Add(delta), B: c.B.Add(delta), Radius: c.Radius}
+}
+
+// Contains checks whether a vector u is contained within this Capsule (including it's borders).
+func (c Capsule) Contains(u Vec) bool {
+	return c.closest(u).To(u).Len() <= c.Radius
+}
+
+// closest returns the point on the segment of the Capsule closest to u.
+func (c Capsule) closest(u Vec) Vec {
+	seg := c.A.To(c.B)
+	l := seg.Dot(seg)
+	if l == 0 {
+		return c.A
+	}
+	t := Clamp(c.A.To(u).Dot(seg)/l, 0, 1)
+	return c.A.Add(seg.Scaled(t))
+}
+
+// project returns the interval of the projection of the Capsule onto the axis.
+func (c Capsule) project(axis Vec) (min, max float64) {
+	a, b := c.A.Dot(axis), c.B.Dot(axis)
+	return math.Min(a, b) - c.Radius, math.Max(a, b) + c.Radius
+}
+
+// IntersectCapsule checks whether the Capsule c intersects the Capsule d. If they intersect, the
+// minimal translation vector of c out of d is returned together with true.
+func (c Capsule) IntersectCapsule(d Capsule) (mtv Vec, ok bool) {
+	p, q := closestSegmentPoints(c.A, c.B, d.A, d.B)
+	dist := q.To(p).Len()
+	if dist >= c.Radius+d.Radius {
+		return ZV, false
+	}
+	if dist > shapeEpsilon {
+		return q.To(p).Unit().Scaled(c.Radius + d.Radius - dist), true
+	}
+
+	// the segments cross, fall back to the separating axes of the segments
+	var axes []Vec
+	for _, seg := range []Vec{c.A.To(c.B), d.A.To(d.B)} {
+		if seg.Len() > 0 {
+			axes = append(axes, seg.Normal().Unit(), seg.Unit())
+		}
+	}
+	if len(axes) == 0 {
+		// two circles with the same center
+		return V(c.Radius+d.Radius, 0), true
+	}
+	return satMTV(axes, Lerp(c.A, c.B, 0.5).Sub(Lerp(d.A, d.B, 0.5)), c.project, d.project)
+}
+
+// IntersectPolygon checks whether the Capsule c intersects the Polygon p. If they intersect, the
+// minimal translation vector of c out of p is returned together with true.
+func (c Capsule) IntersectPolygon(p Polygon) (mtv Vec, ok bool) {
+	mtv, ok = p.IntersectCapsule(c)
+	return mtv.Scaled(-1), ok
+}
+
+// satMTV runs the separating axis test of two convex shapes on the given unit axes. The direction
+// is roughly the direction from the second shape to the first one, it's used to orient the MTV.
+func satMTV(axes []Vec, direction Vec, a, b func(axis Vec) (min, max float64)) (mtv Vec, ok bool) {
+	best := math.Inf(+1)
+	for _, axis := range axes {
+		amin, amax := a(axis)
+		bmin, bmax := b(axis)
+		overlap := math.Min(amax, bmax) - math.Max(amin, bmin)
+		if overlap <= shapeEpsilon {
+			return ZV, false
+		}
+		// the shapes may be contained within each other on this axis
+		push := math.Min(amax-bmin, bmax-amin)
+		if push < best {
+			best = push
+			if amax-bmin < bmax-amin {
+				mtv = axis.Scaled(-push)
+			} else {
+				mtv = axis.Scaled(push)
+			}
+			if amax-bmin == bmax-amin && direction.Dot(axis) < 0 {
+				mtv = mtv.Scaled(-1)
+			}
+		}
+	}
+	return mtv, true
+}
+
+// closestSegmentPoints returns the closest points of the segments p1-q1 and p2-q2.
+func closestSegmentPoints(p1, q1, p2, q2 Vec) (c1, c2 Vec) {
+	d1, d2, r := p1.To(q1), p2.To(q2), p2.To(p1)
+	a, e, f := d1.Dot(d1), d2.Dot(d2), d2.Dot(r)
+
+	var s, t float64
+	switch {
+	case a == 0 && e == 0:
+		return p1, p2
+	case a == 0:
+		t = Clamp(f/e, 0, 1)
+	default:
+		c := d1.Dot(r)
+		if e == 0 {
+			s = Clamp(-c/a, 0, 1)
+		} else {
+			b := d1.Dot(d2)
+			denom := a*e - b*b
+			if denom > shapeEpsilon*a*e {
+				s = Clamp((b*f-c*e)/denom, 0, 1)
+			}
+			t = (b*s + f) / e
+			if t < 0 {
+				t, s = 0, Clamp(-c/a, 0, 1)
+			} else if t > 1 {
+				t, s = 1, Clamp((b-c)/a, 0, 1)
+			}
+		}
+	}
+	return p1.Add(d1.Scaled(s)), p2.Add(d2.Scaled(t))
+}
@@ -0,0 +1,160 @@
+package pixel_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+func TestNewPolygonValidation(t *testing.T) {
+	invalid := [][]pixel.Vec{
+		{pixel.V(0, 0), pixel.V(1, 0)},                                 // too few vertices
+		{pixel.V(0, 0), pixel.V(0, 1), pixel.V(1, 0)},                  // clockwise
+		{pixel.V(0, 0), pixel.V(1, 0), pixel.V(1, 0), pixel.V(0, 1)},   // zero-length edge
+		{pixel.V(0, 0), pixel.V(2, 0), pixel.V(1, 0.5), pixel.V(2, 2)}, // concave
+	}
+	for _, vs := range invalid {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewPolygon(%v) didn't panic", vs)
+				}
+			}()
+			pixel.NewPolygon(vs...)
+		}()
+	}
+
+	// collinear vertices are fine
+	pixel.NewPolygon(pixel.V(0, 0), pixel.V(1, 0), pixel.V(2, 0), pixel.V(2, 2))
+}
+
+func TestPolygonFromRect(t *testing.T) {
+	flip := pixel.IM.ScaledXY(pixel.ZV, pixel.V(-1, 1))
+	p := pixel.PolygonFromRect(pixel.R(0, 0, 2, 1), flip)
+	if !p.Contains(pixel.V(-1, 0.5)) || p.Contains(pixel.V(1, 0.5)) {
+		t.Errorf("flipped PolygonFromRect contains wrong points: %v", p)
+	}
+}
+
+func TestTouchingShapes(t *testing.T) {
+	a := pixel.PolygonFromRect(pixel.R(0, 0, 1, 1), pixel.IM)
+	b := pixel.PolygonFromRect(pixel.R(1, 0, 2, 1), pixel.IM)
+	if _, ok := a.IntersectPolygon(b); ok {
+		t.Errorf("touching polygons reported as intersecting")
+	}
+	c := pixel.Capsule{A: pixel.V(3, 0), B: pixel.V(3, 1), Radius: 1}
+	if _, ok := b.IntersectCapsule(c); ok {
+		t.Errorf("touching polygon and capsule reported as intersecting")
+	}
+}
+
+type testShape interface {
+	Bounds() pixel.Rect
+	Contains(pixel.Vec) bool
+}
+
+func randomPolygon(rng *rand.Rand) pixel.Polygon {
+	n := 3 + rng.Intn(5)
+	angles := make([]float64, n)
+	for i := range angles {
+		angles[i] = (float64(i) + 0.1 + 0.8*rng.Float64()) / float64(n) * 2 * math.Pi
+	}
+	center := pixel.V(rng.Float64()*10-5, rng.Float64()*10-5)
+	radius := 1 + rng.Float64()*3
+	vs := make([]pixel.Vec, n)
+	for i, a := range angles {
+		vs[i] = center.Add(pixel.Unit(a).Scaled(radius))
+	}
+	return pixel.NewPolygon(vs...)
+}
+
+func randomCapsule(rng *rand.Rand) pixel.Capsule {
+	a := pixel.V(rng.Float64()*10-5, rng.Float64()*10-5)
+	return pixel.Capsule{
+		A:      a,
+		B:      a.Add(pixel.V(rng.Float64()*6-3, rng.Float64()*6-3)),
+		Radius: 0.2 + rng.Float64()*2,
+	}
+}
+
+// sampledIntersect is a brute-force oracle checking the intersection of two shapes by point
+// sampling. The step is the distance of the samples.
+func sampledIntersect(a, b testShape, step float64) bool {
+	r := a.Bounds().Intersect(b.Bounds())
+	for x := r.Min.X; x <= r.Max.X; x += step {
+		for y := r.Min.Y; y <= r.Max.Y; y += step {
+			if a.Contains(pixel.V(x, y)) && b.Contains(pixel.V(x, y)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestShapeIntersectOracle(t *testing.T) {
+	const step = 0.02
+	rng := rand.New(rand.NewSource(1))
+
+	moved := func(s testShape, delta pixel.Vec) testShape {
+		switch s := s.(type) {
+		case pixel.Polygon:
+			return s.Moved(delta)
+		case pixel.Capsule:
+			return s.Moved(delta)
+		}
+		panic("unreachable")
+	}
+	intersect := func(a, b testShape) (pixel.Vec, bool) {
+		switch a := a.(type) {
+		case pixel.Polygon:
+			switch b := b.(type) {
+			case pixel.Polygon:
+				return a.IntersectPolygon(b)
+			case pixel.Capsule:
+				return a.IntersectCapsule(b)
+			}
+		case pixel.Capsule:
+			switch b := b.(type) {
+			case pixel.Polygon:
+				return a.IntersectPolygon(b)
+			case pixel.Capsule:
+				return a.IntersectCapsule(b)
+			}
+		}
+		panic("unreachable")
+	}
+	random := func() testShape {
+		if rng.Intn(2) == 0 {
+			return randomPolygon(rng)
+		}
+		return randomCapsule(rng)
+	}
+
+	for i := 0; i < 300; i++ {
+		a, b := random(), random()
+		mtv, ok := intersect(a, b)
+
+		if sampled := sampledIntersect(a, b, step); sampled && !ok {
+			t.Fatalf("%v and %v: sampling found an intersection, Intersect didn't", a, b)
+		}
+		if !ok {
+			continue
+		}
+		if mtv.Len() > 2*step && !sampledIntersect(a, b, step) {
+			t.Fatalf("%v and %v: Intersect returned %v, sampling found no intersection", a, b, mtv)
+		}
+
+		// the MTV must separate the shapes
+		if _, still := intersect(moved(a, mtv.Scaled(1+1e-6)), b); still {
+			t.Fatalf("%v and %v: moving by MTV %v doesn't separate them", a, b, mtv)
+		}
+		// and it must be minimal, at least along its own direction
+		if mtv.Len() > 1e-3 {
+			if _, still := intersect(moved(a, mtv.Scaled(0.99)), b); !still {
+				t.Fatalf("%v and %v: MTV %v is not minimal", a, b, mtv)
+			}
+		}
+	}
+}
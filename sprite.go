@@ -1,8 +1,12 @@
 package pixel
 
-import "image/color"
+import (
+	"image/color"
+	"sync"
+)
 
-// Sprite is a drawable frame of a Picture. It's anchored by the center of it's Picture's frame.
+// Sprite is a drawable frame of a Picture. It's anchored by the center of it's Picture's frame,
+// unless set otherwise by SetAnchor.
 //
 // Frame specifies a rectangular portion of the Picture that will be drawn. For example, this
 // creates a Sprite that draws the whole Picture:
@@ -14,9 +18,11 @@ import "image/color"
 // memory leak, since Sprite caches them and never forgets. In such a situation, create a new Sprite
 // for each Picture.
 type Sprite struct {
-	tri   *TrianglesData
-	frame Rect
-	d     Drawer
+	tri      *TrianglesData
+	frame    Rect
+	anchor   Vec
+	uvOffset Vec
+	d        Drawer
 
 	matrix Matrix
 	mask   RGBA
@@ -26,8 +32,9 @@ type Sprite struct {
 func NewSprite(pic Picture, frame Rect) *Sprite {
 	tri := MakeTrianglesData(6)
 	s := &Sprite{
-		tri: tri,
-		d:   Drawer{Triangles: tri},
+		tri:    tri,
+		anchor: V(0.5, 0.5),
+		d:      Drawer{Triangles: tri},
 	}
 	s.matrix = IM
 	s.mask = Alpha(1)
@@ -44,6 +51,48 @@ func (s *Sprite) Set(pic Picture, frame Rect) {
 	}
 }
 
+// SetAnchor sets the point of the frame which is placed at the origin, so that the Matrix passed
+// to Draw moves this point. The anchor is relative to the frame: pixel.V(0, 0) is the bottom-left
+// corner, pixel.V(0.5, 0.5) the center (the default), pixel.V(1, 1) the top-right corner. It's also
+// the point the Sprite is rotated and scaled around.
+//
+//   sprite.SetAnchor(pixel.V(0.5, 0)) // stand on the position by the bottom edge
+//   sprite.Draw(win, pixel.IM.Moved(feet))
+func (s *Sprite) SetAnchor(anchor Vec) {
+	if anchor != s.anchor {
+		s.anchor = anchor
+		s.calcData()
+	}
+}
+
+// Anchor returns the anchor of the Sprite set by SetAnchor.
+func (s *Sprite) Anchor() Vec {
+	return s.anchor
+}
+
+// SetUVOffset scrolls the content of the frame by the offset without moving the Sprite, e.g. for
+// conveyor belts, waterfalls or water. The content wraps around within the frame, increasing the
+// offset moves it left and down, just like with DrawScrolling:
+//
+//   belt.SetUVOffset(pixel.V(speed*elapsed, 0))
+//
+// The wrapping never samples the Picture outside of the frame, so it's safe with frames of a shared
+// atlas. It doesn't rely on the texture wrapping of the Target (there is none), on all Targets the
+// Sprite is split into up to four quads at the seam instead, so a Sprite with a non-zero offset
+// draws up to 24 vertices instead of 6. Changing the offset is as cheap as changing the Matrix, so
+// it's fine to animate it every frame for many Sprites drawn to a Batch.
+func (s *Sprite) SetUVOffset(offset Vec) {
+	if offset != s.uvOffset {
+		s.uvOffset = offset
+		s.calcData()
+	}
+}
+
+// UVOffset returns the offset of the content set by SetUVOffset.
+func (s *Sprite) UVOffset() Vec {
+	return s.uvOffset
+}
+
 // Picture returns the current Sprite's Picture.
 func (s *Sprite) Picture() Picture {
 	return s.d.Picture
@@ -88,30 +137,88 @@ func (s *Sprite) DrawColorMask(t Target, matrix Matrix, mask color.Color) {
 }
 
 func (s *Sprite) calcData() {
-	var (
-		center     = s.frame.Center()
-		horizontal = V(s.frame.W()/2, 0)
-		vertical   = V(0, s.frame.H()/2)
-	)
-
-	(*s.tri)[0].Position = Vec{}.Sub(horizontal).Sub(vertical)
-	(*s.tri)[1].Position = Vec{}.Add(horizontal).Sub(vertical)
-	(*s.tri)[2].Position = Vec{}.Add(horizontal).Add(vertical)
-	(*s.tri)[3].Position = Vec{}.Sub(horizontal).Sub(vertical)
-	(*s.tri)[4].Position = Vec{}.Add(horizontal).Add(vertical)
-	(*s.tri)[5].Position = Vec{}.Sub(horizontal).Add(vertical)
-
-	for i := range *s.tri {
-		(*s.tri)[i].Color = s.mask
-		(*s.tri)[i].Picture = center.Add((*s.tri)[i].Position)
-		(*s.tri)[i].Intensity = 1
+	// the quads in the coordinates of the Picture and the parts of the frame they show
+	xs := [][2]float64{{s.frame.Min.X, s.frame.Max.X}}
+	ys := [][2]float64{{s.frame.Min.Y, s.frame.Max.Y}}
+	us, vs := xs, ys
+	if s.uvOffset.X != 0 && s.frame.W() > 0 {
+		xs, us = scrollingSplit(s.frame.Min.X, s.frame.Max.X, s.frame.Min.X, s.frame.W(), s.uvOffset.X)
 	}
-
-	// matrix and mask
-	for i := range *s.tri {
-		(*s.tri)[i].Position = s.matrix.Project((*s.tri)[i].Position)
-		(*s.tri)[i].Color = s.mask
+	if s.uvOffset.Y != 0 && s.frame.H() > 0 {
+		ys, vs = scrollingSplit(s.frame.Min.Y, s.frame.Max.Y, s.frame.Min.Y, s.frame.H(), s.uvOffset.Y)
+	}
+	s.tri.SetLen(6 * len(xs) * len(ys))
+
+	// shift the quads, so that the anchor is at the origin
+	origin := s.frame.Min.Add(s.anchor.ScaledXY(s.frame.Size()))
+
+	k := 0
+	for j := range ys {
+		for i := range xs {
+			pos := [...]Vec{
+				V(xs[i][0], ys[j][0]), V(xs[i][1], ys[j][0]), V(xs[i][1], ys[j][1]), V(xs[i][0], ys[j][1]),
+			}
+			uv := [...]Vec{
+				V(us[i][0], vs[j][0]), V(us[i][1], vs[j][0]), V(us[i][1], vs[j][1]), V(us[i][0], vs[j][1]),
+			}
+			for _, v := range [...]int{0, 1, 2, 0, 2, 3} {
+				(*s.tri)[k].Position = s.matrix.Project(pos[v].Sub(origin))
+				(*s.tri)[k].Color = s.mask
+				(*s.tri)[k].Picture = uv[v]
+				(*s.tri)[k].Intensity = 1
+				k++
+			}
+		}
 	}
 
 	s.d.Dirty()
 }
+
+// quickSprites caches the Sprites of the Pictures recently drawn by DrawPicture, so that Targets
+// don't have to process the Pictures again on each call.
+var quickSprites struct {
+	sync.Mutex
+	sprites []*Sprite // the most recently drawn last
+}
+
+// quickSpritesSize is the number of Pictures cached by DrawPicture.
+const quickSpritesSize = 8
+
+// DrawPicture draws the whole Picture onto the Target centered at the given position. It's the
+// simplest way to draw a Picture, e.g. for quick debug rendering:
+//
+//   pixel.DrawPicture(win, heatmap, win.Bounds().Center())
+//
+// The last few drawn Pictures are cached, so drawing the same Picture every frame is fine. However,
+// a Sprite or a Batch is more efficient for repeated drawing, especially of many Pictures.
+func DrawPicture(t Target, pic Picture, at Vec) {
+	DrawPictureMatrix(t, pic, IM.Moved(at))
+}
+
+// DrawPictureMatrix draws the whole Picture onto the Target transformed by the Matrix, just like
+// a Sprite of the whole Picture. See DrawPicture.
+func DrawPictureMatrix(t Target, pic Picture, matrix Matrix) {
+	quickSprites.Lock()
+	defer quickSprites.Unlock()
+
+	sprites := quickSprites.sprites
+	var s *Sprite
+	for i := range sprites {
+		if sprites[i].Picture() == pic {
+			s = sprites[i]
+			sprites = append(sprites[:i], sprites[i+1:]...)
+			break
+		}
+	}
+	if s == nil {
+		if len(sprites) == quickSpritesSize {
+			sprites = sprites[1:]
+		}
+		s = NewSprite(pic, pic.Bounds())
+	} else {
+		s.Set(pic, pic.Bounds()) // the bounds may have changed
+	}
+	quickSprites.sprites = append(sprites, s)
+
+	s.Draw(t, matrix)
+}
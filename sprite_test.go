@@ -0,0 +1,101 @@
+package pixel_test
+
+import (
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+func TestDrawPicture(t *testing.T) {
+	pic := pixel.MakePictureData(pixel.R(0, 0, 20, 10))
+	target := &nopTarget{}
+
+	pixel.DrawPicture(target, pic, pixel.V(100, 50))
+	pixel.DrawPicture(target, pic, pixel.V(100, 50))
+	if target.pics != 1 {
+		t.Errorf("MakePicture called %d times, want 1 for the cached Picture", target.pics)
+	}
+
+	quad := pixel.R(target.tris.Position(0).X, target.tris.Position(0).Y, target.tris.Position(0).X, target.tris.Position(0).Y)
+	for i := 0; i < target.tris.Len(); i++ {
+		pos := target.tris.Position(i)
+		quad = quad.Union(pixel.R(pos.X, pos.Y, pos.X, pos.Y))
+	}
+	if want := pixel.R(90, 45, 110, 55); quad != want {
+		t.Errorf("drawn at %v, want %v", quad, want)
+	}
+}
+
+func TestSpriteSetAnchor(t *testing.T) {
+	pic := pixel.MakePictureData(pixel.R(0, 0, 20, 10))
+	tests := []struct {
+		anchor pixel.Vec
+		want   pixel.Rect
+	}{
+		{pixel.V(0.5, 0.5), pixel.R(90, 45, 110, 55)},
+		{pixel.V(0, 0), pixel.R(100, 50, 120, 60)},
+		{pixel.V(1, 0.5), pixel.R(80, 45, 100, 55)},
+		{pixel.V(0.25, 1), pixel.R(95, 40, 115, 50)},
+	}
+	for _, test := range tests {
+		s := pixel.NewSprite(pic, pic.Bounds())
+		s.SetAnchor(test.anchor)
+		target := &nopTarget{}
+		s.Draw(target, pixel.IM.Moved(pixel.V(100, 50)))
+
+		quad := pixel.R(target.tris.Position(0).X, target.tris.Position(0).Y, target.tris.Position(0).X, target.tris.Position(0).Y)
+		for i := 0; i < target.tris.Len(); i++ {
+			pos := target.tris.Position(i)
+			quad = quad.Union(pixel.R(pos.X, pos.Y, pos.X, pos.Y))
+			if uv, _ := target.tris.Picture(i); (uv.X != 0 && uv.X != 20) || (uv.Y != 0 && uv.Y != 10) {
+				t.Errorf("anchor %v: picture coordinates %v not at the corners of the frame", test.anchor, uv)
+			}
+		}
+		if quad != test.want {
+			t.Errorf("anchor %v: drawn at %v, want %v", test.anchor, quad, test.want)
+		}
+	}
+}
+
+func TestSpriteSetUVOffset(t *testing.T) {
+	pic := pixel.MakePictureData(pixel.R(0, 0, 40, 40))
+	frame := pixel.R(10, 20, 30, 30)
+	s := pixel.NewSprite(pic, frame)
+	s.SetUVOffset(pixel.V(25, -2)) // wraps to (5, 8)
+	target := &nopTarget{}
+	s.Draw(target, pixel.IM.Moved(pixel.V(100, 50)))
+
+	if target.tris.Len() != 24 {
+		t.Fatalf("got %d vertices, want 24 for four quads", target.tris.Len())
+	}
+	area := 0.0
+	for i := 0; i < target.tris.Len(); i += 6 {
+		min, max := target.tris.Position(i), target.tris.Position(i+2)
+		uvMin, _ := target.tris.Picture(i)
+		uvMax, _ := target.tris.Picture(i + 2)
+		if !frame.Contains(uvMin) || !frame.Contains(uvMax) {
+			t.Errorf("picture coordinates %v, %v outside of the frame %v", uvMin, uvMax, frame)
+		}
+		if size := min.To(max); size != uvMin.To(uvMax) {
+			t.Errorf("quad of size %v shows a part of size %v", size, uvMin.To(uvMax))
+		}
+		area += min.To(max).X * min.To(max).Y
+	}
+	if area != frame.Area() {
+		t.Errorf("quads cover area %v, want %v", area, frame.Area())
+	}
+
+	// the content at the bottom-left corner of the Sprite is shifted by the offset
+	if pos := target.tris.Position(0); pos != pixel.V(90, 45) {
+		t.Errorf("first quad at %v, want %v", pos, pixel.V(90, 45))
+	}
+	if uv, _ := target.tris.Picture(0); uv != pixel.V(15, 28) {
+		t.Errorf("bottom-left corner shows %v, want %v", uv, pixel.V(15, 28))
+	}
+
+	s.SetUVOffset(pixel.ZV)
+	s.Draw(target, pixel.IM.Moved(pixel.V(100, 50)))
+	if target.tris.Len() != 6 {
+		t.Errorf("got %d vertices after resetting the offset, want 6", target.tris.Len())
+	}
+}
@@ -0,0 +1,370 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/faiface/pixel"
+)
+
+// scanner splits path data and transform lists into commands and numbers.
+type scanner struct {
+	s   string
+	pos int
+}
+
+func (sc *scanner) skipSeparators() {
+	for sc.pos < len(sc.s) && strings.IndexByte(" \t\r\n,", sc.s[sc.pos]) >= 0 {
+		sc.pos++
+	}
+}
+
+func (sc *scanner) done() bool {
+	sc.skipSeparators()
+	return sc.pos >= len(sc.s)
+}
+
+// peekNumber returns whether a number follows.
+func (sc *scanner) peekNumber() bool {
+	sc.skipSeparators()
+	if sc.pos >= len(sc.s) {
+		return false
+	}
+	c := sc.s[sc.pos]
+	return c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9')
+}
+
+func (sc *scanner) number() (float64, error) {
+	sc.skipSeparators()
+	start := sc.pos
+	i := sc.pos
+	if i < len(sc.s) && (sc.s[i] == '-' || sc.s[i] == '+') {
+		i++
+	}
+	dot, exp := false, false
+	for ; i < len(sc.s); i++ {
+		c := sc.s[i]
+		switch {
+		case c >= '0' && c <= '9':
+		case c == '.' && !dot && !exp:
+			dot = true
+		case (c == 'e' || c == 'E') && !exp && i > start:
+			exp = true
+			if i+1 < len(sc.s) && (sc.s[i+1] == '-' || sc.s[i+1] == '+') {
+				i++
+			}
+		default:
+			goto end
+		}
+	}
+end:
+	f, err := strconv.ParseFloat(sc.s[start:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number at %d in %q", start, sc.s)
+	}
+	sc.pos = i
+	return f, nil
+}
+
+func (sc *scanner) vec() (pixel.Vec, error) {
+	x, err := sc.number()
+	if err != nil {
+		return pixel.ZV, err
+	}
+	y, err := sc.number()
+	if err != nil {
+		return pixel.ZV, err
+	}
+	return pixel.V(x, y), nil
+}
+
+// parsePath parses path data, transforms it by the Matrix and flattens the curves. It returns the
+// resulting subpaths as polygons.
+func parsePath(d string, m pixel.Matrix, tolerance float64) ([][]pixel.Vec, error) {
+	var (
+		subpaths [][]pixel.Vec
+		current  []pixel.Vec
+		sc       = &scanner{s: d}
+		pos      pixel.Vec // current point in the path's coordinates
+		start    pixel.Vec // start of the current subpath
+		cmd      byte
+	)
+
+	closeSubpath := func() {
+		if len(current) >= 3 {
+			subpaths = append(subpaths, current)
+		}
+		current = nil
+	}
+
+	for !sc.done() {
+		if !sc.peekNumber() {
+			cmd = sc.s[sc.pos]
+			sc.pos++
+		} else if cmd == 0 {
+			return nil, fmt.Errorf("path data must start with a command")
+		}
+
+		rel := cmd >= 'a' && cmd <= 'z'
+		origin := pixel.ZV
+		if rel {
+			origin = pos
+		}
+		if len(current) == 0 && cmd != 'M' && cmd != 'm' && cmd != 'Z' && cmd != 'z' {
+			// drawing after a closepath starts a new subpath at the current point
+			current = append(current, m.Project(pos))
+		}
+
+		switch cmd {
+		case 'M', 'm':
+			p, err := sc.vec()
+			if err != nil {
+				return nil, err
+			}
+			closeSubpath()
+			pos = origin.Add(p)
+			start = pos
+			current = append(current, m.Project(pos))
+			// following coordinate pairs are implicit lineto commands
+			if rel {
+				cmd = 'l'
+			} else {
+				cmd = 'L'
+			}
+		case 'L', 'l':
+			p, err := sc.vec()
+			if err != nil {
+				return nil, err
+			}
+			pos = origin.Add(p)
+			current = append(current, m.Project(pos))
+		case 'H', 'h':
+			x, err := sc.number()
+			if err != nil {
+				return nil, err
+			}
+			pos = pixel.V(origin.X+x, pos.Y)
+			current = append(current, m.Project(pos))
+		case 'V', 'v':
+			y, err := sc.number()
+			if err != nil {
+				return nil, err
+			}
+			pos = pixel.V(pos.X, origin.Y+y)
+			current = append(current, m.Project(pos))
+		case 'C', 'c':
+			var ps [3]pixel.Vec
+			for i := range ps {
+				p, err := sc.vec()
+				if err != nil {
+					return nil, err
+				}
+				ps[i] = origin.Add(p)
+			}
+			current = flattenCubic(current, m.Project(pos), m.Project(ps[0]), m.Project(ps[1]), m.Project(ps[2]), tolerance)
+			pos = ps[2]
+		case 'Q', 'q':
+			var ps [2]pixel.Vec
+			for i := range ps {
+				p, err := sc.vec()
+				if err != nil {
+					return nil, err
+				}
+				ps[i] = origin.Add(p)
+			}
+			current = flattenQuadratic(current, m.Project(pos), m.Project(ps[0]), m.Project(ps[1]), tolerance)
+			pos = ps[1]
+		case 'Z', 'z':
+			closeSubpath()
+			pos = start
+			cmd = 0
+			if sc.peekNumber() {
+				return nil, fmt.Errorf("numbers after a closepath command")
+			}
+		default:
+			return nil, fmt.Errorf("unsupported path command %q", cmd)
+		}
+	}
+	// fills close open subpaths implicitly
+	closeSubpath()
+
+	for i := range subpaths {
+		subpaths[i] = cleanPolygon(subpaths[i])
+	}
+	return subpaths, nil
+}
+
+// flattenCubic appends the points of the cubic Bézier curve p0 p1 p2 p3 (excluding p0) to the
+// polygon, so that the polygon doesn't deviate more than tolerance from the curve.
+func flattenCubic(poly []pixel.Vec, p0, p1, p2, p3 pixel.Vec, tolerance float64) []pixel.Vec {
+	// the distance of n segments from the curve is at most 3/4 * dd / n^2
+	dd := math.Max(p0.Sub(p1.Scaled(2)).Add(p2).Len(), p1.Sub(p2.Scaled(2)).Add(p3).Len())
+	n := int(math.Ceil(math.Sqrt(0.75 * dd / tolerance)))
+	if n < 1 {
+		n = 1
+	}
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+		u := 1 - t
+		p := p0.Scaled(u * u * u).
+			Add(p1.Scaled(3 * u * u * t)).
+			Add(p2.Scaled(3 * u * t * t)).
+			Add(p3.Scaled(t * t * t))
+		poly = append(poly, p)
+	}
+	return poly
+}
+
+// flattenQuadratic is the same as flattenCubic, but for a quadratic Bézier curve.
+func flattenQuadratic(poly []pixel.Vec, p0, p1, p2 pixel.Vec, tolerance float64) []pixel.Vec {
+	// the distance of n segments from the curve is at most 1/4 * dd / n^2
+	dd := p0.Sub(p1.Scaled(2)).Add(p2).Len()
+	n := int(math.Ceil(math.Sqrt(0.25 * dd / tolerance)))
+	if n < 1 {
+		n = 1
+	}
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+		u := 1 - t
+		p := p0.Scaled(u * u).Add(p1.Scaled(2 * u * t)).Add(p2.Scaled(t * t))
+		poly = append(poly, p)
+	}
+	return poly
+}
+
+// cleanPolygon removes duplicate consecutive points (including the closing point equal to the
+// first one).
+func cleanPolygon(poly []pixel.Vec) []pixel.Vec {
+	clean := poly[:0]
+	for _, p := range poly {
+		if len(clean) > 0 && clean[len(clean)-1] == p {
+			continue
+		}
+		clean = append(clean, p)
+	}
+	for len(clean) > 1 && clean[len(clean)-1] == clean[0] {
+		clean = clean[:len(clean)-1]
+	}
+	return clean
+}
+
+// parseTransform parses the value of the transform attribute.
+func parseTransform(s string) (pixel.Matrix, error) {
+	m := pixel.IM
+	rest := strings.TrimSpace(s)
+	for rest != "" {
+		open := strings.IndexByte(rest, '(')
+		end := strings.IndexByte(rest, ')')
+		if open < 0 || end < open {
+			return pixel.IM, fmt.Errorf("invalid transform %q", s)
+		}
+		name := strings.TrimSpace(rest[:open])
+		sc := &scanner{s: rest[open+1 : end]}
+		rest = strings.TrimLeft(rest[end+1:], " \t\r\n,")
+
+		var args []float64
+		for !sc.done() {
+			f, err := sc.number()
+			if err != nil {
+				return pixel.IM, fmt.Errorf("invalid transform %q", s)
+			}
+			args = append(args, f)
+		}
+
+		t, ok := transformMatrix(name, args)
+		if !ok {
+			return pixel.IM, fmt.Errorf("invalid transform %q", s)
+		}
+		// the rightmost transform is applied first
+		m = t.Chained(m)
+	}
+	return m, nil
+}
+
+func transformMatrix(name string, args []float64) (pixel.Matrix, bool) {
+	switch {
+	case name == "matrix" && len(args) == 6:
+		return pixel.Matrix{args[0], args[1], args[2], args[3], args[4], args[5]}, true
+	case name == "translate" && len(args) == 1:
+		return pixel.IM.Moved(pixel.V(args[0], 0)), true
+	case name == "translate" && len(args) == 2:
+		return pixel.IM.Moved(pixel.V(args[0], args[1])), true
+	case name == "scale" && len(args) == 1:
+		return pixel.IM.Scaled(pixel.ZV, args[0]), true
+	case name == "scale" && len(args) == 2:
+		return pixel.IM.ScaledXY(pixel.ZV, pixel.V(args[0], args[1])), true
+	case name == "rotate" && len(args) == 1:
+		return pixel.IM.Rotated(pixel.ZV, args[0]*math.Pi/180), true
+	case name == "rotate" && len(args) == 3:
+		return pixel.IM.Rotated(pixel.V(args[1], args[2]), args[0]*math.Pi/180), true
+	case name == "skewX" && len(args) == 1:
+		return pixel.Matrix{1, 0, math.Tan(args[0] * math.Pi / 180), 1, 0, 0}, true
+	case name == "skewY" && len(args) == 1:
+		return pixel.Matrix{1, math.Tan(args[0] * math.Pi / 180), 0, 1, 0, 0}, true
+	}
+	return pixel.IM, false
+}
+
+// namedColors are the most common of the SVG color keywords.
+var namedColors = map[string]pixel.RGBA{
+	"black":   pixel.RGB(0, 0, 0),
+	"white":   pixel.RGB(1, 1, 1),
+	"red":     pixel.RGB(1, 0, 0),
+	"green":   pixel.RGB(0, float64(0x80)/255, 0),
+	"lime":    pixel.RGB(0, 1, 0),
+	"blue":    pixel.RGB(0, 0, 1),
+	"yellow":  pixel.RGB(1, 1, 0),
+	"cyan":    pixel.RGB(0, 1, 1),
+	"magenta": pixel.RGB(1, 0, 1),
+	"gray":    pixel.RGB(0.5, 0.5, 0.5),
+	"grey":    pixel.RGB(0.5, 0.5, 0.5),
+	"orange":  pixel.RGB(1, float64(0xa5)/255, 0),
+	"purple":  pixel.RGB(float64(0x80)/255, 0, float64(0x80)/255),
+}
+
+// parseColor parses a color in the #rgb, #rrggbb, rgb(r, g, b) or keyword format.
+func parseColor(s string) (pixel.RGBA, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if c, ok := namedColors[s]; ok {
+		return c, nil
+	}
+
+	switch {
+	case strings.HasPrefix(s, "#") && (len(s) == 4 || len(s) == 7):
+		hex := s[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			break
+		}
+		return pixel.RGB(
+			float64(v>>16&0xff)/255,
+			float64(v>>8&0xff)/255,
+			float64(v&0xff)/255,
+		), nil
+	case strings.HasPrefix(s, "rgb(") && strings.HasSuffix(s, ")"):
+		parts := strings.Split(s[4:len(s)-1], ",")
+		if len(parts) != 3 {
+			break
+		}
+		var c [3]float64
+		for i, p := range parts {
+			p = strings.TrimSpace(p)
+			scale := 255.0
+			if strings.HasSuffix(p, "%") {
+				p, scale = p[:len(p)-1], 100
+			}
+			v, err := strconv.ParseFloat(p, 64)
+			if err != nil {
+				return pixel.RGBA{}, fmt.Errorf("invalid color %q", s)
+			}
+			c[i] = pixel.Clamp(v/scale, 0, 1)
+		}
+		return pixel.RGB(c[0], c[1], c[2]), nil
+	}
+	return pixel.RGBA{}, fmt.Errorf("invalid color %q", s)
+}
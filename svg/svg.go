@@ -0,0 +1,301 @@
+// Package svg loads simple SVG documents into triangles, so that vector graphics (such as UI
+// icons) can be drawn crisp at any scale.
+//
+// Only a practical subset of SVG is supported: <svg>, <g> and <path> elements with M, L, H, V, C, Q
+// and Z path commands (both absolute and relative), fill colors, opacity and transforms. Curves are
+// flattened into line segments and the filled areas are triangulated. Gradients, filters, strokes,
+// text and other elements are not supported. They are skipped and reported in the warnings of the
+// Document instead of failing the whole file.
+package svg
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/faiface/pixel"
+	"github.com/pkg/errors"
+)
+
+// Document is a loaded SVG document.
+//
+// All coordinates are in pixel's coordinate system, the Y axis points up. The document is flipped
+// vertically within its Bounds, so the Bounds are the same as the document's viewBox.
+type Document struct {
+	// Bounds is the viewBox of the document (or the rectangle given by its width and height).
+	Bounds pixel.Rect
+
+	// Shapes are the filled paths of the document in the order they appear in the document.
+	Shapes []Shape
+
+	// Warnings describe the parts of the document that were skipped, because they are not
+	// supported.
+	Warnings []string
+}
+
+// Shape is a single filled path of a Document.
+type Shape struct {
+	// ID is the id attribute of the path element, if any.
+	ID string
+
+	// Fill is the fill color of the path, including the opacity.
+	Fill pixel.RGBA
+
+	// Triangles are the triangulated filled area of the path. All vertices have the Fill color.
+	Triangles *pixel.TrianglesData
+}
+
+// Draw draws all Shapes of the Document onto the Target.
+//
+// This creates the geometry on every call, draw the Document onto a Batch once if you need to draw
+// it often.
+func (doc *Document) Draw(t pixel.Target) {
+	for _, shape := range doc.Shapes {
+		d := pixel.Drawer{Triangles: shape.Triangles}
+		d.Draw(t)
+	}
+}
+
+// Load reads an SVG document from the Reader and converts it into a Document.
+//
+// The tolerance is the maximal distance (in the document's units) of the flattened curves from the
+// real curves. Smaller tolerance produces smoother curves with more triangles.
+//
+// An error is returned only if the document can't be read or isn't a valid XML. Unsupported parts
+// of the document are reported in Document.Warnings.
+func Load(r io.Reader, tolerance float64) (*Document, error) {
+	if tolerance <= 0 {
+		return nil, fmt.Errorf("svg.Load: tolerance must be positive, got %v", tolerance)
+	}
+
+	l := &loader{
+		doc:       &Document{},
+		tolerance: tolerance,
+	}
+
+	dec := xml.NewDecoder(r)
+	stack := []style{{fill: pixel.RGB(0, 0, 0), opacity: 1, transform: pixel.IM}}
+	skip := 0 // depth within a skipped element
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse SVG")
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if skip > 0 {
+				skip++
+				continue
+			}
+			st, ok := l.startElement(tok, stack[len(stack)-1])
+			if !ok {
+				skip = 1
+				continue
+			}
+			stack = append(stack, st)
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	l.flip()
+	return l.doc, nil
+}
+
+// style is the inherited state of an element.
+type style struct {
+	fill      pixel.RGBA
+	noFill    bool
+	opacity   float64
+	transform pixel.Matrix
+}
+
+type loader struct {
+	doc       *Document
+	tolerance float64
+	root      bool
+}
+
+func (l *loader) warnf(format string, args ...interface{}) {
+	l.doc.Warnings = append(l.doc.Warnings, fmt.Sprintf(format, args...))
+}
+
+// startElement processes the start of an element. If the element and its children should be
+// skipped, it returns false.
+func (l *loader) startElement(el xml.StartElement, parent style) (style, bool) {
+	switch el.Name.Local {
+	case "svg":
+		st, ok := l.applyStyle(el, parent)
+		if !l.root {
+			l.root = true
+			l.doc.Bounds = rootBounds(el)
+		}
+		return st, ok
+	case "g":
+		return l.applyStyle(el, parent)
+	case "path":
+		st, ok := l.applyStyle(el, parent)
+		if ok && !st.noFill {
+			l.path(el, st)
+		}
+		return st, ok
+	case "title", "desc", "metadata":
+		return parent, false
+	default:
+		l.warnf("unsupported element <%s> skipped", el.Name.Local)
+		return parent, false
+	}
+}
+
+func rootBounds(el xml.StartElement) pixel.Rect {
+	if vb := attr(el, "viewBox"); vb != "" {
+		f := strings.FieldsFunc(vb, func(r rune) bool { return r == ' ' || r == ',' })
+		if len(f) == 4 {
+			var v [4]float64
+			for i := range v {
+				v[i], _ = strconv.ParseFloat(f[i], 64)
+			}
+			return pixel.R(v[0], v[1], v[0]+v[2], v[1]+v[3])
+		}
+	}
+	w, _ := strconv.ParseFloat(strings.TrimSuffix(attr(el, "width"), "px"), 64)
+	h, _ := strconv.ParseFloat(strings.TrimSuffix(attr(el, "height"), "px"), 64)
+	return pixel.R(0, 0, w, h)
+}
+
+// applyStyle returns the style of the element inherited from the parent.
+func (l *loader) applyStyle(el xml.StartElement, parent style) (style, bool) {
+	st := parent
+
+	props := make(map[string]string)
+	for _, a := range el.Attr {
+		props[a.Name.Local] = a.Value
+	}
+	// the style attribute overrides the presentation attributes
+	for _, decl := range strings.Split(props["style"], ";") {
+		kv := strings.SplitN(decl, ":", 2)
+		if len(kv) == 2 {
+			props[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+	}
+
+	if t, ok := props["transform"]; ok {
+		m, err := parseTransform(t)
+		if err != nil {
+			l.warnf("<%s>: %v, element skipped", el.Name.Local, err)
+			return st, false
+		}
+		st.transform = m.Chained(st.transform)
+	}
+
+	if fill, ok := props["fill"]; ok {
+		switch fill = strings.TrimSpace(fill); {
+		case fill == "none":
+			st.noFill = true
+		case fill == "inherit":
+		case strings.HasPrefix(fill, "url("):
+			l.warnf("<%s>: unsupported fill %q (gradients and patterns are not supported), element skipped", el.Name.Local, fill)
+			return st, false
+		default:
+			c, err := parseColor(fill)
+			if err != nil {
+				l.warnf("<%s>: %v, element skipped", el.Name.Local, err)
+				return st, false
+			}
+			st.fill = c
+			st.noFill = false
+		}
+	}
+
+	for _, name := range []string{"opacity", "fill-opacity"} {
+		if v, ok := props[name]; ok {
+			o, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				l.warnf("<%s>: invalid %s %q ignored", el.Name.Local, name, v)
+				continue
+			}
+			st.opacity *= pixel.Clamp(o, 0, 1)
+		}
+	}
+
+	if _, ok := props["stroke"]; ok && props["stroke"] != "none" {
+		l.warnf("<%s>: strokes are not supported, stroke ignored", el.Name.Local)
+	}
+	if _, ok := props["filter"]; ok {
+		l.warnf("<%s>: filters are not supported, filter ignored", el.Name.Local)
+	}
+
+	return st, true
+}
+
+// path flattens and triangulates a path element and adds it to the document.
+func (l *loader) path(el xml.StartElement, st style) {
+	subpaths, err := parsePath(attr(el, "d"), st.transform, l.tolerance)
+	if err != nil {
+		l.warnf("<path id=%q>: %v, path skipped", attr(el, "id"), err)
+		return
+	}
+
+	col := st.fill.Scaled(st.opacity)
+	tri := &pixel.TrianglesData{}
+	for i, sp := range subpaths {
+		for _, other := range subpaths[:i] {
+			if len(sp) > 0 && len(other) >= 3 && polygonContains(other, sp[0]) {
+				l.warnf("<path id=%q>: holes are not supported, subpath %d is filled", attr(el, "id"), i)
+				break
+			}
+		}
+		indices, ok := triangulate(sp)
+		if !ok {
+			l.warnf("<path id=%q>: subpath %d is self-intersecting, it may be filled incorrectly", attr(el, "id"), i)
+		}
+		off := tri.Len()
+		tri.SetLen(off + len(indices))
+		for j, k := range indices {
+			(*tri)[off+j].Position = sp[k]
+			(*tri)[off+j].Color = col
+		}
+	}
+	if tri.Len() == 0 {
+		return
+	}
+
+	l.doc.Shapes = append(l.doc.Shapes, Shape{
+		ID:        attr(el, "id"),
+		Fill:      col,
+		Triangles: tri,
+	})
+}
+
+// flip converts the document from the SVG coordinates (Y axis pointing down) to the pixel's
+// coordinates.
+func (l *loader) flip() {
+	sum := l.doc.Bounds.Min.Y + l.doc.Bounds.Max.Y
+	for _, shape := range l.doc.Shapes {
+		for i := range *shape.Triangles {
+			(*shape.Triangles)[i].Position.Y = sum - (*shape.Triangles)[i].Position.Y
+		}
+	}
+}
+
+func attr(el xml.StartElement, name string) string {
+	for _, a := range el.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
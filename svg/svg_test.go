@@ -0,0 +1,99 @@
+package svg_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/svg"
+)
+
+// area returns the total area of the triangles.
+func area(tri *pixel.TrianglesData) float64 {
+	total := 0.0
+	for i := 0; i+2 < tri.Len(); i += 3 {
+		a, b, c := (*tri)[i].Position, (*tri)[i+1].Position, (*tri)[i+2].Position
+		total += math.Abs(a.To(b).Cross(a.To(c))) / 2
+	}
+	return total
+}
+
+func load(t *testing.T, doc string) *svg.Document {
+	t.Helper()
+	d, err := svg.Load(strings.NewReader(doc), 0.01)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	return d
+}
+
+func TestLoadConcavePath(t *testing.T) {
+	// an L-shape with area 3
+	doc := load(t, `<svg viewBox="0 0 10 10"><path id="l" fill="#f00" d="M0 0 h2 v1 h-1 v1 h-1 z"/></svg>`)
+
+	if doc.Bounds != pixel.R(0, 0, 10, 10) {
+		t.Errorf("Bounds = %v, want %v", doc.Bounds, pixel.R(0, 0, 10, 10))
+	}
+	if len(doc.Shapes) != 1 || len(doc.Warnings) != 0 {
+		t.Fatalf("got %d shapes and warnings %v, want 1 shape and no warnings", len(doc.Shapes), doc.Warnings)
+	}
+	shape := doc.Shapes[0]
+	if shape.ID != "l" || shape.Fill != pixel.RGB(1, 0, 0) {
+		t.Errorf("shape %q with fill %v, want %q with fill %v", shape.ID, shape.Fill, "l", pixel.RGB(1, 0, 0))
+	}
+	if got := area(shape.Triangles); math.Abs(got-3) > 1e-9 {
+		t.Errorf("area = %v, want 3", got)
+	}
+
+	// the document is flipped, (0, 0) of the SVG is the top-left corner
+	for _, v := range *shape.Triangles {
+		if v.Position.Y < 8 || v.Position.Y > 10 {
+			t.Fatalf("vertex at %v, want Y within [8, 10]", v.Position)
+		}
+	}
+}
+
+func TestLoadCurvesAndTransforms(t *testing.T) {
+	// a circle of radius 1 made of 4 cubic curves, scaled twice and translated
+	doc := load(t, `<svg width="100" height="100">
+		<g transform="translate(50 50)" fill="blue" opacity="0.5">
+			<path transform="scale(2)" d="M1 0 C1 0.5523 0.5523 1 0 1 C-0.5523 1 -1 0.5523 -1 0
+				C-1 -0.5523 -0.5523 -1 0 -1 C0.5523 -1 1 -0.5523 1 0 Z"/>
+		</g>
+	</svg>`)
+
+	if len(doc.Shapes) != 1 {
+		t.Fatalf("got %d shapes, want 1", len(doc.Shapes))
+	}
+	shape := doc.Shapes[0]
+	if want := pixel.RGB(0, 0, 1).Scaled(0.5); shape.Fill != want {
+		t.Errorf("fill = %v, want %v", shape.Fill, want)
+	}
+	if got, want := area(shape.Triangles), 4*math.Pi; math.Abs(got-want) > 0.1 {
+		t.Errorf("area = %v, want about %v", got, want)
+	}
+	for _, v := range *shape.Triangles {
+		if d := v.Position.Sub(pixel.V(50, 50)).Len(); d > 2.01 {
+			t.Fatalf("vertex at %v is %v from the center, want at most 2", v.Position, d)
+		}
+	}
+}
+
+func TestLoadWarnings(t *testing.T) {
+	doc := load(t, `<svg viewBox="0 0 10 10">
+		<defs><linearGradient id="g"/></defs>
+		<path fill="url(#g)" d="M0 0 L1 0 L1 1 Z"/>
+		<path d="M0 0 A1 1 0 0 0 1 1 Z"/>
+		<text>hello</text>
+		<path fill="none" d="M0 0 L1 0 L1 1 Z"/>
+		<path d="M0 0 L1 0 L1 1 Z"/>
+	</svg>`)
+
+	if len(doc.Shapes) != 1 {
+		t.Errorf("got %d shapes, want 1", len(doc.Shapes))
+	}
+	if len(doc.Warnings) != 4 {
+		t.Errorf("got warnings %q, want 4 warnings", doc.Warnings)
+	}
+}
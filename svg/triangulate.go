@@ -0,0 +1,104 @@
+package svg
+
+import "github.com/faiface/pixel"
+
+// triangulate triangulates a simple polygon using ear clipping. It returns the indices of the
+// triangles' vertices into the polygon, three per triangle.
+//
+// If the polygon is not simple (it's self-intersecting), the ear clipping gets stuck at some point.
+// The rest of the polygon is then triangulated as a fan and ok is false.
+func triangulate(poly []pixel.Vec) (indices []int, ok bool) {
+	if len(poly) < 3 {
+		return nil, true
+	}
+
+	// ear clipping works on a counter-clockwise polygon
+	remaining := make([]int, len(poly))
+	for i := range remaining {
+		remaining[i] = i
+	}
+	if signedArea(poly) < 0 {
+		for i, j := 0, len(remaining)-1; i < j; i, j = i+1, j-1 {
+			remaining[i], remaining[j] = remaining[j], remaining[i]
+		}
+	}
+
+	indices = make([]int, 0, 3*(len(poly)-2))
+	for len(remaining) > 3 {
+		found := false
+		for i := range remaining {
+			prev := remaining[(i+len(remaining)-1)%len(remaining)]
+			curr := remaining[i]
+			next := remaining[(i+1)%len(remaining)]
+
+			a, b, c := poly[prev], poly[curr], poly[next]
+			cross := a.To(b).Cross(b.To(c))
+			if cross < 0 {
+				continue // reflex vertex
+			}
+			if cross == 0 {
+				// a collinear vertex adds nothing, just drop it
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				found = true
+				break
+			}
+			if !isEar(poly, remaining, prev, curr, next) {
+				continue
+			}
+
+			indices = append(indices, prev, curr, next)
+			remaining = append(remaining[:i], remaining[i+1:]...)
+			found = true
+			break
+		}
+		if !found {
+			for i := 1; i+1 < len(remaining); i++ {
+				indices = append(indices, remaining[0], remaining[i], remaining[i+1])
+			}
+			return indices, false
+		}
+	}
+	if len(remaining) == 3 {
+		indices = append(indices, remaining[0], remaining[1], remaining[2])
+	}
+	return indices, true
+}
+
+// isEar checks whether no other vertex of the polygon lies in the triangle prev curr next.
+func isEar(poly []pixel.Vec, remaining []int, prev, curr, next int) bool {
+	a, b, c := poly[prev], poly[curr], poly[next]
+	for _, k := range remaining {
+		if k == prev || k == curr || k == next {
+			continue
+		}
+		p := poly[k]
+		if p == a || p == b || p == c {
+			continue
+		}
+		if a.To(b).Cross(a.To(p)) >= 0 && b.To(c).Cross(b.To(p)) >= 0 && c.To(a).Cross(c.To(p)) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// signedArea returns the area of the polygon, positive if it's counter-clockwise.
+func signedArea(poly []pixel.Vec) float64 {
+	area := 0.0
+	for i := range poly {
+		area += poly[i].Cross(poly[(i+1)%len(poly)])
+	}
+	return area / 2
+}
+
+// polygonContains checks whether the point lies within the polygon using the even-odd rule.
+func polygonContains(poly []pixel.Vec, p pixel.Vec) bool {
+	inside := false
+	for i, j := 0, len(poly)-1; i < len(poly); j, i = i, i+1 {
+		a, b := poly[i], poly[j]
+		if (a.Y > p.Y) != (b.Y > p.Y) && p.X < (b.X-a.X)*(p.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+	return inside
+}
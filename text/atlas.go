@@ -16,6 +16,9 @@ import (
 var Atlas7x13 *Atlas
 
 // Glyph describes one glyph in an Atlas.
+//
+// Dot and Frame are in the coordinates of the Atlas's Picture, Advance is in logical units (see
+// NewAtlasScaled).
 type Glyph struct {
 	Dot     pixel.Vec
 	Frame   pixel.Rect
@@ -30,6 +33,7 @@ type Atlas struct {
 	ascent     float64
 	descent    float64
 	lineHeight float64
+	scale      float64
 }
 
 // NewAtlas creates a new Atlas containing glyphs of the union of the given sets of runes (plus
@@ -39,6 +43,17 @@ type Atlas struct {
 //
 // Do not destroy or close the font.Face after creating the Atlas. Atlas still uses it.
 func NewAtlas(face font.Face, runeSets ...[]rune) *Atlas {
+	return NewAtlasScaled(face, 1, runeSets...)
+}
+
+// NewAtlasScaled creates a new Atlas just like NewAtlas, but for a high-DPI display with the given
+// scale factor (the number of physical pixels per logical unit).
+//
+// The face must be rasterized at the scale times larger size than the text is supposed to appear,
+// for example a truetype face of size 28 for a 14 units large text on a 2x display. All metrics of
+// the Atlas (LineHeight, Kern, Advance, ...) and the glyphs drawn with it are in logical units, so
+// a Text using it has the same layout as with an unscaled Atlas, just sharper.
+func NewAtlasScaled(face font.Face, scale float64, runeSets ...[]rune) *Atlas {
 	seen := make(map[rune]bool)
 	runes := []rune{unicode.ReplacementChar}
 	for _, set := range runeSets {
@@ -84,7 +99,7 @@ func NewAtlas(face font.Face, runeSets ...[]rune) *Atlas {
 				i2f(fg.frame.Max.X),
 				bounds.Max.Y-(i2f(fg.frame.Max.Y)-bounds.Min.Y),
 			).Norm(),
-			Advance: i2f(fg.advance),
+			Advance: i2f(fg.advance) / scale,
 		}
 	}
 
@@ -92,12 +107,18 @@ func NewAtlas(face font.Face, runeSets ...[]rune) *Atlas {
 		face:       face,
 		pic:        pixel.PictureDataFromImage(atlasImg),
 		mapping:    mapping,
-		ascent:     i2f(face.Metrics().Ascent),
-		descent:    i2f(face.Metrics().Descent),
-		lineHeight: i2f(face.Metrics().Height),
+		ascent:     i2f(face.Metrics().Ascent) / scale,
+		descent:    i2f(face.Metrics().Descent) / scale,
+		lineHeight: i2f(face.Metrics().Height) / scale,
+		scale:      scale,
 	}
 }
 
+// Scale returns the scale factor the Atlas was created with, see NewAtlasScaled.
+func (a *Atlas) Scale() float64 {
+	return a.scale
+}
+
 // Picture returns the underlying Picture containing an arrangement of all the glyphs contained
 // within the Atlas.
 func (a *Atlas) Picture() pixel.Picture {
@@ -118,7 +139,7 @@ func (a *Atlas) Glyph(r rune) Glyph {
 // Kern returns the kerning distance between runes r0 and r1. Positive distance means that the
 // glyphs should be further apart.
 func (a *Atlas) Kern(r0, r1 rune) float64 {
-	return i2f(a.face.Kern(r0, r1))
+	return i2f(a.face.Kern(r0, r1)) / a.scale
 }
 
 // Ascent returns the distance from the top of the line to the baseline.
@@ -157,7 +178,10 @@ func (a *Atlas) DrawRune(prevR, r rune, dot pixel.Vec) (rect, frame, bounds pixe
 
 	glyph := a.Glyph(r)
 
-	rect = glyph.Frame.Moved(dot.Sub(glyph.Dot))
+	rect = pixel.Rect{
+		Min: glyph.Frame.Min.Sub(glyph.Dot).Scaled(1 / a.scale),
+		Max: glyph.Frame.Max.Sub(glyph.Dot).Scaled(1 / a.scale),
+	}.Moved(dot)
 	bounds = rect
 
 	if bounds.W()*bounds.H() != 0 {
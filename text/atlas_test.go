@@ -1,9 +1,16 @@
 package text_test
 
 import (
+	"fmt"
+	"math"
 	"testing"
 
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+
+	"github.com/faiface/pixel"
 	"github.com/faiface/pixel/text"
+	"github.com/golang/freetype/truetype"
 )
 
 func TestAtlas7x13(t *testing.T) {
@@ -22,3 +29,34 @@ func TestAtlas7x13(t *testing.T) {
 		}
 	}
 }
+
+func TestAtlasCacheScale(t *testing.T) {
+	ttf, _ := truetype.Parse(goregular.TTF)
+	cache := text.NewAtlasCache(func(size float64) font.Face {
+		return truetype.NewFace(ttf, &truetype.Options{Size: size})
+	}, text.ASCII)
+
+	a1, a2 := cache.Atlas(14, 1), cache.Atlas(14, 2)
+	if cache.Atlas(14, 2) != a2 {
+		t.Fatalf("AtlasCache.Atlas returned a different Atlas for the same size and scale")
+	}
+	if a2.Scale() != 2 {
+		t.Fatalf("Atlas.Scale() = %v, want 2", a2.Scale())
+	}
+	if math.Abs(a1.LineHeight()-a2.LineHeight()) > 1 {
+		t.Fatalf("LineHeight() = %v at scale 1 and %v at scale 2", a1.LineHeight(), a2.LineHeight())
+	}
+
+	txt := text.New(pixel.ZV, a1)
+	fmt.Fprint(txt, "Hello,\nworld!")
+	b1, dot := txt.Bounds(), txt.Dot
+
+	txt.SetAtlas(a2)
+	b2 := txt.Bounds()
+	if !eqVectors(txt.Dot, dot) {
+		t.Fatalf("txt.Dot = %v after SetAtlas, want %v", txt.Dot, dot)
+	}
+	if b2.W() == 0 || math.Abs(b1.W()-b2.W()) > 2 || math.Abs(b1.H()-b2.H()) > 2 {
+		t.Fatalf("txt.Bounds() = %v after SetAtlas, want about %v", b2, b1)
+	}
+}
@@ -0,0 +1,58 @@
+package text
+
+import (
+	"sync"
+
+	"golang.org/x/image/font"
+)
+
+// AtlasCache creates Atlases of one font at different sizes and scale factors and caches them, so
+// that multiple Texts using the same font at the same size share one Atlas.
+//
+// This is mainly useful for high-DPI support. When a Window moves to a display with a different
+// scale factor, switch all Texts to the Atlas with the new scale:
+//
+//   cache := text.NewAtlasCache(func(size float64) font.Face {
+//       return truetype.NewFace(ttf, &truetype.Options{Size: size})
+//   }, text.ASCII)
+//   txt := text.New(orig, cache.Atlas(14, 1))
+//   // ... the scale factor changed
+//   txt.SetAtlas(cache.Atlas(14, 2))
+//
+// AtlasCache is safe for concurrent use.
+type AtlasCache struct {
+	newFace  func(size float64) font.Face
+	runeSets [][]rune
+
+	mu      sync.Mutex
+	atlases map[atlasKey]*Atlas
+}
+
+type atlasKey struct {
+	size, scale float64
+}
+
+// NewAtlasCache creates a new empty AtlasCache. The newFace function creates a face of the font at
+// the given size in pixels. The Atlases will contain the union of the given sets of runes.
+func NewAtlasCache(newFace func(size float64) font.Face, runeSets ...[]rune) *AtlasCache {
+	return &AtlasCache{
+		newFace:  newFace,
+		runeSets: runeSets,
+		atlases:  make(map[atlasKey]*Atlas),
+	}
+}
+
+// Atlas returns an Atlas of the font for a text of the given logical size rasterized at the given
+// scale factor. The Atlas is created using NewAtlasScaled if it's not in the cache yet.
+func (ac *AtlasCache) Atlas(size, scale float64) *Atlas {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	key := atlasKey{size, scale}
+	if atlas, ok := ac.atlases[key]; ok {
+		return atlas
+	}
+	atlas := NewAtlasScaled(ac.newFace(size*scale), scale, ac.runeSets...)
+	ac.atlases[key] = atlas
+	return atlas
+}
@@ -0,0 +1,34 @@
+package text_test
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/text"
+)
+
+// This example combines two per-glyph effects of a dialogue box: the letters appear one by one like
+// on a typewriter, each fading in, and the text is waving.
+func ExampleText_DrawGlyphs() {
+	txt := text.New(pixel.V(100, 100), text.Atlas7x13)
+	fmt.Fprint(txt, "Hello, traveller!\nWhat brings you here?")
+
+	// the Target would usually be a Window
+	target := pixel.NewBatch(&pixel.TrianglesData{}, text.Atlas7x13.Picture())
+
+	const lettersPerSecond = 20
+	for t := 0.0; t < 3; t += 1.0 / 60 {
+		target.Clear()
+		txt.DrawGlyphs(target, pixel.IM, func(i int, g text.GlyphQuad) (pixel.Matrix, color.Color) {
+			// typewriter: the i-th letter starts appearing at i/lettersPerSecond and fades in
+			alpha := pixel.Clamp(t*lettersPerSecond-float64(i), 0, 1)
+
+			// wave: letters bounce up and down with a phase shifted by their position
+			wave := 2 * math.Sin(6*t+g.Rect.Center().X/10)
+
+			return pixel.IM.Moved(pixel.V(0, wave)), pixel.Alpha(alpha)
+		})
+	}
+}
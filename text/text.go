@@ -90,17 +90,45 @@ type Text struct {
 
 	atlas *Atlas
 
-	buf    []byte
-	prevR  rune
-	bounds pixel.Rect
-	glyph  pixel.TrianglesData
-	tris   pixel.TrianglesData
-
-	mat    pixel.Matrix
-	col    pixel.RGBA
-	trans  pixel.TrianglesData
-	transD pixel.Drawer
-	dirty  bool
+	buf     []byte
+	prevR   rune
+	bounds  pixel.Rect
+	glyph   pixel.TrianglesData
+	tris    pixel.TrianglesData
+	written []writtenGlyph
+
+	mat      pixel.Matrix
+	col      pixel.RGBA
+	rounding PixelRounding
+	trans    pixel.TrianglesData
+	transD   pixel.Drawer
+	dirty    bool
+}
+
+// PixelRounding specifies how a Text rounds the positions of the glyphs to whole pixels when it's
+// drawn, see SetPixelRounding.
+type PixelRounding int
+
+const (
+	// NoRounding draws the glyphs exactly where the Matrix puts them.
+	NoRounding PixelRounding = iota
+
+	// RoundDot moves the whole text, so that its Orig lands on a whole pixel. The glyphs keep their
+	// exact relative positions, but may still fall between the pixels.
+	RoundDot
+
+	// RoundGlyphs moves each glyph, so that the dot it was written at lands on a whole pixel. The
+	// dots include the kerning and the fractional advances, so the glyphs move by less than half a
+	// pixel each and the spacing stays even.
+	RoundGlyphs
+)
+
+// writtenGlyph records a glyph written to a Text, so that the Text can be laid out again with a
+// different Atlas.
+type writtenGlyph struct {
+	prevR, r rune
+	dot      pixel.Vec
+	col      pixel.RGBA
 }
 
 // New creates a new Text capable of drawing runes contained in the provided Atlas. Orig and Dot
@@ -147,6 +175,66 @@ func (txt *Text) Atlas() *Atlas {
 	return txt.atlas
 }
 
+// SetAtlas changes the Atlas of the Text. All the text already written to the Text is laid out
+// again using the new Atlas.
+//
+// This is mainly useful for switching to an Atlas with a different scale factor (see
+// NewAtlasScaled and AtlasCache), because the positions of the already written glyphs are
+// preserved, so the Bounds stay the same (up to rounding of the glyph metrics).
+func (txt *Text) SetAtlas(atlas *Atlas) {
+	txt.atlas = atlas
+	txt.transD.Picture = atlas.pic
+
+	txt.bounds = pixel.Rect{}
+	txt.tris.SetLen(0)
+	for _, g := range txt.written {
+		rect, frame, bounds, _ := atlas.DrawRune(g.prevR, g.r, g.dot)
+		txt.pushGlyph(rect, frame, bounds, g.col)
+	}
+	txt.dirty = true
+}
+
+// SetPixelRounding sets how the glyphs are rounded to whole pixels when the Text is drawn.
+// The default is NoRounding.
+//
+// Text moving by fractional pixels (e.g. in the world space under a smoothly moving camera)
+// shimmers, because the glyphs are sampled from the Atlas between its pixels. Rounding makes it
+// move by whole pixels instead:
+//
+//   txt.SetPixelRounding(text.RoundGlyphs)
+//   txt.Draw(win, cam)
+//
+// The rounding is done after the Matrix passed to Draw, in the units of the Target, so it only
+// helps when these are pixels and the Matrix doesn't scale the text by a fraction or rotate it.
+func (txt *Text) SetPixelRounding(rounding PixelRounding) {
+	if rounding != txt.rounding {
+		txt.rounding = rounding
+		txt.dirty = true
+	}
+}
+
+// PixelRounding returns the rounding set by SetPixelRounding.
+func (txt *Text) PixelRounding() PixelRounding {
+	return txt.rounding
+}
+
+// roundQuad moves the transformed quad of the glyph i according to the PixelRounding.
+func (txt *Text) roundQuad(i int, quad pixel.TrianglesData, m pixel.Matrix) {
+	var at pixel.Vec
+	switch txt.rounding {
+	case RoundDot:
+		at = m.Project(txt.Orig)
+	case RoundGlyphs:
+		at = m.Project(txt.written[i].dot)
+	default:
+		return
+	}
+	offset := pixel.V(math.Floor(at.X+0.5), math.Floor(at.Y+0.5)).Sub(at)
+	for j := range quad {
+		quad[j].Position = quad[j].Position.Add(offset)
+	}
+}
+
 // Bounds returns the bounding box of the text currently written to the Text excluding whitespace.
 //
 // If the Text is empty, a zero rectangle is returned.
@@ -154,7 +242,14 @@ func (txt *Text) Bounds() pixel.Rect {
 	return txt.bounds
 }
 
-// BoundsOf returns the bounding box of s if it was to be written to the Text right now.
+// BoundsOf returns the bounding box of s if it was to be written to the Text right now, excluding
+// whitespace, just like Bounds would after writing only s. Newlines and tabs move the dot exactly
+// like when writing. The Text itself is not changed, so BoundsOf is useful for layout (centering,
+// sizing buttons, wrapping) before writing:
+//
+//   b := txt.BoundsOf(label)
+//   txt.Dot = txt.Dot.Sub(pixel.V(b.W()/2, 0))
+//   txt.WriteString(label)
 func (txt *Text) BoundsOf(s string) pixel.Rect {
 	dot := txt.Dot
 	prevR := txt.prevR
@@ -187,6 +282,7 @@ func (txt *Text) Clear() {
 	txt.prevR = -1
 	txt.bounds = pixel.Rect{}
 	txt.tris.SetLen(0)
+	txt.written = txt.written[:0]
 	txt.dirty = true
 	txt.Dot = txt.Orig
 }
@@ -261,6 +357,9 @@ func (txt *Text) DrawColorMask(t pixel.Target, matrix pixel.Matrix, mask color.C
 			txt.trans[i].Position = txt.mat.Project(txt.trans[i].Position)
 			txt.trans[i].Color = txt.trans[i].Color.Mul(txt.col)
 		}
+		for i := range txt.written {
+			txt.roundQuad(i, txt.trans[i*6:i*6+6], txt.mat)
+		}
 
 		txt.transD.Dirty()
 		txt.dirty = false
@@ -269,6 +368,149 @@ func (txt *Text) DrawColorMask(t pixel.Target, matrix pixel.Matrix, mask color.C
 	txt.transD.Draw(t)
 }
 
+// GlyphQuad is a glyph written to a Text, as returned by Glyphs and passed to the DrawGlyphs
+// callback.
+type GlyphQuad struct {
+	// Index is the index of the glyph among all glyphs written to the Text. Control runes (such
+	// as newlines) don't produce glyphs and don't have an index.
+	Index int
+
+	// Rune is the rune drawn by the glyph.
+	Rune rune
+
+	// Frame is the glyph's frame in the Atlas's Picture.
+	Frame pixel.Rect
+
+	// Rect is the quad the glyph is drawn to, in the Text's coordinates (before applying any
+	// Matrix).
+	Rect pixel.Rect
+
+	// Color is the Color the glyph was written with.
+	Color pixel.RGBA
+}
+
+// Glyphs returns all glyphs currently written to the Text.
+func (txt *Text) Glyphs() []GlyphQuad {
+	glyphs := make([]GlyphQuad, len(txt.written))
+	for i := range glyphs {
+		glyphs[i] = txt.glyphQuad(i)
+	}
+	return glyphs
+}
+
+func (txt *Text) glyphQuad(i int) GlyphQuad {
+	// every glyph is a quad of two triangles, vertices 0 and 2 are the opposite corners
+	quad := txt.tris[i*6 : i*6+6]
+	return GlyphQuad{
+		Index: i,
+		Rune:  txt.written[i].r,
+		Frame: pixel.Rect{Min: quad[0].Picture, Max: quad[2].Picture},
+		Rect:  pixel.Rect{Min: quad[0].Position, Max: quad[2].Position},
+		Color: txt.written[i].col,
+	}
+}
+
+// DrawGlyphs draws all text written to the Text to the provided Target, letting the function f
+// transform and color each glyph individually. This is useful for animated text effects, such as
+// wavy text or letters fading in one by one.
+//
+// For each glyph, f returns a Matrix, which is applied to the glyph in the Text's coordinates
+// before the provided Matrix, and a color mask multiplying the glyph's color (nil means no
+// effect):
+//
+//   txt.DrawGlyphs(win, pixel.IM, func(i int, g text.GlyphQuad) (pixel.Matrix, color.Color) {
+//       wave := pixel.V(0, 3*math.Sin(4*t+float64(i)/2))
+//       return pixel.IM.Moved(wave), nil
+//   })
+//
+// All glyphs are still drawn at once with the Atlas's Picture, so this is only a bit more expensive
+// than DrawColorMask, but the glyphs are transformed on every call.
+func (txt *Text) DrawGlyphs(t pixel.Target, matrix pixel.Matrix, f func(i int, g GlyphQuad) (pixel.Matrix, color.Color)) {
+	txt.trans.SetLen(txt.tris.Len())
+	txt.trans.Update(&txt.tris)
+
+	for i := range txt.written {
+		m, mask := f(i, txt.glyphQuad(i))
+		m = m.Chained(matrix)
+		if mask == nil {
+			mask = pixel.Alpha(1)
+		}
+		rgba := pixel.ToRGBA(mask)
+
+		quad := txt.trans[i*6 : i*6+6]
+		for j := range quad {
+			quad[j].Position = m.Project(quad[j].Position)
+			quad[j].Color = quad[j].Color.Mul(rgba)
+		}
+		txt.roundQuad(i, quad, m)
+	}
+
+	txt.transD.Dirty()
+	txt.transD.Draw(t)
+
+	// the transformed triangles no longer match the matrix and the color mask of DrawColorMask
+	txt.dirty = true
+}
+
+// Render draws all text written to the Text into a new PictureData, without any Target. The
+// PictureData covers the Bounds of the Text rounded out to whole pixels, in the Text's coordinates,
+// and is transparent outside of the glyphs. It's useful for baking static labels, which are then
+// drawn as a single Sprite:
+//
+//   baked := txt.Render()
+//   label := pixel.NewSprite(baked, baked.Bounds())
+//
+// The glyphs are copied from the Atlas's Picture pixel by pixel, without any filtering, so write
+// the text at whole-pixel positions (see SetPixelRounding) for crisp results.
+func (txt *Text) Render() *pixel.PictureData {
+	bounds := txt.Bounds()
+	for i := range txt.written {
+		bounds = bounds.Union(txt.glyphQuad(i).Rect)
+	}
+	pd := pixel.MakePictureData(pixel.R(
+		math.Floor(bounds.Min.X),
+		math.Floor(bounds.Min.Y),
+		math.Ceil(bounds.Max.X),
+		math.Ceil(bounds.Max.Y),
+	))
+
+	atlas, ok := txt.atlas.Picture().(*pixel.PictureData)
+	if !ok {
+		atlas = pixel.PictureDataFromPicture(txt.atlas.Picture())
+	}
+
+	for i := range txt.written {
+		g := txt.glyphQuad(i)
+		if g.Rect.W() == 0 || g.Rect.H() == 0 {
+			continue
+		}
+		scale := pixel.V(g.Frame.W()/g.Rect.W(), g.Frame.H()/g.Rect.H())
+		for y := math.Floor(g.Rect.Min.Y); y < g.Rect.Max.Y; y++ {
+			for x := math.Floor(g.Rect.Min.X); x < g.Rect.Max.X; x++ {
+				at := pixel.V(x+0.5, y+0.5)
+				if !g.Rect.Contains(at) || !pd.Rect.Contains(at) {
+					continue
+				}
+				src := atlas.Color(g.Frame.Min.Add(at.Sub(g.Rect.Min).ScaledXY(scale))).Mul(g.Color)
+				if src.A == 0 {
+					continue
+				}
+				// the glyphs may overlap, blend them like a Target would
+				j := pd.Index(at)
+				dst := pixel.ToRGBA(pd.Pix[j])
+				out := src.Add(dst.Scaled(1 - src.A))
+				pd.Pix[j] = color.RGBA{
+					R: uint8(math.Floor(out.R*255 + 0.5)),
+					G: uint8(math.Floor(out.G*255 + 0.5)),
+					B: uint8(math.Floor(out.B*255 + 0.5)),
+					A: uint8(math.Floor(out.A*255 + 0.5)),
+				}
+			}
+		}
+	}
+	return pd
+}
+
 // controlRune checks if r is a control rune (newline, tab, ...). If it is, a new dot position and
 // true is returned. If r is not a control rune, the original dot and false is returned.
 func (txt *Text) controlRune(r rune, dot pixel.Vec) (newDot pixel.Vec, control bool) {
@@ -297,9 +539,6 @@ func (txt *Text) drawBuf() {
 	}
 
 	rgba := pixel.ToRGBA(txt.Color)
-	for i := range txt.glyph {
-		txt.glyph[i].Color = rgba
-	}
 
 	for utf8.FullRune(txt.buf) {
 		r, size := utf8.DecodeRune(txt.buf)
@@ -311,37 +550,50 @@ func (txt *Text) drawBuf() {
 			continue
 		}
 
+		txt.written = append(txt.written, writtenGlyph{
+			prevR: txt.prevR,
+			r:     r,
+			dot:   txt.Dot,
+			col:   rgba,
+		})
+
 		var rect, frame, bounds pixel.Rect
 		rect, frame, bounds, txt.Dot = txt.Atlas().DrawRune(txt.prevR, r, txt.Dot)
 
 		txt.prevR = r
 
-		rv := [...]pixel.Vec{
-			{X: rect.Min.X, Y: rect.Min.Y},
-			{X: rect.Max.X, Y: rect.Min.Y},
-			{X: rect.Max.X, Y: rect.Max.Y},
-			{X: rect.Min.X, Y: rect.Max.Y},
-		}
+		txt.pushGlyph(rect, frame, bounds, rgba)
+	}
+}
 
-		fv := [...]pixel.Vec{
-			{X: frame.Min.X, Y: frame.Min.Y},
-			{X: frame.Max.X, Y: frame.Min.Y},
-			{X: frame.Max.X, Y: frame.Max.Y},
-			{X: frame.Min.X, Y: frame.Max.Y},
-		}
+// pushGlyph appends a glyph quad to the Text's triangles.
+func (txt *Text) pushGlyph(rect, frame, bounds pixel.Rect, col pixel.RGBA) {
+	rv := [...]pixel.Vec{
+		{X: rect.Min.X, Y: rect.Min.Y},
+		{X: rect.Max.X, Y: rect.Min.Y},
+		{X: rect.Max.X, Y: rect.Max.Y},
+		{X: rect.Min.X, Y: rect.Max.Y},
+	}
 
-		for i, j := range [...]int{0, 1, 2, 0, 2, 3} {
-			txt.glyph[i].Position = rv[j]
-			txt.glyph[i].Picture = fv[j]
-		}
+	fv := [...]pixel.Vec{
+		{X: frame.Min.X, Y: frame.Min.Y},
+		{X: frame.Max.X, Y: frame.Min.Y},
+		{X: frame.Max.X, Y: frame.Max.Y},
+		{X: frame.Min.X, Y: frame.Max.Y},
+	}
 
-		txt.tris = append(txt.tris, txt.glyph...)
-		txt.dirty = true
+	for i, j := range [...]int{0, 1, 2, 0, 2, 3} {
+		txt.glyph[i].Position = rv[j]
+		txt.glyph[i].Picture = fv[j]
+		txt.glyph[i].Color = col
+	}
 
-		if txt.bounds.W()*txt.bounds.H() == 0 {
-			txt.bounds = bounds
-		} else {
-			txt.bounds = txt.bounds.Union(bounds)
-		}
+	txt.tris = append(txt.tris, txt.glyph...)
+	txt.dirty = true
+
+	if txt.bounds.W()*txt.bounds.H() == 0 {
+		txt.bounds = bounds
+	} else {
+		txt.bounds = txt.bounds.Union(bounds)
 	}
 }
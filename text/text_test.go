@@ -2,6 +2,8 @@ package text_test
 
 import (
 	"fmt"
+	"image/color"
+	"math"
 	"math/rand"
 	"testing"
 	"unicode"
@@ -85,3 +87,160 @@ func BenchmarkTextWrite(b *testing.B) {
 func eqVectors(a, b pixel.Vec) bool {
 	return (a.X == b.X && a.Y == b.Y)
 }
+
+func TestGlyphs(t *testing.T) {
+	txt := text.New(pixel.ZV, text.Atlas7x13)
+	fmt.Fprint(txt, "ab\nc")
+
+	glyphs := txt.Glyphs()
+	if len(glyphs) != 3 {
+		t.Fatalf("len(txt.Glyphs()) = %d, want 3", len(glyphs))
+	}
+	for i, r := range "abc" {
+		if glyphs[i].Index != i || glyphs[i].Rune != r {
+			t.Errorf("glyph %d is %d %q, want %d %q", i, glyphs[i].Index, glyphs[i].Rune, i, r)
+		}
+		if want := text.Atlas7x13.Glyph(r).Frame; glyphs[i].Frame != want {
+			t.Errorf("glyph %d frame = %v, want %v", i, glyphs[i].Frame, want)
+		}
+	}
+
+	tri := &pixel.TrianglesData{}
+	batch := pixel.NewBatch(tri, text.Atlas7x13.Picture())
+	txt.DrawGlyphs(batch, pixel.IM.Moved(pixel.V(100, 0)), func(i int, g text.GlyphQuad) (pixel.Matrix, color.Color) {
+		return pixel.IM.Moved(pixel.V(0, float64(i))), pixel.Alpha(0.5)
+	})
+
+	if tri.Len() != 3*6 {
+		t.Fatalf("drawn %d vertices, want %d", tri.Len(), 3*6)
+	}
+	for i, g := range glyphs {
+		want := g.Rect.Min.Add(pixel.V(100, float64(i)))
+		if got := (*tri)[i*6].Position; !eqVectors(got, want) {
+			t.Errorf("glyph %d drawn at %v, want %v", i, got, want)
+		}
+		if got := (*tri)[i*6].Color; got != pixel.Alpha(0.5) {
+			t.Errorf("glyph %d color = %v, want %v", i, got, pixel.Alpha(0.5))
+		}
+	}
+}
+
+func TestPixelRounding(t *testing.T) {
+	ttf, err := truetype.Parse(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	atlas := text.NewAtlas(truetype.NewFace(ttf, &truetype.Options{Size: 13}), text.ASCII)
+
+	// scrolls the text by 0.25 pixels per frame and returns the mean variance of the subpixel
+	// positions of the glyphs among the frames
+	scroll := func(rounding text.PixelRounding) float64 {
+		txt := text.New(pixel.V(0.3, 0.6), atlas)
+		txt.SetPixelRounding(rounding)
+		fmt.Fprint(txt, "Shimmering AV text")
+
+		glyphs := len(txt.Glyphs())
+		sum := make([]float64, glyphs)
+		sumSq := make([]float64, glyphs)
+		const frames = 8
+		for frame := 0; frame < frames; frame++ {
+			tri := &pixel.TrianglesData{}
+			txt.Draw(pixel.NewBatch(tri, atlas.Picture()), pixel.IM.Moved(pixel.V(0.25*float64(frame), 0)))
+			for i := 0; i < glyphs; i++ {
+				x := (*tri)[i*6].Position.X
+				phase := x - math.Floor(x+1e-9)
+				sum[i] += phase
+				sumSq[i] += phase * phase
+			}
+		}
+
+		variance := 0.0
+		for i := range sum {
+			mean := sum[i] / frames
+			variance += sumSq[i]/frames - mean*mean
+		}
+		return variance / float64(glyphs)
+	}
+
+	if v := scroll(text.NoRounding); v < 0.01 {
+		t.Errorf("NoRounding: phase variance %v, the test doesn't scroll by subpixels", v)
+	}
+	if v := scroll(text.RoundGlyphs); v > 0.01 {
+		t.Errorf("RoundGlyphs: phase variance %v, want the glyphs to keep their phase", v)
+	}
+
+	// RoundGlyphs puts every dot on a whole pixel, RoundDot only the origin
+	txt := text.New(pixel.V(0.3, 0.6), atlas)
+	fmt.Fprint(txt, "AV")
+	for _, rounding := range []text.PixelRounding{text.RoundDot, text.RoundGlyphs} {
+		txt.SetPixelRounding(rounding)
+		tri := &pixel.TrianglesData{}
+		txt.Draw(pixel.NewBatch(tri, atlas.Picture()), pixel.IM.Moved(pixel.V(10.4, 0)))
+		for i, g := range txt.Glyphs() {
+			moved := (*tri)[i*6].Position.Sub(g.Rect.Min.Add(pixel.V(10.4, 0)))
+			if moved.Len() > 0.5*math.Sqrt2+1e-9 {
+				t.Errorf("rounding %d: glyph %d moved by %v", rounding, i, moved)
+			}
+			if rounding == text.RoundDot && i > 0 {
+				if first := (*tri)[0].Position.Sub(txt.Glyphs()[0].Rect.Min.Add(pixel.V(10.4, 0))); moved.To(first).Len() > 1e-9 {
+					t.Errorf("RoundDot: glyph %d moved by %v, glyph 0 by %v", i, moved, first)
+				}
+			}
+		}
+	}
+}
+
+func TestBoundsOf(t *testing.T) {
+	for _, s := range []string{"Hello", "Hello\nworld!", "a\tb\n\tc\rd", "  spaces  "} {
+		txt := text.New(pixel.V(10, 20), text.Atlas7x13)
+		txt.TabWidth = 4 * 7
+
+		got := txt.BoundsOf(s)
+		if !eqVectors(txt.Dot, pixel.V(10, 20)) {
+			t.Errorf("%q: BoundsOf moved the dot to %v", s, txt.Dot)
+		}
+		txt.WriteString(s)
+		if want := txt.Bounds(); !eqVectors(got.Min, want.Min) || !eqVectors(got.Max, want.Max) {
+			t.Errorf("%q: BoundsOf = %v, want %v", s, got, want)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	txt := text.New(pixel.V(10, 20), text.Atlas7x13)
+	txt.Color = pixel.RGB(1, 0, 0)
+	txt.WriteString("Hi\nthere")
+
+	pd := txt.Render()
+	b := txt.Bounds()
+	if want := pixel.R(math.Floor(b.Min.X), math.Floor(b.Min.Y), math.Ceil(b.Max.X), math.Ceil(b.Max.Y)); pd.Rect != want {
+		t.Fatalf("Render covers %v, want %v", pd.Rect, want)
+	}
+
+	atlas := text.Atlas7x13.Picture().(*pixel.PictureData)
+	covered := make(map[int]bool)
+	opaque := 0
+	for _, g := range txt.Glyphs() {
+		for y := g.Rect.Min.Y + 0.5; y < g.Rect.Max.Y; y++ {
+			for x := g.Rect.Min.X + 0.5; x < g.Rect.Max.X; x++ {
+				at := pixel.V(x, y)
+				covered[pd.Index(at)] = true
+				want := atlas.Color(g.Frame.Min.Add(at.Sub(g.Rect.Min))).Mul(pixel.RGB(1, 0, 0))
+				if got := pd.Color(at); got != want {
+					t.Fatalf("%q at %v is %v, want %v", g.Rune, at, got, want)
+				}
+				if want.A > 0 {
+					opaque++
+				}
+			}
+		}
+	}
+	if opaque == 0 {
+		t.Errorf("no opaque pixels rendered")
+	}
+	for i, c := range pd.Pix {
+		if !covered[i] && c.A != 0 {
+			t.Errorf("pixel %d outside of the glyphs is %v", i, c)
+		}
+	}
+}
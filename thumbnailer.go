@@ -0,0 +1,276 @@
+package pixel
+
+import (
+	"container/list"
+	"image/color"
+	"math"
+	"sync"
+)
+
+// Thumbnailer generates small previews of Pictures (e.g. for an asset browser) on background
+// goroutines, so that loading and downsampling hundreds of them doesn't stutter the UI.
+//
+// Thumbnails are cached by their keys in memory, the least recently used ones are dropped when the
+// cache exceeds its budget (see SetBudget). Requests for a key which is already being generated
+// share the work. Pending requests are processed newest first, so the thumbnails scrolled into
+// view last are generated first.
+//
+// Thumbnailer only produces PictureData, its upload to the video memory happens when it's first
+// drawn, as usual.
+type Thumbnailer struct {
+	maxSize int
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	jobs   map[string]*thumbnailJob
+	queue  []*thumbnailJob
+	closed bool
+
+	budget int
+	used   int
+	lru    *list.List // of *thumbnailEntry, most recently used first
+	cache  map[string]*list.Element
+}
+
+type thumbnailJob struct {
+	key     string
+	src     func() (*PictureData, error)
+	waiters []chan *PictureData
+	running bool
+}
+
+type thumbnailEntry struct {
+	key string
+	pd  *PictureData
+}
+
+// NewThumbnailer creates a Thumbnailer with the given number of worker goroutines, which produces
+// thumbnails no larger than maxSize pixels in each dimension.
+//
+// The cache budget defaults to 32 MiB.
+func NewThumbnailer(workers int, maxSize int) *Thumbnailer {
+	if workers < 1 {
+		workers = 1
+	}
+	if maxSize < 1 {
+		maxSize = 1
+	}
+	t := &Thumbnailer{
+		maxSize: maxSize,
+		jobs:    make(map[string]*thumbnailJob),
+		budget:  32 << 20,
+		lru:     list.New(),
+		cache:   make(map[string]*list.Element),
+	}
+	t.cond = sync.NewCond(&t.mu)
+	for i := 0; i < workers; i++ {
+		go t.work()
+	}
+	return t
+}
+
+// SetBudget sets the maximal number of bytes taken by the cached thumbnails.
+func (t *Thumbnailer) SetBudget(bytes int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.budget = bytes
+	t.evict()
+}
+
+// Request returns a channel which receives the thumbnail of the Picture returned by src. The key
+// identifies the Picture, src is only called (on a worker goroutine) if the thumbnail isn't cached
+// or already being generated.
+//
+// The channel is closed after receiving the thumbnail. If src fails, or the request gets cancelled,
+// the channel is closed without receiving anything (so a receive yields nil).
+//
+// The returned PictureData is shared by all requests for the key, don't modify it.
+func (t *Thumbnailer) Request(key string, src func() (*PictureData, error)) <-chan *PictureData {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ch := make(chan *PictureData, 1)
+	if t.closed {
+		close(ch)
+		return ch
+	}
+	if e, ok := t.cache[key]; ok {
+		t.lru.MoveToFront(e)
+		ch <- e.Value.(*thumbnailEntry).pd
+		close(ch)
+		return ch
+	}
+	if job, ok := t.jobs[key]; ok {
+		job.waiters = append(job.waiters, ch)
+		return ch
+	}
+
+	job := &thumbnailJob{key: key, src: src, waiters: []chan *PictureData{ch}}
+	t.jobs[key] = job
+	t.queue = append(t.queue, job)
+	t.cond.Signal()
+	return ch
+}
+
+// Cancel cancels all requests for the key (e.g. because the thumbnail scrolled out of view), their
+// channels get closed. If the thumbnail isn't being generated yet, src is never called. Otherwise
+// the generation finishes and the thumbnail gets cached anyway.
+func (t *Thumbnailer) Cancel(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	job, ok := t.jobs[key]
+	if !ok {
+		return
+	}
+	for _, ch := range job.waiters {
+		close(ch)
+	}
+	job.waiters = nil
+	if !job.running {
+		delete(t.jobs, key)
+		t.dequeue(job)
+	}
+}
+
+// Close cancels all pending requests and stops the worker goroutines once they finish the
+// thumbnails they're generating. Requests made after Close are closed immediately.
+func (t *Thumbnailer) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.closed = true
+	for _, job := range t.queue {
+		for _, ch := range job.waiters {
+			close(ch)
+		}
+		delete(t.jobs, job.key)
+	}
+	t.queue = nil
+	t.cond.Broadcast()
+}
+
+func (t *Thumbnailer) work() {
+	for {
+		t.mu.Lock()
+		for len(t.queue) == 0 && !t.closed {
+			t.cond.Wait()
+		}
+		if t.closed {
+			t.mu.Unlock()
+			return
+		}
+		job := t.queue[len(t.queue)-1]
+		t.queue = t.queue[:len(t.queue)-1]
+		job.running = true
+		t.mu.Unlock()
+
+		var thumb *PictureData
+		if pd, err := job.src(); err == nil && pd != nil {
+			thumb = Downsample(pd, t.maxSize)
+		}
+
+		t.finish(job, thumb)
+	}
+}
+
+func (t *Thumbnailer) finish(job *thumbnailJob, thumb *PictureData) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.jobs, job.key)
+	for _, ch := range job.waiters {
+		if thumb != nil {
+			ch <- thumb
+		}
+		close(ch)
+	}
+	job.waiters = nil
+
+	if thumb != nil {
+		t.cache[job.key] = t.lru.PushFront(&thumbnailEntry{key: job.key, pd: thumb})
+		t.used += pictureDataBytes(thumb)
+		t.evict()
+	}
+}
+
+func (t *Thumbnailer) dequeue(job *thumbnailJob) {
+	for i, j := range t.queue {
+		if j == job {
+			t.queue = append(t.queue[:i], t.queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// evict drops the least recently used thumbnails until the cache fits into the budget.
+func (t *Thumbnailer) evict() {
+	for t.used > t.budget && t.lru.Len() > 0 {
+		e := t.lru.Back()
+		entry := t.lru.Remove(e).(*thumbnailEntry)
+		delete(t.cache, entry.key)
+		t.used -= pictureDataBytes(entry.pd)
+	}
+}
+
+func pictureDataBytes(pd *PictureData) int {
+	return len(pd.Pix) * 4
+}
+
+// Downsample returns a copy of the PictureData scaled down to fit into maxSize pixels in each
+// dimension, keeping its aspect ratio. Each pixel of the result is the average of the pixels it
+// covers (area averaging), which avoids the aliasing of simply skipping pixels.
+//
+// If the PictureData already fits, an exact copy is returned. The Rect of the result starts at
+// (0, 0).
+func Downsample(pd *PictureData, maxSize int) *PictureData {
+	size := pictureDataSize(pd)
+	sw, sh := int(size.X), int(size.Y)
+	scale := math.Min(1, math.Min(float64(maxSize)/size.X, float64(maxSize)/size.Y))
+	tw := int(math.Max(1, math.Floor(size.X*scale+0.5)))
+	th := int(math.Max(1, math.Floor(size.Y*scale+0.5)))
+
+	thumb := MakePictureData(R(0, 0, float64(tw), float64(th)))
+	if sw == 0 || sh == 0 {
+		return thumb
+	}
+
+	// pixels missing at the end of an incomplete last row are transparent
+	at := func(x, y int) color.RGBA {
+		if i := y*pd.Stride + x; i < len(pd.Pix) {
+			return pd.Pix[i]
+		}
+		return color.RGBA{}
+	}
+
+	fx, fy := float64(sw)/float64(tw), float64(sh)/float64(th)
+	for ty := 0; ty < th; ty++ {
+		y0, y1 := float64(ty)*fy, float64(ty+1)*fy
+		for tx := 0; tx < tw; tx++ {
+			x0, x1 := float64(tx)*fx, float64(tx+1)*fx
+
+			var r, g, b, a float64
+			for y := int(y0); float64(y) < y1 && y < sh; y++ {
+				wy := math.Min(y1, float64(y+1)) - math.Max(y0, float64(y))
+				for x := int(x0); float64(x) < x1 && x < sw; x++ {
+					wx := math.Min(x1, float64(x+1)) - math.Max(x0, float64(x))
+					c := at(x, y)
+					r += float64(c.R) * wx * wy
+					g += float64(c.G) * wx * wy
+					b += float64(c.B) * wx * wy
+					a += float64(c.A) * wx * wy
+				}
+			}
+
+			area := fx * fy
+			thumb.Pix[ty*thumb.Stride+tx] = color.RGBA{
+				R: uint8(math.Floor(r/area + 0.5)),
+				G: uint8(math.Floor(g/area + 0.5)),
+				B: uint8(math.Floor(b/area + 0.5)),
+				A: uint8(math.Floor(a/area + 0.5)),
+			}
+		}
+	}
+
+	return thumb
+}
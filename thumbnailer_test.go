@@ -0,0 +1,135 @@
+package pixel_test
+
+import (
+	"errors"
+	"image/color"
+	"sync"
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+func TestDownsample(t *testing.T) {
+	pd := pixel.MakePictureData(pixel.R(0, 0, 4, 2))
+	for x := 0; x < 4; x++ {
+		pd.Pix[x] = color.RGBA{200, 0, 0, 200}
+		pd.Pix[pd.Stride+x] = color.RGBA{0, 0, 100, 100}
+	}
+
+	thumb := pixel.Downsample(pd, 2)
+	if thumb.Bounds() != pixel.R(0, 0, 2, 1) {
+		t.Fatalf("thumbnail bounds %v, want %v", thumb.Bounds(), pixel.R(0, 0, 2, 1))
+	}
+	want := color.RGBA{100, 0, 50, 150}
+	for _, c := range thumb.Pix {
+		if c != want {
+			t.Errorf("thumbnail pixel %v, want %v", c, want)
+		}
+	}
+
+	small := pixel.Downsample(thumb, 16)
+	if small.Bounds() != thumb.Bounds() || small.Pix[0] != want {
+		t.Errorf("downsampling a small picture changed it")
+	}
+}
+
+func TestThumbnailerCoalesce(t *testing.T) {
+	th := pixel.NewThumbnailer(2, 8)
+	defer th.Close()
+
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+	release := make(chan struct{})
+	src := func() (*pixel.PictureData, error) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		<-release
+		return pixel.MakePictureData(pixel.R(0, 0, 32, 16)), nil
+	}
+
+	a := th.Request("hero", src)
+	b := th.Request("hero", src)
+	close(release)
+
+	ta, tb := <-a, <-b
+	if ta == nil || ta != tb {
+		t.Fatalf("coalesced requests got %p and %p, want the same thumbnail", ta, tb)
+	}
+	if ta.Bounds() != pixel.R(0, 0, 8, 4) {
+		t.Errorf("thumbnail bounds %v, want %v", ta.Bounds(), pixel.R(0, 0, 8, 4))
+	}
+
+	// cached now
+	if tc := <-th.Request("hero", src); tc != ta {
+		t.Errorf("cached request got a different thumbnail")
+	}
+	if calls != 1 {
+		t.Errorf("src called %d times, want 1", calls)
+	}
+}
+
+func TestThumbnailerErrorAndCancel(t *testing.T) {
+	th := pixel.NewThumbnailer(1, 8)
+	defer th.Close()
+
+	failed := th.Request("broken", func() (*pixel.PictureData, error) {
+		return nil, errors.New("no such file")
+	})
+	if pd, ok := <-failed; pd != nil || ok {
+		t.Errorf("failed request received %v, want a closed channel", pd)
+	}
+
+	// keep the only worker busy, so that the next request stays pending
+	release := make(chan struct{})
+	started := make(chan struct{})
+	busy := th.Request("busy", func() (*pixel.PictureData, error) {
+		close(started)
+		<-release
+		return pixel.MakePictureData(pixel.R(0, 0, 1, 1)), nil
+	})
+	<-started
+
+	called := false
+	pending := th.Request("offscreen", func() (*pixel.PictureData, error) {
+		called = true
+		return pixel.MakePictureData(pixel.R(0, 0, 1, 1)), nil
+	})
+	th.Cancel("offscreen")
+	close(release)
+	<-busy
+
+	if pd, ok := <-pending; pd != nil || ok {
+		t.Errorf("cancelled request received %v, want a closed channel", pd)
+	}
+	if called {
+		t.Errorf("src of a cancelled request was called")
+	}
+}
+
+func TestThumbnailerBudget(t *testing.T) {
+	th := pixel.NewThumbnailer(1, 8)
+	defer th.Close()
+	th.SetBudget(2 * 8 * 8 * 4) // two thumbnails
+
+	calls := make(map[string]int)
+	request := func(key string) {
+		<-th.Request(key, func() (*pixel.PictureData, error) {
+			calls[key]++
+			return pixel.MakePictureData(pixel.R(0, 0, 8, 8)), nil
+		})
+	}
+
+	request("a")
+	request("b")
+	request("a") // a is more recently used than b
+	request("c") // evicts b
+	request("a")
+	request("b")
+
+	if calls["a"] != 1 || calls["b"] != 2 || calls["c"] != 1 {
+		t.Errorf("src calls %v, want a:1 b:2 c:1", calls)
+	}
+}
@@ -0,0 +1,158 @@
+package pixel
+
+import (
+	"fmt"
+	"math"
+)
+
+// Tilemap is a grid of tiles drawn from the frames of a sprite sheet. All the tiles are in a single
+// Batch, so drawing the whole map is a single draw call.
+//
+// The frames of the sheet are the cells of a grid of the frame size, starting at the top-left
+// corner of the bounds of the sheet. They are indexed from 0 left to right, top to bottom, like the
+// text on a page. The tiles are indexed by their column x and row y, the tile (0, 0) is at the
+// bottom-left corner of the map, which is at the origin:
+//
+//   tm := pixel.NewTilemap(sheet, pixel.V(16, 16), level)
+//   tm.SetTile(3, 0, doorOpen)
+//   tm.Draw(win)
+//
+// Use the Matrix of the Target to move or scale the map, e.g. with a camera.
+type Tilemap struct {
+	sheet     Picture
+	frameSize Vec
+	cols      int // columns of frames in the sheet
+	frames    int
+
+	width, height int
+	tiles         []int
+
+	tri   *TrianglesData
+	batch *Batch
+}
+
+// NewTilemap creates a Tilemap from the sheet Picture, divided into frames of frameSize, and the
+// indices of the frames of the tiles. The tiles are indexed as tiles[y][x], so the first row is at
+// the bottom. The map is as wide as the longest row. A negative index is an empty tile, which isn't
+// drawn, the tiles missing at the ends of shorter rows are empty too.
+//
+// NewTilemap panics if the frame size is not positive or an index is out of the range of the
+// frames.
+func NewTilemap(sheet Picture, frameSize Vec, tiles [][]int) *Tilemap {
+	if frameSize.X <= 0 || frameSize.Y <= 0 {
+		panic(fmt.Errorf("NewTilemap: frame size %v is not positive", frameSize))
+	}
+	b := sheet.Bounds()
+	cols := int(math.Floor(b.W() / frameSize.X))
+	rows := int(math.Floor(b.H() / frameSize.Y))
+
+	width := 0
+	for _, row := range tiles {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+
+	tm := &Tilemap{
+		sheet:     sheet,
+		frameSize: frameSize,
+		cols:      cols,
+		frames:    cols * rows,
+		width:     width,
+		height:    len(tiles),
+		tiles:     make([]int, width*len(tiles)),
+		tri:       MakeTrianglesData(6 * width * len(tiles)),
+	}
+	tm.batch = NewBatch(tm.tri, sheet)
+
+	for y := 0; y < tm.height; y++ {
+		for x := 0; x < tm.width; x++ {
+			index := -1
+			if x < len(tiles[y]) {
+				index = tiles[y][x]
+			}
+			tm.setTile(x, y, index)
+		}
+	}
+	tm.batch.Dirty()
+	return tm
+}
+
+// Size returns the number of columns and rows of tiles in the Tilemap.
+func (tm *Tilemap) Size() (width, height int) {
+	return tm.width, tm.height
+}
+
+// Bounds returns the rectangle covered by the tiles, from the origin to the top-right corner of the
+// last tile.
+func (tm *Tilemap) Bounds() Rect {
+	return R(0, 0, float64(tm.width)*tm.frameSize.X, float64(tm.height)*tm.frameSize.Y)
+}
+
+// Tile returns the index of the frame of the tile at column x and row y, or a negative number if
+// the tile is empty.
+func (tm *Tilemap) Tile(x, y int) int {
+	tm.checkTile("Tile", x, y)
+	return tm.tiles[y*tm.width+x]
+}
+
+// SetTile sets the frame of the tile at column x and row y. A negative index empties the tile.
+// Only the vertices of the tile are updated in the Targets the Tilemap is drawn to.
+//
+// SetTile panics if the tile is outside of the map or the index is out of the range of the frames.
+func (tm *Tilemap) SetTile(x, y, index int) {
+	tm.checkTile("SetTile", x, y)
+	tm.setTile(x, y, index)
+	i := 6 * (y*tm.width + x)
+	tm.batch.DirtyRange(i, i+6)
+}
+
+// Draw draws all the tiles onto the Target in a single draw call.
+func (tm *Tilemap) Draw(t Target) {
+	tm.batch.Draw(t)
+}
+
+func (tm *Tilemap) checkTile(method string, x, y int) {
+	if x < 0 || x >= tm.width || y < 0 || y >= tm.height {
+		panic(fmt.Errorf("(%T).%s: tile (%d, %d) outside of the %dx%d map", tm, method, x, y, tm.width, tm.height))
+	}
+}
+
+// setTile sets the tile and its vertices without notifying the Batch.
+func (tm *Tilemap) setTile(x, y, index int) {
+	if index >= tm.frames {
+		panic(fmt.Errorf("(%T).SetTile: frame index %d out of range [0, %d)", tm, index, tm.frames))
+	}
+	if index < 0 {
+		index = -1
+	}
+	k := y*tm.width + x
+	tm.tiles[k] = index
+
+	tri := (*tm.tri)[6*k : 6*k+6]
+	if index < 0 {
+		// a degenerate quad covers no pixels
+		for i := range tri {
+			tri[i].Position = ZV
+			tri[i].Color = RGBA{}
+			tri[i].Picture = ZV
+			tri[i].Intensity = 0
+		}
+		return
+	}
+
+	min := V(float64(x)*tm.frameSize.X, float64(y)*tm.frameSize.Y)
+	pos := [...]Vec{min, min.Add(V(tm.frameSize.X, 0)), min.Add(tm.frameSize), min.Add(V(0, tm.frameSize.Y))}
+
+	b := tm.sheet.Bounds()
+	col, row := index%tm.cols, index/tm.cols
+	uvMin := V(b.Min.X+float64(col)*tm.frameSize.X, b.Max.Y-float64(row+1)*tm.frameSize.Y)
+	uv := [...]Vec{uvMin, uvMin.Add(V(tm.frameSize.X, 0)), uvMin.Add(tm.frameSize), uvMin.Add(V(0, tm.frameSize.Y))}
+
+	for i, v := range [...]int{0, 1, 2, 0, 2, 3} {
+		tri[i].Position = pos[v]
+		tri[i].Color = Alpha(1)
+		tri[i].Picture = uv[v]
+		tri[i].Intensity = 1
+	}
+}
@@ -0,0 +1,95 @@
+package pixel_test
+
+import (
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+func TestTilemap(t *testing.T) {
+	// 2x2 frames of 10x10, frame 0 is the top-left one
+	sheet := pixel.MakePictureData(pixel.R(0, 0, 20, 20))
+	tm := pixel.NewTilemap(sheet, pixel.V(10, 10), [][]int{
+		{0, 1, 2},
+		{3, -1},
+	})
+	if w, h := tm.Size(); w != 3 || h != 2 {
+		t.Fatalf("Size() = %d, %d, want 3, 2", w, h)
+	}
+	if got, want := tm.Bounds(), pixel.R(0, 0, 30, 20); got != want {
+		t.Errorf("Bounds() = %v, want %v", got, want)
+	}
+
+	target := &nopTarget{}
+	tm.Draw(target)
+	if target.tris.Len() != 6*6 {
+		t.Fatalf("drawn %d vertices, want %d", target.tris.Len(), 6*6)
+	}
+
+	// quad returns the bounds of the positions and the picture coordinates of the tile
+	quad := func(x, y int) (pos, uv pixel.Rect) {
+		i := 6 * (y*3 + x)
+		pos = pixel.R(target.tris.Position(i).X, target.tris.Position(i).Y, target.tris.Position(i).X, target.tris.Position(i).Y)
+		p, _ := target.tris.Picture(i)
+		uv = pixel.R(p.X, p.Y, p.X, p.Y)
+		for j := i; j < i+6; j++ {
+			v := target.tris.Position(j)
+			pos = pos.Union(pixel.R(v.X, v.Y, v.X, v.Y))
+			p, _ := target.tris.Picture(j)
+			uv = uv.Union(pixel.R(p.X, p.Y, p.X, p.Y))
+		}
+		return pos, uv
+	}
+
+	tests := []struct {
+		x, y    int
+		pos, uv pixel.Rect
+	}{
+		{0, 0, pixel.R(0, 0, 10, 10), pixel.R(0, 10, 10, 20)},
+		{1, 0, pixel.R(10, 0, 20, 10), pixel.R(10, 10, 20, 20)},
+		{2, 0, pixel.R(20, 0, 30, 10), pixel.R(0, 0, 10, 10)},
+		{0, 1, pixel.R(0, 10, 10, 20), pixel.R(10, 0, 20, 10)},
+		{1, 1, pixel.R(0, 0, 0, 0), pixel.R(0, 0, 0, 0)}, // empty
+		{2, 1, pixel.R(0, 0, 0, 0), pixel.R(0, 0, 0, 0)}, // missing in the row
+	}
+	for _, tt := range tests {
+		if pos, uv := quad(tt.x, tt.y); pos != tt.pos || uv != tt.uv {
+			t.Errorf("tile (%d, %d) at %v from %v, want at %v from %v", tt.x, tt.y, pos, uv, tt.pos, tt.uv)
+		}
+	}
+	if tm.Tile(1, 1) >= 0 || tm.Tile(2, 1) >= 0 {
+		t.Errorf("empty tiles are %d and %d, want negative", tm.Tile(1, 1), tm.Tile(2, 1))
+	}
+
+	tm.SetTile(1, 1, 2)
+	tm.SetTile(0, 0, -1)
+	tm.Draw(target)
+	if tm.Tile(1, 1) != 2 {
+		t.Errorf("Tile(1, 1) = %d after SetTile, want 2", tm.Tile(1, 1))
+	}
+	if pos, uv := quad(1, 1); pos != pixel.R(10, 10, 20, 20) || uv != pixel.R(0, 0, 10, 10) {
+		t.Errorf("tile (1, 1) at %v from %v after SetTile", pos, uv)
+	}
+	if pos, _ := quad(0, 0); pos.Area() != 0 {
+		t.Errorf("emptied tile (0, 0) covers %v", pos)
+	}
+}
+
+func TestTilemapPanics(t *testing.T) {
+	sheet := pixel.MakePictureData(pixel.R(0, 0, 20, 20))
+	tm := pixel.NewTilemap(sheet, pixel.V(10, 10), [][]int{{0, 1}})
+	for name, f := range map[string]func(){
+		"tile outside":  func() { tm.SetTile(2, 0, 0) },
+		"frame outside": func() { tm.SetTile(0, 0, 4) },
+		"zero frame":    func() { pixel.NewTilemap(sheet, pixel.V(0, 10), nil) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: no panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+}
@@ -0,0 +1,167 @@
+package pixel
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// TransformBuffer keeps a bounded history of timestamped transforms (position, rotation and scale)
+// of an entity and interpolates between them. It's meant for smoothing entities in networked games,
+// where the positions arrive at 10-20 Hz, but are rendered at 60 Hz or more.
+//
+// Push the samples as they arrive and render the entity slightly in the past (typically by about
+// two update intervals), so that there are samples on both sides of the rendered time:
+//
+//   buf.Push(update.Time, update.Pos, update.Rot, 1)
+//   // ...
+//   sprite.Draw(win, buf.At(time.Now().Add(-100*time.Millisecond)))
+//
+// TransformBuffer allocates nothing after creation.
+type TransformBuffer struct {
+	// MaxExtrapolation limits how far past the newest sample At extrapolates. Zero disables
+	// extrapolation. NewTransformBuffer sets it to 250ms.
+	MaxExtrapolation time.Duration
+
+	// Smooth makes At interpolate positions with Hermite curves using the velocities estimated
+	// from the neighboring samples, instead of linearly. This removes the sharp corners at the
+	// samples of curved motion.
+	Smooth bool
+
+	samples []transformSample // sorted by time, the capacity is the size of the history
+}
+
+type transformSample struct {
+	t     time.Time
+	pos   Vec
+	rot   float64
+	scale float64
+}
+
+// NewTransformBuffer creates an empty TransformBuffer keeping at most size samples.
+func NewTransformBuffer(size int) *TransformBuffer {
+	if size < 2 {
+		panic(fmt.Errorf("NewTransformBuffer: size %d, at least 2 needed", size))
+	}
+	return &TransformBuffer{
+		MaxExtrapolation: 250 * time.Millisecond,
+		samples:          make([]transformSample, 0, size),
+	}
+}
+
+// Push adds a sample to the TransformBuffer. Samples may be pushed out of order, they are inserted
+// according to their time. A sample with the same time as an existing one replaces it.
+//
+// When the history is full, the oldest sample is dropped. A sample older than all the samples in a
+// full history is ignored.
+func (tb *TransformBuffer) Push(t time.Time, pos Vec, rot, scale float64) {
+	s := transformSample{t: t, pos: pos, rot: rot, scale: scale}
+
+	// the samples usually arrive in order, so search from the newest one
+	i := len(tb.samples)
+	for i > 0 && t.Before(tb.samples[i-1].t) {
+		i--
+	}
+	if i > 0 && t.Equal(tb.samples[i-1].t) {
+		tb.samples[i-1] = s
+		return
+	}
+
+	if len(tb.samples) == cap(tb.samples) {
+		if i == 0 {
+			return
+		}
+		copy(tb.samples, tb.samples[1:i])
+		tb.samples[i-1] = s
+		return
+	}
+
+	tb.samples = append(tb.samples, transformSample{})
+	copy(tb.samples[i+1:], tb.samples[i:])
+	tb.samples[i] = s
+}
+
+// Len returns the number of samples in the TransformBuffer.
+func (tb *TransformBuffer) Len() int {
+	return len(tb.samples)
+}
+
+// Clear removes all samples from the TransformBuffer.
+func (tb *TransformBuffer) Clear() {
+	tb.samples = tb.samples[:0]
+}
+
+// At returns the transform at the given time as a Matrix, which scales and rotates around the
+// origin and then moves to the position.
+//
+// Between two samples, the transform is interpolated, rotations along the shortest arc. Past the
+// newest sample, it's extrapolated from the last two samples, but at most by MaxExtrapolation.
+// Before the oldest sample, it's the oldest sample. An empty TransformBuffer returns IM.
+func (tb *TransformBuffer) At(render time.Time) Matrix {
+	n := len(tb.samples)
+	switch {
+	case n == 0:
+		return IM
+	case n == 1 || !render.After(tb.samples[0].t):
+		return tb.samples[0].matrix()
+	}
+
+	if !render.Before(tb.samples[n-1].t) {
+		a, b := tb.samples[n-2], tb.samples[n-1]
+		past := render.Sub(b.t)
+		if past > tb.MaxExtrapolation {
+			past = tb.MaxExtrapolation
+		}
+		return interpolateSample(a, b, 1+past.Seconds()/b.t.Sub(a.t).Seconds()).matrix()
+	}
+
+	i := n - 1
+	for render.Before(tb.samples[i-1].t) {
+		i--
+	}
+	a, b := tb.samples[i-1], tb.samples[i]
+	s := render.Sub(a.t).Seconds() / b.t.Sub(a.t).Seconds()
+	lerped := interpolateSample(a, b, s)
+
+	if tb.Smooth {
+		dt := b.t.Sub(a.t).Seconds()
+		v0, v1 := tb.velocity(i-1), tb.velocity(i)
+		h00 := 2*s*s*s - 3*s*s + 1
+		h10 := s*s*s - 2*s*s + s
+		h01 := -2*s*s*s + 3*s*s
+		h11 := s*s*s - s*s
+		lerped.pos = a.pos.Scaled(h00).
+			Add(v0.Scaled(h10 * dt)).
+			Add(b.pos.Scaled(h01)).
+			Add(v1.Scaled(h11 * dt))
+	}
+
+	return lerped.matrix()
+}
+
+// velocity estimates the velocity at the i-th sample from its neighbors.
+func (tb *TransformBuffer) velocity(i int) Vec {
+	j, k := i-1, i+1
+	if j < 0 {
+		j = i
+	}
+	if k >= len(tb.samples) {
+		k = i
+	}
+	a, b := tb.samples[j], tb.samples[k]
+	return b.pos.Sub(a.pos).Scaled(1 / b.t.Sub(a.t).Seconds())
+}
+
+// interpolateSample interpolates between two samples, s of 0 is a, 1 is b and anything beyond 1
+// extrapolates. Rotation takes the shortest arc.
+func interpolateSample(a, b transformSample, s float64) transformSample {
+	return transformSample{
+		pos:   Lerp(a.pos, b.pos, s),
+		rot:   a.rot + math.Remainder(b.rot-a.rot, 2*math.Pi)*s,
+		scale: a.scale + (b.scale-a.scale)*s,
+	}
+}
+
+func (s transformSample) matrix() Matrix {
+	return IM.Scaled(ZV, s.scale).Rotated(ZV, s.rot).Moved(s.pos)
+}
@@ -0,0 +1,185 @@
+package pixel_test
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/faiface/pixel"
+)
+
+func nearMatrix(a, b pixel.Matrix) bool {
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+func transform(pos pixel.Vec, rot, scale float64) pixel.Matrix {
+	return pixel.IM.Scaled(pixel.ZV, scale).Rotated(pixel.ZV, rot).Moved(pos)
+}
+
+func TestTransformBufferAt(t *testing.T) {
+	t0 := time.Unix(100, 0)
+	ms := func(n int) time.Time { return t0.Add(time.Duration(n) * time.Millisecond) }
+
+	tb := pixel.NewTransformBuffer(4)
+	if got := tb.At(t0); got != pixel.IM {
+		t.Errorf("empty: got %v, want IM", got)
+	}
+
+	// pushed out of order
+	tb.Push(ms(100), pixel.V(10, 0), 0.1, 2)
+	tb.Push(ms(0), pixel.V(0, 0), 2*math.Pi-0.1, 1)
+	tb.Push(ms(200), pixel.V(20, 0), 0.3, 2)
+
+	for _, tt := range []struct {
+		name string
+		at   time.Time
+		want pixel.Matrix
+	}{
+		{"before the oldest", ms(-50), transform(pixel.V(0, 0), 2*math.Pi-0.1, 1)},
+		{"shortest arc", ms(50), transform(pixel.V(5, 0), 2*math.Pi, 1.5)},
+		{"between", ms(150), transform(pixel.V(15, 0), 0.2, 2)},
+		{"extrapolated", ms(250), transform(pixel.V(25, 0), 0.4, 2)},
+		{"capped", ms(10000), transform(pixel.V(45, 0), 0.8, 2)},
+	} {
+		if got := tb.At(tt.at); !nearMatrix(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// the history is bounded, the oldest samples get dropped and older ones are ignored
+	tb.Push(ms(300), pixel.V(30, 0), 0, 1)
+	tb.Push(ms(400), pixel.V(40, 0), 0, 1)
+	tb.Push(ms(50), pixel.V(-1000, 0), 0, 1)
+	if tb.Len() != 4 {
+		t.Errorf("Len() = %d, want 4", tb.Len())
+	}
+	if got, want := tb.At(ms(0)), transform(pixel.V(10, 0), 0.1, 2); !nearMatrix(got, want) {
+		t.Errorf("after dropping: got %v, want the oldest remaining sample %v", got, want)
+	}
+}
+
+func TestTransformBufferSmooth(t *testing.T) {
+	t0 := time.Unix(100, 0)
+	tb := pixel.NewTransformBuffer(8)
+	tb.Smooth = true
+	for i := 0; i < 5; i++ {
+		x := float64(i)
+		tb.Push(t0.Add(time.Duration(i)*100*time.Millisecond), pixel.V(10*x, 0), 0, 1)
+	}
+
+	// uniform motion stays uniform with the Hermite curves
+	for _, ms := range []int{130, 250, 333} {
+		got := tb.At(t0.Add(time.Duration(ms) * time.Millisecond))
+		if want := transform(pixel.V(float64(ms)/10, 0), 0, 1); !nearMatrix(got, want) {
+			t.Errorf("%dms: got %v, want %v", ms, got, want)
+		}
+	}
+}
+
+func TestTransformBufferAllocs(t *testing.T) {
+	t0 := time.Unix(100, 0)
+	tb := pixel.NewTransformBuffer(16)
+	tb.Smooth = true
+	i := 0
+	allocs := testing.AllocsPerRun(100, func() {
+		i++
+		tb.Push(t0.Add(time.Duration(i)*50*time.Millisecond), pixel.V(float64(i), 0), 0, 1)
+		tb.At(t0.Add(time.Duration(i)*50*time.Millisecond - 75*time.Millisecond))
+	})
+	if allocs != 0 {
+		t.Errorf("Push and At allocated %v times per run, want 0", allocs)
+	}
+}
+
+// This example runs a "server" goroutine, which sends the position of an entity moving at 100
+// units per second 20 times a second. The updates are delayed by the network randomly, so some of
+// them arrive out of order. The client renders the entity at 60 frames per second 150ms in the
+// past, so that there are samples on both sides of the rendered time, and the entity moves by the
+// same distance every frame. Drawing the newest received position instead makes it stutter. The
+// time is simulated, so that the output is always the same.
+func ExampleTransformBuffer() {
+	type update struct {
+		time, arrival time.Duration
+		pos           pixel.Vec
+	}
+
+	updates := make(chan update)
+	go func() {
+		defer close(updates)
+		rng := rand.New(rand.NewSource(1))
+		var pending []update
+		for sent := time.Duration(0); sent < 2*time.Second; sent += 50 * time.Millisecond {
+			jitter := time.Duration(rng.Intn(60)) * time.Millisecond
+			pending = append(pending, update{
+				time:    sent,
+				arrival: sent + 20*time.Millisecond + jitter,
+				pos:     pixel.V(100*sent.Seconds(), 0),
+			})
+		}
+		// deliver the updates in the order they arrive
+		for len(pending) > 0 {
+			first := 0
+			for i := range pending {
+				if pending[i].arrival < pending[first].arrival {
+					first = i
+				}
+			}
+			updates <- pending[first]
+			pending = append(pending[:first], pending[first+1:]...)
+		}
+	}()
+
+	start := time.Unix(0, 0)
+	buf := pixel.NewTransformBuffer(16)
+	var newest update
+	minStep, maxStep := math.Inf(1), math.Inf(-1)
+	minNaive, maxNaive := math.Inf(1), math.Inf(-1)
+	var prevX, prevNaive float64
+
+	next, ok := <-updates
+	for frame := 30; frame <= 90; frame++ {
+		now := time.Duration(frame) * time.Second / 60
+
+		// receive the updates, which arrived before this frame
+		for ok && next.arrival <= now {
+			buf.Push(start.Add(next.time), next.pos, 0, 1)
+			if next.time > newest.time {
+				newest = next
+			}
+			next, ok = <-updates
+		}
+
+		x := buf.At(start.Add(now - 150*time.Millisecond)).Project(pixel.ZV).X
+		if frame > 30 {
+			minStep, maxStep = math.Min(minStep, x-prevX), math.Max(maxStep, x-prevX)
+			step := newest.pos.X - prevNaive
+			minNaive, maxNaive = math.Min(minNaive, step), math.Max(maxNaive, step)
+		}
+		prevX, prevNaive = x, newest.pos.X
+		if frame%15 == 0 {
+			fmt.Printf("%4.0fms: x=%.1f\n", now.Seconds()*1000, x)
+		}
+	}
+	for ok {
+		next, ok = <-updates
+	}
+
+	fmt.Printf("TransformBuffer: %.2f to %.2f per frame\n", minStep, maxStep)
+	fmt.Printf("newest position: %.2f to %.2f per frame\n", minNaive, maxNaive)
+
+	// Output:
+	//  500ms: x=35.0
+	//  750ms: x=60.0
+	// 1000ms: x=85.0
+	// 1250ms: x=110.0
+	// 1500ms: x=135.0
+	// TransformBuffer: 1.67 to 1.67 per frame
+	// newest position: 0.00 to 10.00 per frame
+}
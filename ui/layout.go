@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+// Panel draws the Panel image of the Skin behind its child, keeping the padding around it.
+type Panel struct {
+	Child   Widget
+	Padding float64
+
+	bounds pixel.Rect
+}
+
+// MinSize returns the MinSize of the child plus the padding.
+func (p *Panel) MinSize(s *Skin) pixel.Vec {
+	size := pixel.V(2*p.Padding, 2*p.Padding)
+	if p.Child != nil {
+		size = size.Add(p.Child.MinSize(s))
+	}
+	return size
+}
+
+// Layout places the Panel into the rectangle and the child into it, minus the padding.
+func (p *Panel) Layout(s *Skin, r pixel.Rect) {
+	p.bounds = r
+	if p.Child != nil {
+		p.Child.Layout(s, r.Inset(p.Padding))
+	}
+}
+
+// Bounds returns the rectangle of the Panel.
+func (p *Panel) Bounds() pixel.Rect {
+	return p.bounds
+}
+
+// Draw draws the Panel and its child.
+func (p *Panel) Draw(dc *DrawContext) {
+	dc.Frame(dc.Skin.Panel, p.bounds)
+	if p.Child != nil {
+		p.Child.Draw(dc)
+	}
+}
+
+// Children returns the child of the Panel.
+func (p *Panel) Children() []Widget {
+	if p.Child == nil {
+		return nil
+	}
+	return []Widget{p.Child}
+}
+
+// Stack places its Widgets next to each other, from the top to the bottom if Vertical, otherwise
+// from the left to the right. Each of them gets its MinSize along the stack and is stretched across
+// it.
+type Stack struct {
+	Vertical bool
+	Spacing  float64
+	Widgets  []Widget
+
+	bounds pixel.Rect
+}
+
+// MinSize returns the space needed by all the children including the spacing.
+func (st *Stack) MinSize(s *Skin) pixel.Vec {
+	var along, across float64
+	for i, w := range st.Widgets {
+		size := w.MinSize(s)
+		if st.Vertical {
+			size.X, size.Y = size.Y, size.X
+		}
+		if i > 0 {
+			along += st.Spacing
+		}
+		along += size.X
+		across = math.Max(across, size.Y)
+	}
+	if st.Vertical {
+		return pixel.V(across, along)
+	}
+	return pixel.V(along, across)
+}
+
+// Layout places the Stack into the rectangle and the children into it.
+func (st *Stack) Layout(s *Skin, r pixel.Rect) {
+	st.bounds = r
+	x, y := r.Min.X, r.Max.Y
+	for _, w := range st.Widgets {
+		size := w.MinSize(s)
+		if st.Vertical {
+			w.Layout(s, pixel.R(r.Min.X, y-size.Y, r.Max.X, y))
+			y -= size.Y + st.Spacing
+		} else {
+			w.Layout(s, pixel.R(x, r.Min.Y, x+size.X, r.Max.Y))
+			x += size.X + st.Spacing
+		}
+	}
+}
+
+// Bounds returns the rectangle of the Stack.
+func (st *Stack) Bounds() pixel.Rect {
+	return st.bounds
+}
+
+// Draw draws the children.
+func (st *Stack) Draw(dc *DrawContext) {
+	for _, w := range st.Widgets {
+		w.Draw(dc)
+	}
+}
+
+// Children returns the children of the Stack.
+func (st *Stack) Children() []Widget {
+	return st.Widgets
+}
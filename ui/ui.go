@@ -0,0 +1,354 @@
+// Package ui implements a minimal retained UI layer for the Pixel library, with just enough
+// widgets, layout and keyboard navigation for menus and settings screens.
+//
+// A UI is a tree of Widgets held by a Root. The Root lays the tree out, routes the input to the
+// widgets and draws them:
+//
+//   root := ui.NewRoot(skin)
+//   menu := &ui.Stack{Vertical: true, Spacing: 4, Widgets: []ui.Widget{
+//       &ui.Label{Text: "Settings"},
+//       volume,
+//       &ui.Button{Text: "Back", OnClick: back},
+//   }}
+//   root.Add(&ui.Panel{Padding: 8, Child: menu}, pixel.V(0.5, 0.5))
+//
+//   for !win.Closed() {
+//       root.Layout(win.Bounds())
+//       root.Update(input)
+//       root.Draw(win)
+//       win.Update()
+//   }
+//
+// This is not a full GUI toolkit, there is no theming beyond a Skin and no text input.
+package ui
+
+import (
+	"image/color"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/text"
+)
+
+// NineSlice is a 9-slice image within the Picture of a Skin, see pixel.DrawNineSliceFrame. A zero
+// NineSlice isn't drawn.
+type NineSlice struct {
+	Frame, Center pixel.Rect
+}
+
+// Skin specifies the look of the widgets.
+//
+// All the images are parts of a single Picture and all the text uses a single Atlas, so the whole
+// UI is drawn by two Batches.
+type Skin struct {
+	Picture pixel.Picture
+
+	Panel         NineSlice
+	Button        NineSlice
+	ButtonHover   NineSlice
+	ButtonPressed NineSlice
+	SliderTrack   NineSlice
+	SliderKnob    NineSlice
+
+	// Focus is drawn around the focused widget.
+	Focus NineSlice
+
+	Atlas     *text.Atlas
+	TextColor color.Color
+
+	// Padding is the space between the border and the text of a Button.
+	Padding float64
+}
+
+// Widget is an element of the UI.
+type Widget interface {
+	// MinSize returns the smallest size the Widget needs.
+	MinSize(s *Skin) pixel.Vec
+
+	// Layout places the Widget (and its children) into the rectangle.
+	Layout(s *Skin, r pixel.Rect)
+
+	// Bounds returns the rectangle the Widget was placed into by the last Layout.
+	Bounds() pixel.Rect
+
+	// Draw draws the Widget (and its children).
+	Draw(dc *DrawContext)
+}
+
+// Container is a Widget with children, such as a Panel or a Stack. The Root looks for Focusable
+// widgets in the children.
+type Container interface {
+	Widget
+	Children() []Widget
+}
+
+// Focusable is a Widget which takes part in keyboard navigation and receives input.
+type Focusable interface {
+	Widget
+
+	// Activate is called when the Widget is clicked or Enter is pressed while it's focused.
+	Activate()
+
+	// Adjust is called when the left (steps of -1) or right (steps of 1) arrow is pressed while
+	// the Widget is focused.
+	Adjust(steps int)
+
+	// Drag is called when the mouse moves while the button pressed on the Widget is held down.
+	// It's also called when the button gets pressed.
+	Drag(pos pixel.Vec)
+}
+
+// Input is the state of the input for one frame, see Root.Update. The keys should be true when
+// they got pressed or repeated during the frame.
+//
+// This is how to fill it from a pixelgl.Window:
+//
+//   shift := win.Pressed(pixelgl.KeyLeftShift) || win.Pressed(pixelgl.KeyRightShift)
+//   input := ui.Input{
+//       MousePosition:     win.MousePosition(),
+//       MousePressed:      win.Pressed(pixelgl.MouseButtonLeft),
+//       MouseJustPressed:  win.JustPressed(pixelgl.MouseButtonLeft),
+//       MouseJustReleased: win.JustReleased(pixelgl.MouseButtonLeft),
+//       Tab:               win.Repeated(pixelgl.KeyTab) && !shift,
+//       Backtab:           win.Repeated(pixelgl.KeyTab) && shift,
+//       Up:                win.Repeated(pixelgl.KeyUp),
+//       Down:              win.Repeated(pixelgl.KeyDown),
+//       Left:              win.Repeated(pixelgl.KeyLeft),
+//       Right:             win.Repeated(pixelgl.KeyRight),
+//       Enter:             win.JustPressed(pixelgl.KeyEnter),
+//   }
+type Input struct {
+	MousePosition     pixel.Vec
+	MousePressed      bool
+	MouseJustPressed  bool
+	MouseJustReleased bool
+
+	Tab, Backtab          bool
+	Up, Down, Left, Right bool
+	Enter                 bool
+}
+
+type rootItem struct {
+	w      Widget
+	anchor pixel.Vec
+}
+
+// Root holds the widgets of a UI, lays them out, routes the input to them and draws them.
+type Root struct {
+	skin  *Skin
+	items []rootItem
+
+	focus   Focusable
+	hover   Focusable
+	pressed Focusable
+
+	skinBatch *pixel.Batch
+	textBatch *pixel.Batch
+	txt       *text.Text
+	dc        DrawContext
+}
+
+// NewRoot creates an empty Root drawing the widgets with the Skin.
+func NewRoot(skin *Skin) *Root {
+	r := &Root{
+		skin:      skin,
+		skinBatch: pixel.NewBatch(&pixel.TrianglesData{}, skin.Picture),
+		textBatch: pixel.NewBatch(&pixel.TrianglesData{}, skin.Atlas.Picture()),
+		txt:       text.New(pixel.ZV, skin.Atlas),
+	}
+	r.dc = DrawContext{Skin: skin, root: r}
+	return r
+}
+
+// Add adds a top-level Widget to the Root. The Widget gets its MinSize and is placed within the
+// bounds of the Root according to the anchor: pixel.V(0, 0) is the bottom-left corner,
+// pixel.V(0.5, 0.5) is the center, pixel.V(1, 1) is the top-right corner and so on.
+func (r *Root) Add(w Widget, anchor pixel.Vec) {
+	r.items = append(r.items, rootItem{w: w, anchor: anchor})
+}
+
+// Layout lays out all the widgets within the bounds, usually the bounds of the Window. Call it
+// whenever the bounds or the widgets change, calling it every frame is fine.
+func (r *Root) Layout(bounds pixel.Rect) {
+	for _, it := range r.items {
+		size := it.w.MinSize(r.skin)
+		min := bounds.Min.Add(bounds.Size().Sub(size).ScaledXY(it.anchor))
+		it.w.Layout(r.skin, pixel.Rect{Min: min, Max: min.Add(size)})
+	}
+}
+
+// Focus returns the focused Widget, or nil if no Widget is focused.
+func (r *Root) Focus() Focusable {
+	return r.focus
+}
+
+// SetFocus focuses the Widget. Nil removes the focus.
+func (r *Root) SetFocus(f Focusable) {
+	r.focus = f
+}
+
+// focusables returns all Focusable widgets in the order of the keyboard navigation.
+func (r *Root) focusables() []Focusable {
+	var fs []Focusable
+	var walk func(w Widget)
+	walk = func(w Widget) {
+		if f, ok := w.(Focusable); ok {
+			fs = append(fs, f)
+		}
+		if c, ok := w.(Container); ok {
+			for _, child := range c.Children() {
+				walk(child)
+			}
+		}
+	}
+	for _, it := range r.items {
+		walk(it.w)
+	}
+	return fs
+}
+
+// moveFocus moves the focus by delta in the order of the keyboard navigation, wrapping around.
+func (r *Root) moveFocus(delta int) {
+	fs := r.focusables()
+	if len(fs) == 0 {
+		return
+	}
+	i := -1
+	for j, f := range fs {
+		if f == r.focus {
+			i = j
+		}
+	}
+	if i < 0 && delta < 0 {
+		i = 0
+	}
+	r.focus = fs[((i+delta)%len(fs)+len(fs))%len(fs)]
+}
+
+// Update routes the input to the widgets. Call it after Layout.
+//
+// Clicking a Focusable Widget focuses it. Tab and the down arrow focus the next Focusable Widget,
+// Backtab and the up arrow the previous one. The left and right arrows adjust the focused Widget
+// and Enter activates it.
+func (r *Root) Update(in Input) {
+	r.hover = nil
+	fs := r.focusables()
+	for i := len(fs) - 1; i >= 0; i-- {
+		if fs[i].Bounds().Contains(in.MousePosition) {
+			r.hover = fs[i]
+			break
+		}
+	}
+
+	if in.MouseJustPressed {
+		r.pressed = r.hover
+		if r.hover != nil {
+			r.focus = r.hover
+		}
+	}
+	if r.pressed != nil && (in.MousePressed || in.MouseJustPressed) {
+		r.pressed.Drag(in.MousePosition)
+	}
+	if in.MouseJustReleased || !in.MousePressed {
+		if in.MouseJustReleased && r.pressed != nil && r.pressed == r.hover {
+			r.pressed.Activate()
+		}
+		r.pressed = nil
+	}
+
+	switch {
+	case in.Tab || in.Down:
+		r.moveFocus(1)
+	case in.Backtab || in.Up:
+		r.moveFocus(-1)
+	}
+	if r.focus != nil {
+		switch {
+		case in.Left:
+			r.focus.Adjust(-1)
+		case in.Right:
+			r.focus.Adjust(1)
+		}
+		if in.Enter {
+			r.focus.Activate()
+		}
+	}
+}
+
+// Draw draws all the widgets onto the Target.
+//
+// All the Skin images are drawn first and all the text after them, each in a single draw call.
+// So text is never covered by images of other widgets, which is fine, as long as the widgets
+// don't overlap.
+func (r *Root) Draw(t pixel.Target) {
+	r.skinBatch.Clear()
+	r.textBatch.Clear()
+	for _, it := range r.items {
+		it.w.Draw(&r.dc)
+	}
+	if r.focus != nil {
+		r.dc.Frame(r.skin.Focus, r.focus.Bounds())
+	}
+	r.skinBatch.Draw(t)
+	r.textBatch.Draw(t)
+}
+
+// DrawContext is passed to Widget.Draw for drawing with the Skin.
+type DrawContext struct {
+	Skin *Skin
+
+	root *Root
+}
+
+// Frame draws the NineSlice stretched over the rectangle.
+func (dc *DrawContext) Frame(ns NineSlice, r pixel.Rect) {
+	if ns.Frame.Area() == 0 {
+		return
+	}
+	pixel.DrawNineSliceFrame(dc.root.skinBatch, dc.Skin.Picture, ns.Frame, ns.Center, r)
+}
+
+// Text draws a single line of text vertically centered in the rectangle. Align 0 aligns it to the
+// left, 0.5 centers it and 1 aligns it to the right. Nil color means the TextColor of the Skin.
+func (dc *DrawContext) Text(s string, r pixel.Rect, align float64, c color.Color) {
+	if c == nil {
+		c = dc.Skin.TextColor
+	}
+	atlas := dc.Skin.Atlas
+	w := textWidth(atlas, s)
+	dot := pixel.V(
+		r.Min.X+(r.W()-w)*align,
+		r.Center().Y-(atlas.Ascent()-atlas.Descent())/2,
+	)
+
+	txt := dc.root.txt
+	txt.Clear()
+	txt.Orig, txt.Dot = dot, dot
+	txt.Color = c
+	txt.WriteString(s)
+	txt.Draw(dc.root.textBatch, pixel.IM)
+}
+
+// Focused returns whether the Widget is focused.
+func (dc *DrawContext) Focused(w Widget) bool {
+	return dc.root.focus != nil && Widget(dc.root.focus) == w
+}
+
+// Hovered returns whether the mouse is over the Focusable Widget.
+func (dc *DrawContext) Hovered(w Widget) bool {
+	return dc.root.hover != nil && Widget(dc.root.hover) == w
+}
+
+// Pressed returns whether the mouse button was pressed on the Widget and is still held down.
+func (dc *DrawContext) Pressed(w Widget) bool {
+	return dc.root.pressed != nil && Widget(dc.root.pressed) == w
+}
+
+// textWidth returns the advance of a line of text written with the Atlas.
+func textWidth(atlas *text.Atlas, s string) float64 {
+	dot, prevR := pixel.ZV, rune(-1)
+	for _, r := range s {
+		_, _, _, dot = atlas.DrawRune(prevR, r, dot)
+		prevR = r
+	}
+	return dot.X
+}
@@ -0,0 +1,169 @@
+package ui_test
+
+import (
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/text"
+	"github.com/faiface/pixel/ui"
+)
+
+func testSkin() *ui.Skin {
+	frame := ui.NineSlice{Frame: pixel.R(0, 0, 8, 8), Center: pixel.R(2, 2, 6, 6)}
+	return &ui.Skin{
+		Picture:     pixel.MakePictureData(pixel.R(0, 0, 8, 8)),
+		Panel:       frame,
+		Button:      frame,
+		SliderTrack: frame,
+		SliderKnob:  frame,
+		Focus:       frame,
+		Atlas:       text.Atlas7x13,
+		TextColor:   pixel.Alpha(1),
+		Padding:     4,
+	}
+}
+
+type testMenu struct {
+	root    *ui.Root
+	label   *ui.Label
+	button  *ui.Button
+	slider  *ui.Slider
+	clicked int
+}
+
+func newTestMenu() *testMenu {
+	m := &testMenu{root: ui.NewRoot(testSkin())}
+	m.label = &ui.Label{Text: "Hi"}
+	m.button = &ui.Button{Text: "OK", OnClick: func() { m.clicked++ }}
+	m.slider = &ui.Slider{Min: 0, Max: 10, Value: 5, Step: 1}
+	m.root.Add(&ui.Panel{Padding: 8, Child: &ui.Stack{Vertical: true, Spacing: 2, Widgets: []ui.Widget{
+		m.label, m.button, m.slider,
+	}}}, pixel.V(0.5, 0.5))
+	m.root.Layout(pixel.R(0, 0, 400, 300))
+	return m
+}
+
+func TestLayout(t *testing.T) {
+	m := newTestMenu()
+	for _, tt := range []struct {
+		name string
+		w    ui.Widget
+		want pixel.Rect
+	}{
+		{"label", m.label, pixel.R(150, 162.5, 250, 175.5)},
+		{"button", m.button, pixel.R(150, 139.5, 250, 160.5)},
+		{"slider", m.slider, pixel.R(150, 124.5, 250, 137.5)},
+	} {
+		if got := tt.w.Bounds(); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	root := ui.NewRoot(testSkin())
+	corner := &ui.Button{Text: "X"}
+	root.Add(corner, pixel.V(1, 1))
+	root.Layout(pixel.R(0, 0, 400, 300))
+	if got, want := corner.Bounds(), pixel.R(385, 279, 400, 300); got != want {
+		t.Errorf("top-right anchor: got %v, want %v", got, want)
+	}
+}
+
+func TestKeyboardNavigation(t *testing.T) {
+	m := newTestMenu()
+	if m.root.Focus() != nil {
+		t.Fatalf("focused %v initially", m.root.Focus())
+	}
+
+	for i, step := range []struct {
+		in   ui.Input
+		want ui.Focusable
+	}{
+		{ui.Input{Tab: true}, m.button},
+		{ui.Input{Tab: true}, m.slider},
+		{ui.Input{Tab: true}, m.button},
+		{ui.Input{Backtab: true}, m.slider},
+		{ui.Input{Up: true}, m.button},
+		{ui.Input{Down: true}, m.slider},
+	} {
+		m.root.Update(step.in)
+		if got := m.root.Focus(); got != step.want {
+			t.Errorf("step %d: focused %T, want %T", i, got, step.want)
+		}
+	}
+
+	m.root.Update(ui.Input{Right: true})
+	m.root.Update(ui.Input{Right: true})
+	if m.slider.Value != 7 {
+		t.Errorf("slider value %v after two right arrows, want 7", m.slider.Value)
+	}
+
+	m.root.Update(ui.Input{Up: true})
+	m.root.Update(ui.Input{Enter: true})
+	if m.clicked != 1 {
+		t.Errorf("button clicked %d times by Enter, want 1", m.clicked)
+	}
+}
+
+func TestMouse(t *testing.T) {
+	m := newTestMenu()
+
+	over := m.button.Bounds().Center()
+	m.root.Update(ui.Input{MousePosition: over, MousePressed: true, MouseJustPressed: true})
+	m.root.Update(ui.Input{MousePosition: over, MouseJustReleased: true})
+	if m.clicked != 1 || m.root.Focus() != m.button {
+		t.Errorf("click: clicked %d times and focused %T, want 1 and the button", m.clicked, m.root.Focus())
+	}
+
+	// releasing outside of the button doesn't click it
+	m.root.Update(ui.Input{MousePosition: over, MousePressed: true, MouseJustPressed: true})
+	m.root.Update(ui.Input{MousePosition: pixel.ZV, MouseJustReleased: true})
+	if m.clicked != 1 {
+		t.Errorf("released outside: clicked %d times, want 1", m.clicked)
+	}
+
+	// dragging the knob of the slider all the way to the right
+	b := m.slider.Bounds()
+	m.root.Update(ui.Input{MousePosition: b.Center(), MousePressed: true, MouseJustPressed: true})
+	m.root.Update(ui.Input{MousePosition: pixel.V(b.Max.X+50, b.Min.Y), MousePressed: true})
+	if m.slider.Value != 10 || m.root.Focus() != m.slider {
+		t.Errorf("drag: slider value %v and focused %T, want 10 and the slider", m.slider.Value, m.root.Focus())
+	}
+}
+
+// drawTarget counts the draws onto it.
+type drawTarget struct {
+	draws int
+}
+
+func (dt *drawTarget) MakeTriangles(t pixel.Triangles) pixel.TargetTriangles {
+	return &drawTriangles{Triangles: t.Copy(), dst: dt}
+}
+
+func (dt *drawTarget) MakePicture(p pixel.Picture) pixel.TargetPicture {
+	return drawPicture{Picture: p, dst: dt}
+}
+
+type drawTriangles struct {
+	pixel.Triangles
+	dst *drawTarget
+}
+
+func (dt *drawTriangles) Draw() { dt.dst.draws++ }
+
+type drawPicture struct {
+	pixel.Picture
+	dst *drawTarget
+}
+
+func (dp drawPicture) Draw(t pixel.TargetTriangles) { dp.dst.draws++ }
+
+func TestDraw(t *testing.T) {
+	m := newTestMenu()
+	m.root.SetFocus(m.slider)
+
+	target := &drawTarget{}
+	m.root.Draw(target)
+	if target.draws != 2 {
+		t.Errorf("drawn in %d draw calls, want 2", target.draws)
+	}
+}
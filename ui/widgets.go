@@ -0,0 +1,183 @@
+package ui
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+// Label is a single line of text.
+type Label struct {
+	Text string
+
+	// Color is the color of the text, nil means the TextColor of the Skin.
+	Color color.Color
+
+	bounds pixel.Rect
+}
+
+// MinSize returns the size of the text.
+func (l *Label) MinSize(s *Skin) pixel.Vec {
+	return pixel.V(textWidth(s.Atlas, l.Text), s.Atlas.LineHeight())
+}
+
+// Layout places the Label into the rectangle.
+func (l *Label) Layout(s *Skin, r pixel.Rect) {
+	l.bounds = r
+}
+
+// Bounds returns the rectangle of the Label.
+func (l *Label) Bounds() pixel.Rect {
+	return l.bounds
+}
+
+// Draw draws the text aligned to the left.
+func (l *Label) Draw(dc *DrawContext) {
+	dc.Text(l.Text, l.bounds, 0, l.Color)
+}
+
+// Button is a clickable button with a text.
+type Button struct {
+	Text string
+
+	// OnClick is called when the Button is clicked or activated by Enter.
+	OnClick func()
+
+	bounds pixel.Rect
+}
+
+// MinSize returns the size of the text plus the padding of the Skin.
+func (b *Button) MinSize(s *Skin) pixel.Vec {
+	return pixel.V(textWidth(s.Atlas, b.Text), s.Atlas.LineHeight()).Add(pixel.V(2*s.Padding, 2*s.Padding))
+}
+
+// Layout places the Button into the rectangle.
+func (b *Button) Layout(s *Skin, r pixel.Rect) {
+	b.bounds = r
+}
+
+// Bounds returns the rectangle of the Button.
+func (b *Button) Bounds() pixel.Rect {
+	return b.bounds
+}
+
+// Draw draws the Button image of the Skin according to the state of the Button and the centered
+// text.
+func (b *Button) Draw(dc *DrawContext) {
+	frame := dc.Skin.Button
+	switch {
+	case dc.Pressed(b) && dc.Skin.ButtonPressed.Frame.Area() > 0:
+		frame = dc.Skin.ButtonPressed
+	case dc.Hovered(b) && dc.Skin.ButtonHover.Frame.Area() > 0:
+		frame = dc.Skin.ButtonHover
+	}
+	dc.Frame(frame, b.bounds)
+	dc.Text(b.Text, b.bounds, 0.5, nil)
+}
+
+// Activate calls OnClick.
+func (b *Button) Activate() {
+	if b.OnClick != nil {
+		b.OnClick()
+	}
+}
+
+// Adjust does nothing.
+func (b *Button) Adjust(steps int) {}
+
+// Drag does nothing.
+func (b *Button) Drag(pos pixel.Vec) {}
+
+// Slider selects a value from a range by dragging a knob or with the left and right arrows.
+type Slider struct {
+	Min, Max, Value float64
+
+	// Step is the increment of the Value. The Value is rounded to the multiples of Step from Min.
+	// Zero means a continuous Value, which the arrows change by a tenth of the range.
+	Step float64
+
+	// Width is the minimum width of the Slider. Zero means 100.
+	Width float64
+
+	// OnChange is called when the Value changes by the input.
+	OnChange func(value float64)
+
+	bounds pixel.Rect
+}
+
+// MinSize returns the Width and the height of a line of text.
+func (sl *Slider) MinSize(s *Skin) pixel.Vec {
+	w := sl.Width
+	if w == 0 {
+		w = 100
+	}
+	return pixel.V(w, s.Atlas.LineHeight())
+}
+
+// Layout places the Slider into the rectangle.
+func (sl *Slider) Layout(s *Skin, r pixel.Rect) {
+	sl.bounds = r
+}
+
+// Bounds returns the rectangle of the Slider.
+func (sl *Slider) Bounds() pixel.Rect {
+	return sl.bounds
+}
+
+// knobSize returns the size of the square knob.
+func (sl *Slider) knobSize() float64 {
+	return math.Min(sl.bounds.H(), sl.bounds.W())
+}
+
+// Draw draws the track and the knob of the Slider.
+func (sl *Slider) Draw(dc *DrawContext) {
+	track := sl.bounds.Pad(0, sl.bounds.H()/3, 0, sl.bounds.H()/3)
+	dc.Frame(dc.Skin.SliderTrack, track)
+
+	k := sl.knobSize()
+	t := 0.0
+	if sl.Max != sl.Min {
+		t = pixel.Clamp((sl.Value-sl.Min)/(sl.Max-sl.Min), 0, 1)
+	}
+	x := sl.bounds.Min.X + t*(sl.bounds.W()-k)
+	dc.Frame(dc.Skin.SliderKnob, pixel.R(x, sl.bounds.Min.Y, x+k, sl.bounds.Min.Y+k))
+}
+
+// setValue sets the Value snapped to the Step and clamped to the range and calls OnChange if it
+// changed.
+func (sl *Slider) setValue(v float64) {
+	if sl.Step > 0 {
+		v = sl.Min + math.Floor((v-sl.Min)/sl.Step+0.5)*sl.Step
+	}
+	v = pixel.Clamp(v, math.Min(sl.Min, sl.Max), math.Max(sl.Min, sl.Max))
+	if v == sl.Value {
+		return
+	}
+	sl.Value = v
+	if sl.OnChange != nil {
+		sl.OnChange(v)
+	}
+}
+
+// Activate does nothing.
+func (sl *Slider) Activate() {}
+
+// Adjust changes the Value by the steps of Step.
+func (sl *Slider) Adjust(steps int) {
+	step := sl.Step
+	if step <= 0 {
+		step = (sl.Max - sl.Min) / 10
+	}
+	sl.setValue(sl.Value + float64(steps)*step)
+}
+
+// Drag moves the knob to the position.
+func (sl *Slider) Drag(pos pixel.Vec) {
+	k := sl.knobSize()
+	if sl.bounds.W() <= k {
+		return
+	}
+	t := pixel.Clamp((pos.X-sl.bounds.Min.X-k/2)/(sl.bounds.W()-k), 0, 1)
+	sl.setValue(sl.Min + t*(sl.Max-sl.Min))
+}